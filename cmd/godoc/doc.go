@@ -34,6 +34,16 @@ web page.
 
 	godoc -http=:6060
 
+With the -export flag, it writes the pages it would serve, from the package and
+command documentation and the source files to the style sheets and scripts, to
+a directory, and exits. Directories become index.html files, and pages of other
+files, such as source files, get a .html extension. The links between the pages
+are relative, so the mirror can be published on any static file server or in
+object storage. Search, the playground and the analyses need a server and are
+not available in the mirror.
+
+	godoc -export=/tmp/godoc-site
+
 Usage:
 	godoc [flag] package [name ...]
 
@@ -56,6 +66,10 @@ The flags are:
 	-index_files=""
 		glob pattern specifying index files; if not empty,
 		the index is read from these files in sorted order
+	-index_cache=""
+		file in which to keep the search index between runs; if not
+		empty, the index is read from the file at startup and written
+		to it after each update
 	-index_throttle=0.75
 		index throttle value; a value of 0 means no time is allocated
 		to the indexer (the indexer will never finish), a value of 1.0
@@ -63,6 +77,14 @@ The flags are:
 		goroutines may get no time while the index is built)
 	-links=true:
 		link identifiers to their declarations
+	-doclinks=false
+		link the exported identifiers that doc comments mention to
+		their documentation: those of the package written as T.Method,
+		as pkg.Name, or as a name that is not an ordinary word, such
+		as NewReader, and those of the packages it imports as pkg.Name
+	-markdown=false
+		render a subset of Markdown in doc comments in HTML:
+		fenced code blocks, lists, and [text](url) links
 	-write_index=false
 		write index to a file; the file name must be specified with
 		-index_files
@@ -76,6 +98,14 @@ The flags are:
 		print HTML in command-line mode
 	-goroot=$GOROOT
 		Go root directory
+	-play=false
+		enable the playground and runnable examples in the web interface
+	-play_backend=""
+		URL of the playground service that runs the programs of -play;
+		if empty, the golang.org playground is used
+	-versions=""
+		comma-separated list of name=dir pairs naming other versions
+		of the source tree to serve; each dir is a Go root or workspace
 	-http=addr
 		HTTP service address (e.g., '127.0.0.1:6060' or just ':6060')
 	-server=addr
@@ -89,25 +119,69 @@ The flags are:
 		See http://golang.org/lib/godoc/analysis/help.html for details.
 	-templates=""
 		directory containing alternate template files; if set,
-		the directory may provide alternative template files
-		for the files in $GOROOT/lib/godoc
+		the directory may provide alternatives for any of the
+		template and static files godoc serves under /lib/godoc
 	-url=path
 		print to standard output the data that would be served by
 		an HTTP request for path
+	-export=dir
+		write a static HTML mirror of the web pages to dir
 	-zip=""
 		zip file providing the file system to serve; disabled if empty
 
+The look of the web pages can be changed without rebuilding godoc. The files
+of the directory given with -templates, such as godoc.html (the frame of every
+page), package.html or style.css, replace the built-in files of the same name;
+the others are still served. The built-in theme.css, which every page loads
+after style.css, is empty, so a theme.css in the directory may restyle the
+pages; one containing just
+
+	@import "dark.css";
+
+switches to the built-in dark theme.
+
 By default, godoc looks at the packages it finds via $GOROOT and $GOPATH (if set).
 This behavior can be altered by providing an alternative $GOROOT with the -goroot
 flag.
 
 When godoc runs as a web server and -index is set, a search index is maintained.
-The index is created at startup.
+The index is created at startup, and updated every -index_interval; an update
+only indexes again the directories whose files were added, removed or modified.
+With -index_cache, the index is also kept in a file between runs, so that godoc
+starts with the index of the last run, and the first update only indexes the
+directories that changed in between:
+
+	godoc -http=:6060 -index -index_cache=$HOME/.cache/godoc.index
 
 The index contains both identifier and full text search information (searchable
 via regular expressions). The maximum number of full text search results shown
 can be set with the -maxresults flag; if set to 0, no full text results are
 shown, and only an identifier index but no full text search index is created.
+Packages and identifiers found are listed by the number of packages in the
+index that import their packages, most imported first, so that a search for
+http lists net/http before less used packages of the same name.
+
+To browse how packages change between releases, other versions of the source
+tree, such as checkouts of earlier releases or other workspaces, may be served
+beside it with the -versions flag:
+
+	godoc -http=:6060 -versions=v1.0=$HOME/releases/v1.0,next=$HOME/next
+
+The pages of packages and commands that more than one version has link to each
+of them, and the "v" URL parameter selects the version shown, as in
+http://localhost:6060/pkg/example.com/lib/?v=v1.0. The default version is the
+one in $GOROOT and $GOPATH; only it is searched.
+
+With -play, examples can be edited and run in the browser. The programs are
+sent to the golang.org playground, unless -play_backend gives the URL of
+another playground service, such as one that deployments serving private code
+run themselves:
+
+	godoc -http=:6060 -play -play_backend=https://play.example.com
+
+Requests to run programs are refused if they come from the pages of another
+site. With a backend other than golang.org's, the buttons that share code
+through the golang.org playground are hidden.
 
 By default, godoc uses the system's GOOS/GOARCH; in command-line mode you can
 set the GOOS/GOARCH environment variables to get output for the system specified.
@@ -127,6 +201,17 @@ For instance, http://golang.org/pkg/math/big/?m=all,text shows the documentation
 for all (not just the exported) declarations of package big, in textual form (as
 it would appear when using godoc from the command line: "godoc -src math/big .*").
 
+Go source files are shown with their keywords, strings and comments
+highlighted; the URL parameter "hl=0" turns off all but the comments, as in
+http://localhost:6060/src/fmt/print.go?hl=0.
+
+For programs, the documentation of a package is also served as JSON under
+/api/pkg/, as in http://localhost:6060/api/pkg/math/big?format=json. It holds
+the package's doc comment, and its constants, variables, types, functions and
+examples, with their doc comments, declarations, and the files and lines they
+are in. The "m" parameter accepts "all" and "methods" there, and "GOOS" and
+"GOARCH" apply as on the web pages.
+
 By default, godoc serves files from the file system of the underlying OS.
 Instead, a .zip file may be provided via the -zip flag, which contains
 the file system to serve. The file paths stored in the .zip file must use
@@ -142,6 +227,9 @@ one may run godoc as follows:
 
 Godoc documentation is converted to HTML or to text using the go/doc package;
 see http://golang.org/pkg/go/doc/#ToHTML for the exact rules.
+With -markdown, godoc also renders, in HTML only, lines between ``` fences
+as preformatted text, runs of lines beginning with -, *, + or a number
+followed by . or ) after a blank line as lists, and [text](url) as links.
 Godoc also shows example code that is runnable by the testing package;
 see http://golang.org/pkg/testing/#hdr-Examples for the conventions.
 See "Godoc: documenting Go code" for how to write good comments for godoc:
@@ -0,0 +1,234 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !appengine
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// exportRoots are the pages from which -export crawls the site.
+var exportRoots = []string{"/", "/pkg/", "/cmd/", "/src/"}
+
+// exportSkip lists the URL paths whose pages -export does not follow,
+// as they need a server to be of use.
+var exportSkip = []string{"/search", "/opensearch.xml", "/fmt", "/debug/", "/api/", "/doc/codewalk/"}
+
+// exportLinkRx matches the attributes holding the links of a page.
+var exportLinkRx = regexp.MustCompile(`(href|src)="([^"]*)"`)
+
+// An exporter writes a static mirror of the pages godoc serves to a directory.
+type exporter struct {
+	dir       string
+	queue     []string          // URL paths to fetch
+	seen      map[string]bool   // URL paths queued
+	files     map[string]string // URL path to the name of its file, slash-separated, relative to dir
+	redirects map[string]string // URL path to the one it redirects to
+	pages     map[string]string // name of each HTML file written to its URL path
+}
+
+// exportSite crawls the pages godoc serves, starting from exportRoots and the
+// files under /lib/godoc, and writes them to dir. Directories are written as
+// index.html files in them, and HTML pages whose names do not end in .html,
+// such as those of source files, get that extension added. Once all pages are
+// written, their links are rewritten to refer to the files relative to them,
+// so the mirror can be published at any URL.
+func exportSite(dir string) error {
+	e := &exporter{
+		dir:       dir,
+		seen:      make(map[string]bool),
+		files:     make(map[string]string),
+		redirects: make(map[string]string),
+		pages:     make(map[string]string),
+	}
+	for _, path := range exportRoots {
+		e.add(path)
+	}
+	if err := e.addStatic("/lib/godoc"); err != nil {
+		return err
+	}
+	for len(e.queue) > 0 {
+		path := e.queue[0]
+		e.queue = e.queue[1:]
+		if err := e.fetch(path); err != nil {
+			return err
+		}
+	}
+	for name, path := range e.pages {
+		if err := e.rewriteLinks(name, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add queues the page at path, unless it was queued before or is skipped.
+func (e *exporter) add(path string) {
+	if e.seen[path] {
+		return
+	}
+	for _, skip := range exportSkip {
+		if path == skip || strings.HasSuffix(skip, "/") && strings.HasPrefix(path, skip) {
+			return
+		}
+	}
+	e.seen[path] = true
+	e.queue = append(e.queue, path)
+}
+
+// addStatic queues the files in and below the directory dir of fs, except
+// for the templates, which are not served as they are.
+func (e *exporter) addStatic(dir string) error {
+	list, err := fs.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range list {
+		path := pathpkg.Join(dir, fi.Name())
+		if fi.IsDir() {
+			if err := e.addStatic(path); err != nil {
+				return err
+			}
+			continue
+		}
+		switch pathpkg.Ext(path) {
+		case ".html", ".txt", ".xml":
+			continue
+		}
+		e.add(path)
+	}
+	return nil
+}
+
+// fetch serves the page at path, writes it to its file and queues the pages
+// it links to. Pages that cannot be served are logged and left out.
+func (e *exporter) fetch(path string) error {
+	req := &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Path: path},
+		Header: make(http.Header),
+	}
+	w := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(w, req)
+
+	switch w.Code {
+	case http.StatusOK:
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect:
+		if to, ok := localLink(path, w.HeaderMap.Get("Location")); ok {
+			e.redirects[path] = to.Path
+			e.add(to.Path)
+		}
+		return nil
+	default:
+		if *verbose {
+			log.Printf("export: %s: HTTP error %d", path, w.Code)
+		}
+		return nil
+	}
+
+	name := exportName(path, w.HeaderMap.Get("Content-Type"))
+	file := filepath.Join(e.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, w.Body.Bytes(), 0644); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Printf("export: %s -> %s", path, file)
+	}
+	e.files[path] = name
+
+	if isHTML(w.HeaderMap.Get("Content-Type")) {
+		e.pages[name] = path
+		for _, m := range exportLinkRx.FindAllSubmatch(w.Body.Bytes(), -1) {
+			if u, ok := localLink(path, string(m[2])); ok {
+				e.add(u.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// localLink resolves the link ref on the page at path and reports whether it
+// refers to a page of the site. The query is dropped, as the mirror only has
+// the pages without one.
+func localLink(path, ref string) (*url.URL, bool) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, false
+	}
+	u = (&url.URL{Path: path}).ResolveReference(u)
+	if u.Scheme != "" || u.Host != "" || u.Opaque != "" || u.Path == "" {
+		return nil, false
+	}
+	u.RawQuery = ""
+	return u, true
+}
+
+// rewriteLinks rewrites the links to exported pages in the HTML file name,
+// of the page at path, to be relative to it.
+func (e *exporter) rewriteLinks(name, path string) error {
+	file := filepath.Join(e.dir, filepath.FromSlash(name))
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	data = exportLinkRx.ReplaceAllFunc(data, func(attr []byte) []byte {
+		m := exportLinkRx.FindSubmatch(attr)
+		u, ok := localLink(path, string(m[2]))
+		if !ok {
+			return attr
+		}
+		target := u.Path
+		for i := 0; i < 10 && e.redirects[target] != ""; i++ {
+			target = e.redirects[target]
+		}
+		to, ok := e.files[target]
+		if !ok {
+			return attr
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(pathpkg.Dir(name)), filepath.FromSlash(to))
+		if err != nil {
+			return attr
+		}
+		ref := filepath.ToSlash(rel)
+		if u.Fragment != "" {
+			ref += "#" + u.Fragment
+		}
+		return []byte(fmt.Sprintf(`%s="%s"`, m[1], ref))
+	})
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// exportName returns the name of the file, relative to the export directory,
+// of the page at path with the given content type.
+func exportName(path, contentType string) string {
+	name := strings.TrimPrefix(path, "/")
+	switch {
+	case name == "" || strings.HasSuffix(name, "/"):
+		name += "index.html"
+	case isHTML(contentType) && pathpkg.Ext(name) != ".html":
+		name += ".html"
+	}
+	return name
+}
+
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/html"
+}
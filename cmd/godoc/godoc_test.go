@@ -12,6 +12,8 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -393,17 +395,18 @@ func main() { print(lib.V) }
 
 	// Make an HTTP request and check for a regular expression match.
 	// The patterns are very crude checks that basic type information
-	// has been annotated onto the source view.
+	// has been annotated onto the source view, which is requested
+	// without highlighting to keep them simple.
 tryagain:
 	for _, test := range []struct{ url, pattern string }{
-		{"/src/lib/lib.go", "L2.*package .*Package docs for lib.*/lib"},
-		{"/src/lib/lib.go", "L3.*type .*type info for T.*struct"},
-		{"/src/lib/lib.go", "L5.*var V .*type T struct"},
-		{"/src/lib/lib.go", "L6.*func .*type T struct.*T.*return .*const C untyped int.*C"},
-
-		{"/src/app/main.go", "L2.*package .*Package docs for app"},
-		{"/src/app/main.go", "L3.*import .*Package docs for lib.*lib"},
-		{"/src/app/main.go", "L4.*func main.*package lib.*lib.*var lib.V lib.T.*V"},
+		{"/src/lib/lib.go?hl=0", "L2.*package .*Package docs for lib.*/lib"},
+		{"/src/lib/lib.go?hl=0", "L3.*type .*type info for T.*struct"},
+		{"/src/lib/lib.go?hl=0", "L5.*var V .*type T struct"},
+		{"/src/lib/lib.go?hl=0", "L6.*func .*type T struct.*T.*return .*const C untyped int.*C"},
+
+		{"/src/app/main.go?hl=0", "L2.*package .*Package docs for app"},
+		{"/src/app/main.go?hl=0", "L3.*import .*Package docs for lib.*lib"},
+		{"/src/app/main.go?hl=0", "L4.*func main.*package lib.*lib.*var lib.V lib.T.*V"},
 	} {
 		url := fmt.Sprintf("http://%s%s", addr, test.url)
 		resp, err := http.Get(url)
@@ -441,8 +444,154 @@ tryagain:
 	}
 }
 
-// godocEnv returns the process environment without the GOPATH variable.
-// (We don't want the indexer looking at the local workspace during tests.)
+// Basic integration test for godoc -export.
+func TestExport(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "godoc-export")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(tmpdir)
+	goroot := filepath.Join(tmpdir, "goroot")
+	file := filepath.Join(goroot, "src", "lib", "lib.go")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("MkdirAll(%s) failed: %s", filepath.Dir(file), err)
+	}
+	if err := ioutil.WriteFile(file, []byte("package lib\n\n// T is a type.\ntype T int\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bin, cleanup := buildGodoc(t)
+	defer cleanup()
+	site := filepath.Join(tmpdir, "site")
+	cmd := exec.Command(bin, "-goroot="+goroot, "-export="+site)
+	cmd.Env = godocEnv()
+	cmd.Args[0] = "godoc"
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("godoc -export failed: %v\n%s", err, out)
+	}
+
+	for _, test := range []struct{ file, pattern string }{
+		{"src/lib/index.html", `href="lib.go.html"`},
+		{"src/lib/index.html", `href="../../lib/godoc/style.css"`},
+		{"src/lib/lib.go.html", `<span class="keyword">type</span> T int`},
+		{"lib/godoc/style.css", `body`},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(site, filepath.FromSlash(test.file)))
+		if err != nil {
+			t.Errorf("reading exported file: %v", err)
+			continue
+		}
+		if !regexp.MustCompile(test.pattern).Match(data) {
+			t.Errorf("%s: doesn't match %q, got:\n%s", test.file, test.pattern, data)
+		}
+	}
+}
+
+// Basic integration test for godoc -templates.
+func TestTemplates(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "godoc-templates")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(tmpdir)
+	for file, content := range map[string]string{
+		"goroot/src/lib/lib.go": "package lib\n",
+		"templates/godoc.html":  "<title>{{html .Title}}</title>\ncustom frame\n{{printf \"%s\" .Body}}\n",
+		"templates/theme.css":   "@import \"dark.css\";\n",
+	} {
+		file = filepath.Join(tmpdir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("MkdirAll(%s) failed: %s", filepath.Dir(file), err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bin, cleanup := buildGodoc(t)
+	defer cleanup()
+	for _, test := range []struct{ url, pattern string }{
+		{"/src/lib/", `(?s)custom frame.*lib\.go`},     // overridden template
+		{"/lib/godoc/theme.css", `@import "dark.css"`}, // overridden static file
+		{"/lib/godoc/style.css", `body {`},             // built-in static file
+		{"/lib/godoc/dark.css", `@media screen`},       // built-in theme
+	} {
+		cmd := exec.Command(bin,
+			"-goroot="+filepath.Join(tmpdir, "goroot"),
+			"-templates="+filepath.Join(tmpdir, "templates"),
+			"-url="+test.url)
+		cmd.Env = godocEnv()
+		cmd.Args[0] = "godoc"
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Errorf("godoc -url=%s failed: %v\n%s", test.url, err, out)
+			continue
+		}
+		if !regexp.MustCompile(test.pattern).Match(out) {
+			t.Errorf("godoc -url=%s: doesn't match %q, got:\n%s", test.url, test.pattern, out)
+		}
+	}
+}
+
+// Basic integration test for godoc -play_backend: requests to /compile from
+// the pages of the server are forwarded to the backend, and those from the
+// pages of other sites refused.
+func TestPlayBackend(t *testing.T) {
+	var compiled []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compiled = append(compiled, r.FormValue("body"))
+		fmt.Fprint(w, `{"Errors":"","Events":[{"Message":"hello\n","Kind":"stdout"}]}`)
+	}))
+	defer backend.Close()
+
+	bin, cleanup := buildGodoc(t)
+	defer cleanup()
+	addr := serverAddress(t)
+	cmd := exec.Command(bin, "-http="+addr, "-play", "-play_backend="+backend.URL)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Args[0] = "godoc"
+	cmd.Env = godocEnv()
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start godoc: %s", err)
+	}
+	defer killAndWait(cmd)
+	waitForServerReady(t, addr)
+
+	for _, test := range []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusOK},
+		{"http://" + addr, http.StatusOK},
+		{"http://example.com", http.StatusForbidden},
+	} {
+		compiled = nil
+		req, err := http.NewRequest("POST", fmt.Sprintf("http://%s/compile", addr), strings.NewReader(url.Values{"version": {"2"}, "body": {"package main"}}.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if test.origin != "" {
+			req.Header.Set("Origin", test.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /compile: %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("POST /compile from %q: got status %v, want %v", test.origin, resp.Status, test.status)
+			continue
+		}
+		forwarded := len(compiled) == 1 && compiled[0] == "package main" && strings.Contains(string(body), "hello")
+		if forwarded != (test.status == http.StatusOK) {
+			t.Errorf("POST /compile from %q: forwarded %q, got %q", test.origin, compiled, body)
+		}
+	}
+}
+
 func godocEnv() (env []string) {
 	for _, v := range os.Environ() {
 		if strings.HasPrefix(v, "GOPATH=") {
@@ -80,23 +80,33 @@ var (
 	// command-line searches
 	query = flag.Bool("q", false, "arguments are considered search queries")
 
+	// static site
+	exportDir = flag.String("export", "", "directory to write a static HTML mirror of the web pages to")
+
 	verbose = flag.Bool("v", false, "verbose mode")
 
 	// file system roots
 	// TODO(gri) consider the invariant that goroot always end in '/'
 	goroot = flag.String("goroot", runtime.GOROOT(), "Go root directory")
 
+	// other versions of the source tree
+	versions = flag.String("versions", "", "comma-separated list of name=dir pairs naming other versions of the source tree to serve; each dir is a Go root or workspace")
+
 	// layout control
 	tabWidth       = flag.Int("tabwidth", 4, "tab width")
 	showTimestamps = flag.Bool("timestamps", false, "show timestamps with directory listings")
-	templateDir    = flag.String("templates", "", "directory containing alternate template files")
+	templateDir    = flag.String("templates", "", "directory containing template and static files that replace the built-in ones")
 	showPlayground = flag.Bool("play", false, "enable playground in web interface")
+	playBackend    = flag.String("play_backend", "", "`URL` of the playground service that runs the programs of -play; golang.org's if empty")
 	showExamples   = flag.Bool("ex", false, "show examples in command line mode")
 	declLinks      = flag.Bool("links", true, "link identifiers to their declarations")
+	docLinks       = flag.Bool("doclinks", false, "link the identifiers that doc comments mention to their documentation")
+	docMarkdown    = flag.Bool("markdown", false, "render links, code fences and lists in doc comments as in Markdown in HTML")
 
 	// search index
 	indexEnabled  = flag.Bool("index", false, "enable search index")
 	indexFiles    = flag.String("index_files", "", "glob pattern specifying index files; if not empty, the index is read from these files in sorted order")
+	indexCache    = flag.String("index_cache", "", "file in which to keep the search index between runs; only directories that changed are indexed again")
 	indexInterval = flag.Duration("index_interval", 0, "interval of indexing; 0 for default (5m), negative to only index once at startup")
 	maxResults    = flag.Int("maxresults", 10000, "maximum number of full text search results shown")
 	indexThrottle = flag.Float64("index_throttle", 0.75, "index throttle value; 0.0 = no time allocated, 1.0 = full throttle")
@@ -161,9 +171,15 @@ func main() {
 	flag.Parse()
 
 	playEnabled = *showPlayground
+	if *playBackend != "" && !*showPlayground {
+		log.Fatal("-play_backend applies only with -play")
+	}
+	if err := setPlayBackend(*playBackend); err != nil {
+		log.Fatal(err)
+	}
 
 	// Check usage: either server and no args, command line and args, or index creation mode
-	if (*httpAddr != "" || *urlFlag != "") != (flag.NArg() == 0) && !*writeIndex {
+	if (*httpAddr != "" || *urlFlag != "" || *exportDir != "") != (flag.NArg() == 0) && !*writeIndex {
 		usage()
 	}
 
@@ -184,10 +200,10 @@ func main() {
 		defer rc.Close() // be nice (e.g., -writeIndex mode)
 		fs.Bind("/", zipfs.New(rc, *zipfile), *goroot, vfs.BindReplace)
 	}
+	fs.Bind("/lib/godoc", mapfs.New(static.Files), "/", vfs.BindReplace)
 	if *templateDir != "" {
+		// files in the directory take precedence over the built-in ones
 		fs.Bind("/lib/godoc", vfs.OS(*templateDir), "/", vfs.BindBefore)
-	} else {
-		fs.Bind("/lib/godoc", mapfs.New(static.Files), "/", vfs.BindReplace)
 	}
 
 	// Bind $GOPATH trees into Go root.
@@ -195,6 +211,20 @@ func main() {
 		fs.Bind("/src", gatefs.New(vfs.OS(p), fsGate), "/src", vfs.BindAfter)
 	}
 
+	// Bind the trees of other versions beside it.
+	var versionNames []string
+	if *versions != "" {
+		for _, v := range strings.Split(*versions, ",") {
+			i := strings.Index(v, "=")
+			if i <= 0 || strings.Contains(v[:i], "/") {
+				log.Fatalf("-versions: %q is not of the form name=dir", v)
+			}
+			name, dir := v[:i], v[i+1:]
+			fs.Bind(godoc.VersionsDir+"/"+name, gatefs.New(vfs.OS(dir), fsGate), "/", vfs.BindReplace)
+			versionNames = append(versionNames, name)
+		}
+	}
+
 	httpMode := *httpAddr != ""
 
 	var typeAnalysis, pointerAnalysis bool
@@ -213,12 +243,14 @@ func main() {
 
 	corpus := godoc.NewCorpus(fs)
 	corpus.Verbose = *verbose
+	corpus.Versions = versionNames
 	corpus.MaxResults = *maxResults
 	corpus.IndexEnabled = *indexEnabled && httpMode
 	if *maxResults == 0 {
 		corpus.IndexFullText = false
 	}
 	corpus.IndexFiles = *indexFiles
+	corpus.IndexCacheFile = *indexCache
 	corpus.IndexDirectory = indexDirectoryDefault
 	corpus.IndexThrottle = *indexThrottle
 	corpus.IndexInterval = *indexInterval
@@ -226,7 +258,7 @@ func main() {
 		corpus.IndexThrottle = 1.0
 		corpus.IndexEnabled = true
 	}
-	if *writeIndex || httpMode || *urlFlag != "" {
+	if *writeIndex || httpMode || *urlFlag != "" || *exportDir != "" {
 		if err := corpus.Init(); err != nil {
 			log.Fatal(err)
 		}
@@ -236,15 +268,18 @@ func main() {
 	pres.TabWidth = *tabWidth
 	pres.ShowTimestamps = *showTimestamps
 	pres.ShowPlayground = *showPlayground
+	pres.DisableShare = *playBackend != ""
 	pres.ShowExamples = *showExamples
 	pres.DeclLinks = *declLinks
+	pres.DocLinks = *docLinks
+	pres.DocMarkdown = *docMarkdown
 	pres.SrcMode = *srcMode
 	pres.HTMLMode = *html
 	if *notesRx != "" {
 		pres.NotesRx = regexp.MustCompile(*notesRx)
 	}
 
-	readTemplates(pres, httpMode || *urlFlag != "")
+	readTemplates(pres, httpMode || *urlFlag != "" || *exportDir != "")
 	registerHandlers(pres)
 
 	if *writeIndex {
@@ -279,6 +314,14 @@ func main() {
 		return
 	}
 
+	// Write the pages that would be served to *exportDir.
+	if *exportDir != "" {
+		if err := exportSite(*exportDir); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if httpMode {
 		// HTTP server mode.
 		var handler http.Handler = http.DefaultServeMux
@@ -6,6 +6,27 @@
 
 package main
 
-// This package registers "/compile" and "/share" handlers
-// that redirect to the golang.org playground.
-import _ "golang.org/x/tools/playground"
+// This file sets up the "/compile" and "/share" handlers of the playground,
+// which run the programs of the playground and of the runnable examples.
+// They forward the requests to the golang.org playground, or to the
+// playground service that the -play_backend flag selects.
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/playground"
+)
+
+// setPlayBackend makes the playground run programs with the playground
+// service at the URL backend, or golang.org's if backend is "".
+func setPlayBackend(backend string) error {
+	switch {
+	case backend == "":
+	case strings.HasPrefix(backend, "http://"), strings.HasPrefix(backend, "https://"):
+		playground.BaseURL = strings.TrimSuffix(backend, "/")
+	default:
+		return fmt.Errorf("invalid playground backend %q: want the URL of a playground service", backend)
+	}
+	return nil
+}
@@ -27,6 +27,110 @@ For GoSublime, follow the steps described here:
 
 For other editors, you probably know what to do.
 
+With -l, goimports lists the files whose formatting or imports differ from
+its own and exits with status 1 if there are any, so that it can check a
+whole tree in continuous integration:
+
+     $ goimports -l .
+
+With -report=json, goimports prints, instead of each file whose imports it
+would change, a line of JSON naming the file, listing the import paths added
+and removed, and giving the byte offsets of the import declarations before
+and after, for tools that annotate the changes. Files whose formatting alone
+would change are not reported:
+
+     {"Filename":"b.go","Added":["fmt"],"Removed":["os"],"SrcStart":11,"SrcEnd":22,"Start":11,"End":23}
+
+Directories are processed recursively. To skip some of the files and
+directories in them, such as test data, vendored trees or generated code,
+pass glob patterns, separated by commas, with -exclude. A pattern containing
+a slash is matched against the whole path, others against its last element:
+
+     $ goimports -l -exclude testdata,vendor,*_string.go .
+
+With -w and -watch, goimports keeps running after fixing the files, and
+fixes each again once it has changed and then stayed the same for half a
+second. Directories may be written as in the go command:
+
+     $ goimports -w -watch ./...
+
+Editor plugins that would otherwise start goimports for every save can run
+it as a server with -listen, giving a TCP address or the path of a Unix
+domain socket after "unix:". Each connection carries a stream of JSON
+requests, each naming a file and holding the contents of its buffer,
+
+     {"Filename": "/path/to/file.go", "Src": "package p\n..."}
+
+answered in order by responses holding the processed contents, or an error:
+
+     {"Src": "package p\n..."}
+     {"Error": "file.go:3:1: expected declaration, found foo"}
+
+The index of the packages in GOPATH is kept for the life of the server, so
+only the first request that needs it waits for the scan.
+
+For code that deliberately departs from the formatting of gofmt, or to keep
+changes minimal, -importsonly rewrites only the import declarations, leaving
+the rest of each file as it is.
+
+To keep the imports of your own organization apart from other third-party
+packages, pass the prefixes of their paths, separated by commas, with -local:
+
+     $ goimports -local example.com/org,example.org/team -w file.go
+
+Imports with one of the prefixes are sorted into a group after the others.
+
+For more groups, or another order of them, list patterns, separated by commas,
+with -groups: "std" for the standard library, "*" for any other import, and
+path prefixes, of which the longest that matches an import wins. The imports
+are grouped in the order of the patterns, separated by blank lines:
+
+     $ goimports -groups std,appengine,k8s.io/*,example.com/*,* -w file.go
+
+To make names that many packages share always resolve to the packages your
+project has chosen, pass pairs of a name and an import path, separated by
+commas, with -prefer. The import is named if the package declares another name:
+
+     $ goimports -prefer errors=github.com/pkg/errors,log=github.com/sirupsen/logrus -w file.go
+
+Of the packages with a name that have the symbols the code refers to,
+goimports prefers the standard library, then those vendored nearest the file,
+then the dependencies the manifest of the project lists, and then the
+shortest paths. To pass over some, such as deprecated packages, unless no
+others will do, pass their paths, separated by commas, with -demote:
+
+     $ goimports -demote golang.org/x/net/context -w file.go
+
+To find packages, goimports scans the src directories of GOROOT and GOPATH,
+skipping directories named testdata, node_modules or bazel-out, or beginning
+with "." or "_". To skip more, such as huge trees of other languages or of
+generated code, list them in a .goimportsignore file in a src directory, one
+on each line, relative to it. Lines beginning with "#" are comments:
+
+     # $GOPATH/src/.goimportsignore
+     github.com/example/monorepo/frontend
+     example.org/generated
+
+Editors that pipe the contents of a buffer to goimports should name its file
+with -srcfile, so that the imports are chosen as if the source were read from
+it, seeing the vendor and internal directories above it:
+
+     $ goimports -srcfile path/to/file.go < buffer
+
+Editors with several unsaved buffers can pass their contents with -modified,
+as an archive on standard input in the format guru reads: for each file, its
+name, a newline, the decimal size of its contents, another newline, and the
+contents. The files named on the command line are read from the archive if
+it has them, and names declared by the other files of their packages, saved
+or not, are never taken for references to packages to import:
+
+     $ goimports -modified path/to/file.go < archive
+
+A server started with -listen takes the contents of the other unsaved buffers
+in each request instead, mapping the names of their files to them:
+
+     {"Filename": "/path/to/file.go", "Src": "package p\n...", "Modified": {"/path/to/other.go": "package p\n..."}}
+
 Happy hacking!
 
 */
@@ -6,6 +6,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/scanner"
@@ -17,15 +18,20 @@ import (
 	"runtime"
 	"strings"
 
+	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/imports"
 )
 
 var (
 	// main operation modes
-	list   = flag.Bool("l", false, "list files whose formatting differs from goimport's")
-	write  = flag.Bool("w", false, "write result to (source) file instead of stdout")
-	doDiff = flag.Bool("d", false, "display diffs instead of rewriting files")
-	srcdir = flag.String("srcdir", "", "choose imports as if source code is from `dir`")
+	list    = flag.Bool("l", false, "list files whose formatting differs from goimport's, exiting with status 1 if any")
+	write   = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	doDiff  = flag.Bool("d", false, "display diffs instead of rewriting files")
+	srcdir  = flag.String("srcdir", "", "choose imports as if source code is from `dir`")
+	srcfile = flag.String("srcfile", "", "choose imports as if source code read from standard input is the `file`")
+	exclude = flag.String("exclude", "", "skip files and directories matching these comma-separated glob `patterns` in directories")
+	prefer  = flag.String("prefer", "", "import these comma-separated `name=path` pairs for the names, whatever other packages there are")
+	demote  = flag.String("demote", "", "import packages with these comma-separated `paths`, or below them, only if no others will do")
 
 	options = &imports.Options{
 		TabWidth:  8,
@@ -34,10 +40,32 @@ var (
 		Fragment:  true,
 	}
 	exitCode = 0
+
+	// excludePatterns are the patterns of -exclude.
+	excludePatterns []string
 )
 
 func init() {
 	flag.BoolVar(&options.AllErrors, "e", false, "report all errors (not just the first 10 on different lines)")
+	flag.BoolVar(&options.Simplify, "s", false, "simplify code, as gofmt -s does")
+	flag.BoolVar(&options.ImportsOnly, "importsonly", false, "rewrite only the import declarations, leaving the rest of the source unformatted")
+	flag.StringVar(&options.LocalPrefix, "local", "", "put imports beginning with this string after 3rd-party packages; comma-separated list")
+	flag.Var(groupsFlag{&options.Groups}, "groups", "group imports in the order of these comma-separated `patterns`: std, *, or path prefixes")
+}
+
+// groupsFlag is the flag.Value of -groups, setting Options.Groups.
+type groupsFlag struct{ groups *[]string }
+
+func (f groupsFlag) String() string {
+	if f.groups == nil {
+		return ""
+	}
+	return strings.Join(*f.groups, ",")
+}
+
+func (f groupsFlag) Set(s string) error {
+	*f.groups = strings.Split(s, ",")
+	return nil
 }
 
 func report(err error) {
@@ -57,6 +85,8 @@ func isGoFile(f os.FileInfo) bool {
 	return !f.IsDir() && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".go")
 }
 
+var modified = flag.Bool("modified", false, "read an archive of modified files, as for guru, from standard input")
+
 func processFile(filename string, in io.Reader, out io.Writer, stdin bool) error {
 	opt := options
 	if stdin {
@@ -65,6 +95,11 @@ func processFile(filename string, in io.Reader, out io.Writer, stdin bool) error
 		opt = &nopt
 	}
 
+	if in == nil && options.Overlay != nil {
+		if abs, err := filepath.Abs(filename); err == nil && options.Overlay[abs] != nil {
+			in = bytes.NewReader(options.Overlay[abs])
+		}
+	}
 	if in == nil {
 		f, err := os.Open(filename)
 		if err != nil {
@@ -80,13 +115,17 @@ func processFile(filename string, in io.Reader, out io.Writer, stdin bool) error
 	}
 
 	target := filename
-	if *srcdir != "" {
+	if stdin && *srcfile != "" {
+		// Pretend that standard input is *srcfile, so that its
+		// directory decides visible imports and local groups.
+		target = *srcfile
+	} else if *srcdir != "" {
 		// Pretend that file is from *srcdir in order to decide
 		// visible imports correctly.
 		target = filepath.Join(*srcdir, filepath.Base(filename))
 	}
 
-	res, err := imports.Process(target, src, opt)
+	res, changes, err := imports.ProcessChanges(target, src, opt)
 	if err != nil {
 		return err
 	}
@@ -95,6 +134,9 @@ func processFile(filename string, in io.Reader, out io.Writer, stdin bool) error
 		// formatting has changed
 		if *list {
 			fmt.Fprintln(out, filename)
+			if exitCode == 0 {
+				exitCode = 1
+			}
 		}
 		if *write {
 			err = ioutil.WriteFile(filename, res, 0)
@@ -103,22 +145,49 @@ func processFile(filename string, in io.Reader, out io.Writer, stdin bool) error
 			}
 		}
 		if *doDiff {
-			data, err := diff(src, res)
+			data, err := diff(src, res, filename)
 			if err != nil {
 				return fmt.Errorf("computing diff: %s", err)
 			}
-			fmt.Printf("diff %s gofmt/%s\n", filename, filename)
+			fmt.Fprintf(out, "diff -u %s %s\n", filepath.ToSlash(filename+".orig"), filepath.ToSlash(filename))
 			out.Write(data)
 		}
+		if *reportFormat != "" && importsChanged(src, res, changes) {
+			data, err := json.Marshal(fileReport{filename, changes})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s\n", data)
+		}
 	}
 
-	if !*list && !*write && !*doDiff {
+	if !*list && !*write && !*doDiff && *reportFormat == "" {
 		_, err = out.Write(res)
 	}
 
 	return err
 }
 
+var reportFormat = flag.String("report", "", "print reports of the changes to the imports of files in `format` json, instead of the files")
+
+// A fileReport is the line that -report=json prints for a file.
+type fileReport struct {
+	Filename string
+	*imports.Changes
+}
+
+// importsChanged reports whether the changes from src to res, described by c,
+// are to the imports, rather than only to the formatting of the rest.
+func importsChanged(src, res []byte, c *imports.Changes) bool {
+	if len(c.Added) > 0 || len(c.Removed) > 0 {
+		return true
+	}
+	if c.SrcStart < 0 {
+		return false
+	}
+	return !bytes.Equal(src[c.SrcStart:c.SrcEnd], res[c.Start:c.End])
+}
+
 func visitFile(path string, f os.FileInfo, err error) error {
 	if err == nil && isGoFile(f) {
 		err = processFile(path, nil, os.Stdout, false)
@@ -129,8 +198,35 @@ func visitFile(path string, f os.FileInfo, err error) error {
 	return nil
 }
 
-func walkDir(path string) {
-	filepath.Walk(path, visitFile)
+// walkDir walks the tree rooted at path, calling visit for each file or
+// directory in it that is not excluded by -exclude.
+func walkDir(path string, visit filepath.WalkFunc) {
+	filepath.Walk(path, func(p string, f os.FileInfo, err error) error {
+		if err == nil && p != path && isExcluded(p) {
+			if f.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return visit(p, f, err)
+	})
+}
+
+// isExcluded reports whether path, found in a directory being walked,
+// matches one of the -exclude patterns. A pattern containing a slash is
+// matched against the whole path, others against its last element.
+func isExcluded(path string) bool {
+	path = filepath.ToSlash(path)
+	for _, pattern := range excludePatterns {
+		name := path
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(path)
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 func main() {
@@ -160,6 +256,94 @@ func gofmtMain() {
 		return
 	}
 
+	if *reportFormat != "" && *reportFormat != "json" {
+		fmt.Fprintf(os.Stderr, "unknown -report format %q\n", *reportFormat)
+		exitCode = 2
+		return
+	}
+
+	if *exclude != "" {
+		for _, pattern := range strings.Split(*exclude, ",") {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				fmt.Fprintf(os.Stderr, "bad -exclude pattern %q: %v\n", pattern, err)
+				exitCode = 2
+				return
+			}
+			excludePatterns = append(excludePatterns, pattern)
+		}
+	}
+
+	if *prefer != "" {
+		options.Prefer = make(map[string]string)
+		for _, pair := range strings.Split(*prefer, ",") {
+			eq := strings.Index(pair, "=")
+			if eq <= 0 || eq == len(pair)-1 {
+				fmt.Fprintf(os.Stderr, "bad -prefer pair %q, want name=path\n", pair)
+				exitCode = 2
+				return
+			}
+			options.Prefer[pair[:eq]] = pair[eq+1:]
+		}
+	}
+
+	if *demote != "" {
+		options.Demote = strings.Split(*demote, ",")
+	}
+
+	if *listenAddr != "" {
+		if len(paths) > 0 || *list || *write || *doDiff || *reportFormat != "" || *srcdir != "" || *srcfile != "" || *modified {
+			fmt.Fprintf(os.Stderr, "-listen cannot be used with paths or with -l, -w, -d, -report, -srcdir, -srcfile or -modified\n")
+			exitCode = 2
+			return
+		}
+		listenMain(*listenAddr)
+		return
+	}
+
+	if *modified {
+		if len(paths) == 0 {
+			fmt.Fprintf(os.Stderr, "-modified reads standard input, so paths must be given\n")
+			exitCode = 2
+			return
+		}
+		overlay, err := buildutil.ParseOverlayArchive(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-modified: %v\n", err)
+			exitCode = 2
+			return
+		}
+		options.Overlay = make(map[string][]byte)
+		for name, src := range overlay {
+			if abs, err := filepath.Abs(name); err == nil {
+				options.Overlay[abs] = src
+			}
+		}
+	}
+
+	if *srcfile != "" {
+		if len(paths) > 0 {
+			fmt.Fprintf(os.Stderr, "-srcfile can only be used when reading standard input\n")
+			exitCode = 2
+			return
+		}
+		if *srcdir != "" {
+			fmt.Fprintf(os.Stderr, "-srcfile and -srcdir cannot be used together\n")
+			exitCode = 2
+			return
+		}
+	}
+
+	if *watch {
+		if !*write || len(paths) == 0 {
+			fmt.Fprintf(os.Stderr, "-watch requires -w and paths to watch\n")
+			exitCode = 2
+			return
+		}
+		for i, path := range paths {
+			paths[i] = strings.TrimSuffix(path, "/...")
+		}
+	}
+
 	if len(paths) == 0 {
 		if err := processFile("<standard input>", os.Stdin, os.Stdout, true); err != nil {
 			report(err)
@@ -172,16 +356,20 @@ func gofmtMain() {
 		case err != nil:
 			report(err)
 		case dir.IsDir():
-			walkDir(path)
+			walkDir(path, visitFile)
 		default:
 			if err := processFile(path, nil, os.Stdout, false); err != nil {
 				report(err)
 			}
 		}
 	}
+
+	if *watch {
+		watchPaths(paths)
+	}
 }
 
-func diff(b1, b2 []byte) (data []byte, err error) {
+func diff(b1, b2 []byte, filename string) (data []byte, err error) {
 	f1, err := ioutil.TempFile("", "gofmt")
 	if err != nil {
 		return
@@ -203,7 +391,37 @@ func diff(b1, b2 []byte) (data []byte, err error) {
 	if len(data) > 0 {
 		// diff exits with a non-zero status when the files don't match.
 		// Ignore that failure as long as we get output.
-		err = nil
+		return replaceTempFilename(data, filename)
 	}
 	return
 }
+
+// replaceTempFilename replaces the names of the temporary files in the
+// header of diff with filename, as gofmt -d does:
+//
+//	--- /tmp/gofmt316145376	2017-02-03 19:13:00.280468375 -0500
+//	+++ /tmp/gofmt617882815	2017-02-03 19:13:00.280468375 -0500
+//
+// becomes
+//
+//	--- path/to/file.go.orig	2017-02-03 19:13:00.280468375 -0500
+//	+++ path/to/file.go	2017-02-03 19:13:00.280468375 -0500
+func replaceTempFilename(diff []byte, filename string) ([]byte, error) {
+	bs := bytes.SplitN(diff, []byte{'\n'}, 3)
+	if len(bs) < 3 {
+		return nil, fmt.Errorf("got unexpected diff for %s", filename)
+	}
+	// Preserve timestamps.
+	var t0, t1 []byte
+	if i := bytes.LastIndexByte(bs[0], '\t'); i != -1 {
+		t0 = bs[0][i:]
+	}
+	if i := bytes.LastIndexByte(bs[1], '\t'); i != -1 {
+		t1 = bs[1][i:]
+	}
+	// Always print filepath with slash separator.
+	f := filepath.ToSlash(filename)
+	bs[0] = []byte(fmt.Sprintf("--- %s%s", f+".orig", t0))
+	bs[1] = []byte(fmt.Sprintf("+++ %s%s", f, t1))
+	return bytes.Join(bs, []byte{'\n'}), nil
+}
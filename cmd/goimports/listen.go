@@ -0,0 +1,112 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+var listenAddr = flag.String("listen", "", "serve JSON requests to process buffers on `address`, a TCP address or unix:path")
+
+// A request asks the server to process the contents of a buffer.
+type request struct {
+	Filename string            // the file the buffer is of, deciding the visible imports
+	Src      string            // the contents of the buffer
+	Modified map[string]string // the contents of other unsaved buffers, by file name
+}
+
+// options returns the options for processing the buffer of req, in which the
+// files of req.Modified are read from it, as with -modified.
+func (req *request) options() *imports.Options {
+	if len(req.Modified) == 0 {
+		return options
+	}
+	opt := *options
+	opt.Overlay = make(map[string][]byte)
+	for name, src := range req.Modified {
+		if abs, err := filepath.Abs(name); err == nil {
+			opt.Overlay[abs] = []byte(src)
+		}
+	}
+	return &opt
+}
+
+// A response holds the processed buffer, or why it could not be processed.
+type response struct {
+	Src   string `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
+
+// listen serves requests on addr, which is a TCP address, or the path of a
+// Unix domain socket after "unix:", until it fails. Each connection carries
+// a stream of JSON requests, each answered by a JSON response, in order.
+// The index of the packages in GOPATH is built for the first request that
+// needs it and kept for the life of the server.
+func listen(addr string) error {
+	network := "tcp"
+	if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", addr[len("unix:"):]
+		// Remove the socket left by a previous server.
+		os.Remove(addr)
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serve(conn)
+	}
+}
+
+// serve answers the requests on conn until it is closed.
+func serve(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			if err != io.EOF {
+				log.Printf("goimports: reading request: %v", err)
+			}
+			return
+		}
+		var resp response
+		if req.Filename == "" {
+			resp.Error = "no filename in request"
+		} else if res, err := imports.Process(req.Filename, []byte(req.Src), req.options()); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Src = string(res)
+		}
+		if err := enc.Encode(&resp); err != nil {
+			log.Printf("goimports: writing response: %v", err)
+			return
+		}
+	}
+}
+
+// listenMain runs the server for -listen, reporting its failure.
+func listenMain(addr string) {
+	if err := listen(addr); err != nil {
+		fmt.Fprintf(os.Stderr, "goimports: %v\n", err)
+		exitCode = 2
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"go/build"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// gopath is the GOPATH the tests find packages in. The index of the packages
+// is built once, so all the tests share it.
+var gopath string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "goimports")
+	if err != nil {
+		panic(err)
+	}
+	files := map[string]string{
+		"src/x.io/bar/bar.go":            "package bar\nvar Baz = 1\n",
+		"src/github.com/long/bar/bar.go": "package bar\nvar Baz = 1\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			panic(err)
+		}
+	}
+	gopath = dir
+	build.Default.GOPATH = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// process sends req on conn and returns the response to it.
+func process(t *testing.T, conn net.Conn, req request) response {
+	if err := json.NewEncoder(conn).Encode(&req); err != nil {
+		t.Fatal(err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// Tests that the flags, such as -demote, apply to the requests served with
+// -listen.
+func TestListenDemote(t *testing.T) {
+	switch runtime.GOOS {
+	case "windows", "plan9", "nacl":
+		t.Skipf("no Unix domain sockets on %s", runtime.GOOS)
+	}
+	dir, err := ioutil.TempDir("", "listen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "sock")
+
+	oldParseFlags := parseFlags
+	defer func() {
+		parseFlags = oldParseFlags
+		flag.Set("listen", "")
+		flag.Set("demote", "")
+		options.Demote = nil
+	}()
+	parseFlags = func() []string { return nil }
+	flag.Set("listen", "unix:"+sock)
+	flag.Set("demote", "x.io")
+	// The server runs until the test binary exits.
+	go gofmtMain()
+
+	var conn net.Conn
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if conn, err = net.Dial("unix", sock); err == nil {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal(err)
+		}
+	}
+	defer conn.Close()
+
+	input := `package app
+
+var _ = bar.Baz
+`
+	want := `package app
+
+import "github.com/long/bar"
+
+var _ = bar.Baz
+`
+	resp := process(t, conn, request{
+		Filename: filepath.Join(gopath, "src/app/app.go"),
+		Src:      input,
+	})
+	if resp.Error != "" {
+		t.Fatal(resp.Error)
+	}
+	if resp.Src != want {
+		t.Errorf("results differ\nGOT:\n%s\nWANT:\n%s\n", resp.Src, want)
+	}
+}
+
+// Tests that names declared by the unsaved buffers of a request are not taken
+// for packages.
+func TestListenModified(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go serve(server)
+
+	filename := filepath.Join(gopath, "src/app/app.go")
+	input := `package app
+
+var _ = bar.Baz
+`
+	tests := []struct {
+		modified map[string]string
+		want     string
+	}{
+		{
+			want: `package app
+
+import "x.io/bar"
+
+var _ = bar.Baz
+`,
+		},
+		{
+			modified: map[string]string{
+				filepath.Join(gopath, "src/app/other.go"): "package app\n\nvar bar struct{ Baz int }\n",
+			},
+			want: input,
+		},
+	}
+	for _, tt := range tests {
+		resp := process(t, client, request{Filename: filename, Src: input, Modified: tt.modified})
+		if resp.Error != "" {
+			t.Fatal(resp.Error)
+		}
+		if resp.Src != tt.want {
+			t.Errorf("results differ with %d modified files\nGOT:\n%s\nWANT:\n%s\n", len(tt.modified), resp.Src, tt.want)
+		}
+	}
+}
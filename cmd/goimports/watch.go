@@ -0,0 +1,75 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+)
+
+var watch = flag.Bool("watch", false, "with -w, keep watching the paths and fix files as they change")
+
+// watchInterval is how often -watch looks for changed files. A changed file
+// is fixed once it has stayed the same for a whole interval, so that one
+// being saved in several writes is fixed only once.
+const watchInterval = 500 * time.Millisecond
+
+// watchPaths fixes the Go files in paths, which have just been fixed, and in
+// the trees of those that are directories whenever they change, until the
+// process is killed. Files are found by polling their modification times.
+func watchPaths(paths []string) {
+	done := scanGoFiles(paths) // modification times of the files fixed
+	pending := make(map[string]time.Time)
+	for {
+		time.Sleep(watchInterval)
+		current := scanGoFiles(paths)
+		for name, mod := range current {
+			switch {
+			case mod.Equal(done[name]):
+				delete(pending, name)
+			case mod.Equal(pending[name]):
+				delete(pending, name)
+				if err := processFile(name, nil, os.Stdout, false); err != nil {
+					report(err)
+				}
+				// Record the time of our own write, if any, so that it
+				// is not taken for a change.
+				if fi, err := os.Stat(name); err == nil {
+					mod = fi.ModTime()
+				}
+				done[name] = mod
+			default:
+				pending[name] = mod
+			}
+		}
+		for name := range done {
+			if _, ok := current[name]; !ok {
+				delete(done, name)
+			}
+		}
+		for name := range pending {
+			if _, ok := current[name]; !ok {
+				delete(pending, name)
+			}
+		}
+	}
+}
+
+// scanGoFiles returns the modification times of the Go files in paths and in
+// the trees of those that are directories, leaving out those excluded by
+// -exclude.
+func scanGoFiles(paths []string) map[string]time.Time {
+	mods := make(map[string]time.Time)
+	for _, path := range paths {
+		walkDir(path, func(p string, f os.FileInfo, err error) error {
+			if err == nil && isGoFile(f) {
+				mods[p] = f.ModTime()
+			}
+			return nil
+		})
+	}
+	return mods
+}
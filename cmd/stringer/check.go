@@ -0,0 +1,73 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -check flag.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+// checkFile compares the named file with the code in src, apart from the
+// header line, which records a command line that may be spelled differently
+// and the version of stringer. If they differ, it prints a diff from the file
+// to src and records the failure.
+func (j *job) checkFile(name string, src []byte) error {
+	old, err := ioutil.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("checking output: %s", err)
+	}
+	if bytes.Equal(withoutHeader(old), withoutHeader(src)) {
+		return nil
+	}
+	j.failed = true
+	data, err := diff(old, src)
+	if err != nil {
+		return fmt.Errorf("computing diff: %s", err)
+	}
+	fmt.Fprintf(&j.stdout, "diff %s stringer/%s\n", name, name)
+	j.stdout.Write(data)
+	return nil
+}
+
+// withoutHeader returns the generated code in src without its first line.
+func withoutHeader(src []byte) []byte {
+	if i := bytes.IndexByte(src, '\n'); i >= 0 {
+		return src[i+1:]
+	}
+	return nil
+}
+
+// diff returns the output of diff -u for the two versions of a file.
+func diff(b1, b2 []byte) (data []byte, err error) {
+	f1, err := ioutil.TempFile("", "stringer")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f1.Name())
+	defer f1.Close()
+
+	f2, err := ioutil.TempFile("", "stringer")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f2.Name())
+	defer f2.Close()
+
+	f1.Write(b1)
+	f2.Write(b2)
+
+	data, err = exec.Command("diff", "-u", f1.Name(), f2.Name()).CombinedOutput()
+	if len(data) > 0 {
+		// diff exits with a non-zero status when the files don't match.
+		// Ignore that failure as long as we get output.
+		err = nil
+	}
+	return
+}
@@ -9,6 +9,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"go/build"
 	"io"
@@ -16,14 +17,71 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 // This file contains a test that compiles and runs each program in testdata
 // after generating the string method for its type. The rule is that for testdata/x.go
 // we run stringer -type X and then compile and run the program. The resulting
 // binary panics if the String method for X is not correct, including for error cases.
+// Files listed in extraFlags are generated with the given flags as well.
+
+// extraFlags holds the additional stringer flags for testdata files that need them.
+var extraFlags = map[string][]string{
+	"alarm.go":   {"-slog"},
+	"big.go":     {"-isvalid"},
+	"byte.go":    {"-isvalid", "-bounds"},
+	"code.go":    {"-blocks", "-group"},
+	"coin.go":    {"-parse", "-fold", "-text"},
+	"color.go":   {"-parse", "-text", "-values", "-strings", "-isvalid", "-gostring", "-trimprefix=Color"},
+	"edge.go":    {"-isvalid", "-ordinal"},
+	"errno.go":   {"-erroronly", "-text"},
+	"event.go":   {"-prefixes", "-parse"},
+	"gap.go":     {"-ordinal"},
+	"level.go":   {"-json", "-sql", "-isvalid"},
+	"mode.go":    {"-yaml"},
+	"mood.go":    {"-text", "-textinvalid=name:Unknown", "-trimprefix=Mood"},
+	"number.go":  {"-valueof"},
+	"perm.go":    {"-flags", "-isvalid", "-parse"},
+	"phase.go":   {"-gob"},
+	"pointer.go": {"-ptr", "-isvalid"},
+	"rate.go":    {"-text", "-isvalid", "-trimprefix=Rate"},
+	"season.go":  {"-description", "-localize"},
+	"shade.go":   {"-format"},
+	"signal.go":  {"-invalid=unknown signal %v"},
+	"small.go":   {"-isvalid", "-ordinal"},
+	"sparse.go":  {"-lookup=binarysearch", "-isvalid", "-iter"},
+	"stage.go":   {"-method=Label", "-text"},
+	"state.go":   {"-parse", "-values", "-strings"},
+	"status.go":  {"-error", "-errorprefix"},
+	"tiny.go":    {"-nofmt", "-parse", "-gostring"},
+	"token.go":   {"-char=fallback"},
+	"wire.go":    {"-binary"},
+}
+
+// stringerPath is the path of the stringer binary that the tests run, built
+// once by TestMain.
+var stringerPath string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	stringerPath = filepath.Join(dir, "stringer.exe")
+	if err := run("go", "build", "-o", stringerPath); err != nil {
+		fmt.Fprintf(os.Stderr, "building stringer: %s\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
 
 func TestEndToEnd(t *testing.T) {
 	dir, err := ioutil.TempDir("", "stringer")
@@ -31,12 +89,6 @@ func TestEndToEnd(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer os.RemoveAll(dir)
-	// Create stringer in temporary directory.
-	stringer := filepath.Join(dir, "stringer.exe")
-	err = run("go", "build", "-o", stringer, "stringer.go")
-	if err != nil {
-		t.Fatalf("building stringer: %s", err)
-	}
 	// Read the testdata directory.
 	fd, err := os.Open("testdata")
 	if err != nil {
@@ -59,13 +111,760 @@ func TestEndToEnd(t *testing.T) {
 		}
 		// Names are known to be ASCII and long enough.
 		typeName := fmt.Sprintf("%c%s", name[0]+'A'-'a', name[1:len(name)-len(".go")])
-		stringerCompileAndRun(t, dir, stringer, typeName, name)
+		stringerCompileAndRun(t, dir, typeName, name)
+	}
+}
+
+// TestStdout checks that -output=- writes the same code that would be
+// written to the output file.
+func TestStdout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join("testdata", "day.go")
+	stringSource := filepath.Join(dir, "day_string.go")
+	err = run(stringerPath, "-type", "Day", "-output", stringSource, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(stringSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := exec.Command(stringerPath, "-type", "Day", "-output", "-", source).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header records the command line, which differs.
+	want = want[bytes.IndexByte(want, '\n'):]
+	got = got[bytes.IndexByte(got, '\n'):]
+	if !bytes.Equal(got, want) {
+		t.Errorf("stdout: got\n====\n%s====\nexpected\n====\n%s", got, want)
+	}
+}
+
+// TestHeader checks that the generated code is marked as such, with the lines
+// of the -header file following, and that a header line that is not a
+// comment is rejected.
+func TestHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join("testdata", "day.go")
+	header := filepath.Join(dir, "header.txt")
+	license := "// Copyright 2016 The Authors.\n//\n// Licensed under the terms in LICENSE.\n"
+	if err := ioutil.WriteFile(header, []byte(license), 0666); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(stringerPath, "-type=Day", "-header", header, "-output=-", source).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	generated := regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.\n`)
+	if loc := generated.FindIndex(out); loc == nil || !bytes.HasPrefix(out[loc[1]:], []byte(license+"\npackage main\n")) {
+		t.Errorf("header:\n%s", out)
+	}
+	if err := ioutil.WriteFile(header, []byte("// +build linux\nlinux\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	out, err = exec.Command(stringerPath, "-type=Day", "-header", header, "-output=-", source).CombinedOutput()
+	if _, ok := err.(*exec.ExitError); !ok || !bytes.Contains(out, []byte("header.txt:2: header line is not a // comment")) {
+		t.Errorf("not a comment: %v\n%s", err, out)
+	}
+}
+
+// TestOutputPkg writes the helpers for a type into another package of a
+// temporary GOPATH and runs a program that uses them.
+func TestOutputPkg(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"day/day.go": `package day
+
+type Day int
+
+const (
+	Monday Day = iota
+	Tuesday
+)
+`,
+		"pub/doc.go": "package pub\n",
+		"main/main.go": `package main
+
+import (
+	"fmt"
+
+	"example/day"
+	"example/pub"
+)
+
+func main() {
+	d, err := pub.ParseDay("Tuesday")
+	if err != nil || d != day.Tuesday {
+		panic("ParseDay")
+	}
+	if fmt.Sprint(pub.DayValues()) != "[0 1]" {
+		panic("DayValues")
+	}
+	if pub.DayCount != 2 || pub.DayMax != day.Tuesday {
+		panic("DayCount")
+	}
+}
+`,
+	}
+	src := filepath.Join(dir, "src", "example")
+	writeFiles(t, src, files)
+	env := append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off", "GOFLAGS=")
+	for _, args := range [][]string{
+		{stringerPath, "-type", "Day", "-parse", "-values", "-bounds", "-outputpkg", "pub", "-output", filepath.Join(src, "pub", "day_string.go"), filepath.Join(src, "day")},
+		{"go", "run", filepath.Join(src, "main", "main.go")},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Env = env
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("%s: %s", args[0], err)
+		}
+	}
+}
+
+// TestPerType generates the code for two types into one file, whose tables
+// they share, and with -pertype into a file for each, and runs the program.
+func TestPerType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "main.go")
+	err = ioutil.WriteFile(source, []byte(`package main
+
+import "fmt"
+
+type Day int
+
+const (
+	Monday Day = iota
+	Tuesday
+	Wednesday
+)
+
+type Color int
+
+const (
+	Red Color = iota + 1
+	Green
+	Blue Color = 10
+)
+
+func main() {
+	if fmt.Sprint(Monday, Wednesday, Day(3)) != "Monday Wednesday Day(3)" {
+		panic("Day")
+	}
+	if fmt.Sprint(Red, Green, Blue, Color(0)) != "Red Green Blue Color(0)" {
+		panic("Color")
+	}
+	if c, err := ParseColor("Blue"); err != nil || c != Blue {
+		panic("ParseColor")
+	}
+}
+`), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dayFile := filepath.Join(dir, "day_string.go")
+	colorFile := filepath.Join(dir, "color_string.go")
+	for _, test := range []struct {
+		flags []string
+		files []string
+	}{
+		{nil, []string{dayFile}},
+		{[]string{"-pertype"}, []string{dayFile, colorFile}},
+	} {
+		os.Remove(dayFile)
+		os.Remove(colorFile)
+		args := append([]string{"-type", "Day,Color", "-parse"}, test.flags...)
+		if err := run(stringerPath, append(args, source)...); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(colorFile); (err == nil) != (len(test.files) == 2) {
+			t.Errorf("%v: color_string.go written: %t", test.flags, err == nil)
+		}
+		code, err := ioutil.ReadFile(dayFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := bytes.Contains(code, []byte("_Day_shared_name")), len(test.flags) == 0; got != want {
+			t.Errorf("%v: shared tables declared: %t, want %t", test.flags, got, want)
+		}
+		if err := run("go", append([]string{"run", source}, test.files...)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestImportedConstants runs stringer on a package of a module whose
+// constants are declared in terms of those of another package of the module,
+// which the type checker finds through its export data.
+func TestImportedConstants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod":       "module example\n",
+		"base/base.go": "package base\n\nconst Base = 10\n\ntype Offset int\n\nconst Step Offset = 2\n",
+		"kind/kind.go": `package kind
+
+import "example/base"
+
+type Kind int
+
+const (
+	First Kind = base.Base + iota
+	Second
+	Third
+	Far Kind = base.Base * Kind(base.Step) * 5
+)
+`,
+		"main/main.go": `package main
+
+import (
+	"fmt"
+
+	"example/kind"
+)
+
+func main() {
+	if fmt.Sprint(kind.First, kind.Third, kind.Far, kind.Kind(0)) != "First Third Far Kind(0)" {
+		panic(fmt.Sprint(kind.First, kind.Third, kind.Far, kind.Kind(0)))
+	}
+}
+`,
+	}
+	src := filepath.Join(dir, "src")
+	writeFiles(t, src, files)
+	env := append(os.Environ(), "GO111MODULE=on", "GOFLAGS=-mod=mod")
+	for _, args := range [][]string{
+		{stringerPath, "-type", "Kind", "./kind"},
+		{"go", "run", "./main"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = src
+		cmd.Env = env
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("%s: %s", args[0], err)
+		}
+	}
+}
+
+// TestPatterns runs stringer on the packages matching ./... and checks that a
+// file is written into those that declare the type, and only those.
+func TestPatterns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	day := "\ntype Day int\n\nconst (\n\tMonday Day = iota\n\tTuesday\n)\n"
+	files := map[string]string{
+		"main.go": "package main\n\nimport \"fmt\"\n" + day + `
+func main() {
+	if fmt.Sprint(Tuesday) != "Tuesday" {
+		panic("Tuesday")
+	}
+}
+`,
+		"a/a.go":          "package a\n" + day,
+		"a/b/b.go":        "package b\n" + day,
+		"c/c.go":          "package c\n\ntype Color int\n",
+		"testdata/day.go": "package testdata\n" + day,
+	}
+	src := filepath.Join(dir, "src")
+	writeFiles(t, src, files)
+	cmd := exec.Command(stringerPath, "-type", "Day", "./...")
+	cmd.Dir = src
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"day_string.go":          true,
+		"a/day_string.go":        true,
+		"a/b/day_string.go":      true,
+		"c/day_string.go":        false,
+		"testdata/day_string.go": false,
+	} {
+		_, err := os.Stat(filepath.Join(src, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists is %t, want %t", name, got, want)
+		}
+	}
+	err = run("go", "run", filepath.Join(src, "main.go"), filepath.Join(src, "day_string.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPatternsErrors runs stringer in parallel on packages of which some
+// fail, and checks that each failure is reported, in order, and that the
+// other packages are written.
+func TestPatternsErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	day := "\ntype Day int\n\nconst (\n\tMonday Day = iota\n\tTuesday\n)\n"
+	files := map[string]string{
+		"a/a.go": "package a\n" + day,
+		"b/b.go": "package b\n\ntype Day int\n",
+		"c/c.go": "package c\n\ntype Day string\n",
+		"d/d.go": "package d\n" + day,
+	}
+	src := filepath.Join(dir, "src")
+	writeFiles(t, src, files)
+	cmd := exec.Command(stringerPath, "-type", "Day", "-p", "2", "./...")
+	cmd.Dir = src
+	out, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("stringer succeeded or did not run: %v\n%s", err, out)
+	}
+	want := "stringer: b: no values defined for type Day\n" +
+		"stringer: c: no values defined for type Day\n" +
+		"stringer: 2 of 4 packages failed\n"
+	if string(out) != want {
+		t.Errorf("reported %q, want %q", out, want)
+	}
+	for name, want := range map[string]bool{
+		"a/day_string.go": true,
+		"b/day_string.go": false,
+		"c/day_string.go": false,
+		"d/day_string.go": true,
+	} {
+		_, err := os.Stat(filepath.Join(src, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists is %t, want %t", name, got, want)
+		}
+	}
+}
+
+// TestJSONErrors runs stringer -json-errors on packages that fail in
+// different ways, and checks the errors printed and the exit status.
+func TestJSONErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"a/a.go": "package a\n\ntype Day int\n\nconst Monday Day = 0\n",
+		"b/b.go": "package b\n\ntype Day int\n",
+		"c/c.go": "package c\n\ntype Day int(\n",
+	}
+	src := filepath.Join(dir, "src")
+	writeFiles(t, src, files)
+	tests := []struct {
+		args   []string
+		status int
+		errors string
+	}{
+		{
+			[]string{"-type", "Day", "./..."}, 5,
+			`{"kind":"novalues","dir":"b","file":"b/b.go","line":3,"column":6,"message":"no values defined for type Day"}` + "\n" +
+				`{"kind":"file","dir":"c","file":"c/c.go","line":3,"column":13,"message":"parsing package: c/c.go: c/c.go:3:13: expected ';', found '('"}` + "\n",
+		},
+		{
+			[]string{"-type", "Day", "c"}, 3,
+			`{"kind":"file","file":"c/c.go","line":3,"column":13,"message":"parsing package: c/c.go: c/c.go:3:13: expected ';', found '('"}` + "\n",
+		},
+		{
+			[]string{"-type", "Night", "a"}, 4,
+			`{"kind":"typenotfound","message":"no type Night in package a"}` + "\n",
+		},
+	}
+	for _, test := range tests {
+		args := append([]string{"-json-errors"}, test.args...)
+		cmd := exec.Command(stringerPath, args...)
+		cmd.Dir = src
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		e, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Errorf("%s: stringer succeeded or did not run: %v", args, err)
+			continue
+		}
+		if e.ExitCode() != test.status {
+			t.Errorf("%s: exit status %d, want %d", args, e.ExitCode(), test.status)
+		}
+		if got := stderr.String(); got != test.errors {
+			t.Errorf("%s: printed\n%s\nwant\n%s", args, got, test.errors)
+		}
+	}
+}
+
+// TestAll runs stringer -all twice on a tree in which some types are marked,
+// and checks that the files are written and reported only the first time.
+func TestAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"a/a.go": `package a
+
+// Day is a day of the week.
+//stringer:generate
+type Day int
+
+const (
+	Monday Day = iota
+	Tuesday
+)
+
+type (
+	//stringer:generate
+	Color int
+	Size  int
+)
+
+const (
+	Red Color = iota
+	Small Size = iota
+)
+`,
+		"b/b.go": "package b\n\ntype Day int\n\nconst Monday Day = 0\n",
+	}
+	src := filepath.Join(dir, "src")
+	writeFiles(t, src, files)
+	for _, want := range []string{filepath.Join("a", "day_string.go") + "\n", ""} {
+		cmd := exec.Command(stringerPath, "-all")
+		cmd.Dir = src
+		cmd.Stderr = os.Stderr
+		got, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("reported %q, want %q", got, want)
+		}
+	}
+	code, err := ioutil.ReadFile(filepath.Join(src, "a", "day_string.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Day", "Color", "Size"} {
+		if got, want := bytes.Contains(code, []byte("func (i "+name+") String")), name != "Size"; got != want {
+			t.Errorf("String method for %s generated: %t, want %t", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(src, "b", "day_string.go")); err == nil {
+		t.Errorf("unmarked type Day in b was generated")
+	}
+}
+
+// TestWatch runs stringer -watch and checks that it generates the code again
+// when a constant is added.
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "day")
+	if err := os.Mkdir(src, 0777); err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(src, "day.go")
+	day := "package day\n\ntype Day int\n\nconst (\n\tMonday Day = iota\n\tTuesday\n"
+	if err := ioutil.WriteFile(source, []byte(day+")\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(stringerPath, "-type", "Day", "-watch", src)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	// waitFor waits for the generated file to contain the text.
+	waitFor := func(text string) {
+		for start := time.Now(); time.Since(start) < 20*time.Second; time.Sleep(100 * time.Millisecond) {
+			code, _ := ioutil.ReadFile(filepath.Join(src, "day_string.go"))
+			if strings.Contains(string(code), text) {
+				return
+			}
+		}
+		t.Fatalf("generated code does not contain %q", text)
+	}
+	waitFor("MondayTuesday")
+	if err := ioutil.WriteFile(source, []byte(day+"\tWednesday\n)\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("MondayTuesdayWednesday")
+}
+
+// TestCheck checks that stringer -check accepts generated code that is up to
+// date, even with a different command line, and reports code that is not.
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "day.go")
+	day := "package day\n\ntype Day int\n\nconst (\n\tMonday Day = iota\n\tTuesday\n"
+	if err := ioutil.WriteFile(source, []byte(day+")\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(stringerPath, "-type", "Day", dir); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(stringerPath, "-check", "-type=Day", dir).Output()
+	if err != nil || len(out) > 0 {
+		t.Fatalf("up to date: %v\n%s", err, out)
+	}
+	if err := ioutil.WriteFile(source, []byte(day+"\tWednesday\n)\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	stringSource := filepath.Join(dir, "day_string.go")
+	before, err := ioutil.ReadFile(stringSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = exec.Command(stringerPath, "-check", "-type=Day", dir).Output()
+	if _, ok := err.(*exec.ExitError); !ok || !bytes.Contains(out, []byte("+const _Day_name = \"MondayTuesdayWednesday\"")) {
+		t.Errorf("out of date: %v\n%s", err, out)
+	}
+	after, err := ioutil.ReadFile(stringSource)
+	if err != nil || !bytes.Equal(after, before) {
+		t.Errorf("-check wrote %s", stringSource)
+	}
+}
+
+// TestTrimSuffix checks that stringer rejects a -trimsuffix that would leave
+// a name empty or give two values the same name.
+func TestTrimSuffix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for consts, want := range map[string]string{
+		"RedColor Hue = iota\n\tColor\n":   "the name of Color would be empty",
+		"RedColor Hue = iota\n\tRed\n":     "RedColor and Red would both be named \"Red\"",
+		"RedColor Hue = iota\n\tRed = 0\n": "",
+	} {
+		source := filepath.Join(dir, "hue.go")
+		text := "package hue\n\ntype Hue int\n\nconst (\n\t" + consts + ")\n"
+		if err := ioutil.WriteFile(source, []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command(stringerPath, "-type=Hue", "-trimsuffix=Color", "-output=-", source).CombinedOutput()
+		if want == "" {
+			if err != nil {
+				t.Errorf("%q: %v\n%s", consts, err, out)
+			}
+			continue
+		}
+		if _, ok := err.(*exec.ExitError); !ok || !bytes.Contains(out, []byte(want)) {
+			t.Errorf("%q: %v\n%s", consts, err, out)
+		}
+	}
+}
+
+// TestDuplicates checks that stringer -duplicates=error rejects constants
+// with the same value.
+func TestDuplicates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join("testdata", "number.go")
+	out, err := exec.Command(stringerPath, "-type=Number", "-duplicates=error", "-output=-", source).CombinedOutput()
+	if _, ok := err.(*exec.ExitError); !ok || !bytes.Contains(out, []byte("have the same Number value")) {
+		t.Errorf("%v\n%s", err, out)
+	}
+}
+
+// TestBounds checks that stringer -bounds rejects a type for which the package
+// declares a constant it would generate, unless stringer generated it before.
+func TestBounds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "day.go")
+	day := "package day\n\ntype Day int\n\nconst (\n\tMonday Day = iota\n\tTuesday\n)\n"
+	if err := ioutil.WriteFile(source, []byte(day), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// The second run sees the constants of the first.
+	for i := 0; i < 2; i++ {
+		if err := run(stringerPath, "-type=Day", "-bounds", dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, "day_string.go")); err != nil {
+		t.Fatal(err)
+	}
+	for decl, want := range map[string]string{
+		"const DayMax = Tuesday":           "day.go:10:7: -bounds would declare DayMax, which is already declared",
+		"func DayCount() int { return 2 }": "day.go:10:6: -bounds would declare DayCount, which is already declared",
+	} {
+		if err := ioutil.WriteFile(source, []byte(day+"\n"+decl+"\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command(stringerPath, "-type=Day", "-bounds", "-output=-", dir).CombinedOutput()
+		if _, ok := err.(*exec.ExitError); !ok || !bytes.Contains(out, []byte(want)) {
+			t.Errorf("%s: %v\n%s", decl, err, out)
+		}
+	}
+}
+
+// pluginSource is a plugin that declares a variable listing the names of the
+// constants.
+const pluginSource = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var in struct {
+		Type      string
+		Constants []struct{ String string }
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var names []string
+	for _, c := range in.Constants {
+		names = append(names, c.String)
+	}
+	fmt.Printf("import \"strings\"\n\nvar _%s_plugin = strings.Fields(%q)\n", in.Type, strings.Join(names, " "))
+}
+`
+
+// TestPlugin checks that the code printed by a plugin is added to the
+// output, along with its imports, and compiles.
+func TestPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pluginDir := filepath.Join(dir, "plugin")
+	if err := os.Mkdir(pluginDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(pluginDir, "main.go"), []byte(pluginSource), 0666); err != nil {
+		t.Fatal(err)
+	}
+	plugin := filepath.Join(dir, "plugin.exe")
+	if err := run("go", "build", "-o", plugin, filepath.Join(pluginDir, "main.go")); err != nil {
+		t.Fatalf("building plugin: %s", err)
+	}
+	source := filepath.Join(dir, "day.go")
+	if err := copy(source, filepath.Join("testdata", "day.go")); err != nil {
+		t.Fatal(err)
+	}
+	stringSource := filepath.Join(dir, "day_string.go")
+	if err := run(stringerPath, "-type=Day", "-plugin", plugin, "-output", stringSource, source); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadFile(stringSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `var _Day_plugin = strings.Fields("Monday Tuesday Wednesday Thursday Friday Saturday Sunday")`
+	if !bytes.Contains(out, []byte(want)) || !bytes.Contains(out, []byte(`"strings"`)) {
+		t.Errorf("plugin code missing:\n%s", out)
+	}
+	if err := run("go", "build", "-o", filepath.Join(dir, "day.exe"), source, stringSource); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGeneratedTests runs the tests written with -gentest for the programs in
+// testdata, with the flags each is generated with, and for Day with String
+// panicking on values with no name.
+func TestGeneratedTests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		fileName string
+		flags    []string
+	}{{"day.go", []string{"-invalid=panic"}}}
+	for _, name := range []string{"big.go", "byte.go", "color.go", "day.go", "edge.go", "gap.go", "num.go", "perm.go", "prime.go", "rate.go", "signal.go", "small.go", "sparse.go", "tiny.go", "unum.go", "unum2.go", "wire.go"} {
+		tests = append(tests, struct {
+			fileName string
+			flags    []string
+		}{name, extraFlags[name]})
+	}
+	for _, test := range tests {
+		source := filepath.Join(dir, test.fileName)
+		if err := copy(source, filepath.Join("testdata", test.fileName)); err != nil {
+			t.Fatalf("copying file to temporary directory: %s", err)
+		}
+		typeName := fmt.Sprintf("%c%s", test.fileName[0]+'A'-'a', test.fileName[1:len(test.fileName)-len(".go")])
+		stringSource := filepath.Join(dir, typeName+"_string.go")
+		args := append([]string{"-type", typeName, "-output", stringSource, "-gentest"}, test.flags...)
+		if err := run(stringerPath, append(args, source)...); err != nil {
+			t.Fatal(err)
+		}
+		testSource := filepath.Join(dir, typeName+"_string_test.go")
+		if err := run("go", "test", stringSource, testSource, source); err != nil {
+			t.Errorf("%s %s: %s", test.fileName, strings.Join(test.flags, " "), err)
+		}
+		os.Remove(source)
+		os.Remove(stringSource)
+		os.Remove(testSource)
+	}
+}
+
+// writeFiles writes the files, named by their paths relative to dir, making
+// the directories that hold them.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, text := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
 	}
 }
 
 // stringerCompileAndRun runs stringer for the named file and compiles and
 // runs the target binary in directory dir. That binary will panic if the String method is incorrect.
-func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName string) {
+func stringerCompileAndRun(t *testing.T, dir, typeName, fileName string) {
 	t.Logf("run: %s %s\n", fileName, typeName)
 	source := filepath.Join(dir, fileName)
 	err := copy(source, filepath.Join("testdata", fileName))
@@ -74,7 +873,9 @@ func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName strin
 	}
 	stringSource := filepath.Join(dir, typeName+"_string.go")
 	// Run stringer in temporary directory.
-	err = run(stringer, "-type", typeName, "-output", stringSource, source)
+	args := []string{"-type", typeName, "-output", stringSource}
+	args = append(args, extraFlags[fileName]...)
+	err = run(stringerPath, append(args, source)...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,67 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the reporting of errors, and the -json-errors flag.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"golang.org/x/tools/stringer"
+)
+
+// exitStatus is the status with which stringer exits for each kind of error.
+var exitStatus = map[stringer.ErrorKind]int{
+	stringer.OtherError:   1,
+	stringer.FileError:    3,
+	stringer.TypeNotFound: 4,
+	stringer.NoValues:     5,
+}
+
+// A diagnostic is an error as printed by -json-errors, on a line of its own.
+type diagnostic struct {
+	Kind    string `json:"kind"`
+	Dir     string `json:"dir,omitempty"` // The package, when several are processed.
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// report prints the error, for the package in dir if that is not empty, and
+// returns the status with which stringer exits for it.
+func report(dir string, err error) int {
+	e, ok := err.(*stringer.Error)
+	if !ok {
+		e = &stringer.Error{Msg: err.Error()}
+	}
+	switch {
+	case *jsonErrors:
+		data, err := json.Marshal(diagnostic{
+			Kind:    e.Kind.String(),
+			Dir:     dir,
+			File:    e.Pos.Filename,
+			Line:    e.Pos.Line,
+			Column:  e.Pos.Column,
+			Message: e.Msg,
+		})
+		if err != nil {
+			log.Fatalf("encoding error: %s", err)
+		}
+		os.Stderr.Write(append(data, '\n'))
+	case dir != "":
+		log.Printf("%s: %s", dir, e.Msg)
+	default:
+		log.Print(e.Msg)
+	}
+	return exitStatus[e.Kind]
+}
+
+// fatal reports the error and exits with its status.
+func fatal(err error) {
+	os.Exit(report("", err))
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of arguments naming several packages, and
+// of the -all flag.
+
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/stringer"
+)
+
+// isPattern reports whether the argument is a package pattern such as ./...,
+// rather than the name of a directory or file.
+func isPattern(arg string) bool {
+	return strings.Contains(arg, "...")
+}
+
+// packageDirs returns the directories of the packages named by the
+// arguments, which are directories or patterns. As for the go command, a
+// pattern dir/... matches the packages in dir and its subdirectories, apart
+// from those named testdata or beginning with . or _.
+func (c *command) packageDirs(args []string) []string {
+	ctxt := c.opts.BuildContext()
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, arg := range args {
+		if !isPattern(arg) {
+			if !isDirectory(arg) {
+				log.Fatalf("%s is not a directory; files must be the only arguments", arg)
+			}
+			add(arg)
+			continue
+		}
+		root := strings.TrimSuffix(arg, "...")
+		if isPattern(root) || root != "" && !strings.HasSuffix(root, "/") {
+			log.Fatalf("unsupported pattern %s: ... must be the last element", arg)
+		}
+		root = filepath.Clean(root)
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if path != root && skipDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			if _, err := ctxt.ImportDir(path, 0); err == nil {
+				add(path)
+			} else if _, ok := err.(*build.NoGoError); !ok {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			fatal(&stringer.Error{Kind: stringer.FileError, Msg: fmt.Sprintf("expanding %s: %s", arg, err)})
+		}
+	}
+	return dirs
+}
+
+// skipDir reports whether a pattern, as for the go command, excludes the
+// directory with the given name and those below it.
+func skipDir(name string) bool {
+	return name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// writeMarked writes a file into each package named by the arguments that
+// declares types marked with stringer.Marker, and prints the names of the
+// files that changed.
+func (c *command) writeMarked(args []string) {
+	marked := func(pkg *stringer.Package) []string {
+		return pkg.Marked()
+	}
+	if !c.writePackages(c.packageDirs(args), marked, true) {
+		fatal(&stringer.Error{
+			Kind: stringer.TypeNotFound,
+			Msg:  fmt.Sprintf("no types marked with %s in the packages matching %s", stringer.Marker, strings.Join(args, " ")),
+		})
+	}
+}
+
+// writePackages writes a file into each package in dirs that has types to
+// generate, as selected by typesOf, running up to -p jobs at once, and prints
+// their output in the order of dirs. If list is set, it also prints the names
+// of the files that changed. A package that fails does not stop the others;
+// its error is reported for its directory, and once all are done
+// writePackages exits with the status of the first. It reports whether any
+// package had types to generate.
+func (c *command) writePackages(dirs []string, typesOf func(*stringer.Package) []string, list bool) bool {
+	jobs := make([]*job, len(dirs))
+	queue := make(chan *job, len(dirs))
+	for i, dir := range dirs {
+		jobs[i] = &job{c: c, dir: dir, done: make(chan struct{})}
+		queue <- jobs[i]
+	}
+	close(queue)
+	for i := 0; i < *parallel && i < len(jobs); i++ {
+		go func() {
+			for j := range queue {
+				j.run(typesOf, list)
+				close(j.done)
+			}
+		}()
+	}
+	found, failed, status := false, 0, 0
+	for _, j := range jobs {
+		<-j.done
+		j.flush()
+		if j.err != nil {
+			if s := report(j.dir, j.err); failed == 0 {
+				status = s
+			}
+			failed++
+		}
+		found = found || j.found
+	}
+	if failed > 0 {
+		if !*jsonErrors {
+			log.Printf("%d of %d packages failed", failed, len(jobs))
+		}
+		os.Exit(status)
+	}
+	return found
+}
+
+// run loads the package of the job and writes the code for the types that
+// typesOf selects from it, if there are any, printing the name of each file
+// if list is set and it changed.
+func (j *job) run(typesOf func(*stringer.Package) []string, list bool) {
+	j.pkg, j.err = stringer.Load(j.dir, &j.c.opts)
+	if j.err != nil {
+		return
+	}
+	types := typesOf(j.pkg)
+	if len(types) == 0 {
+		return
+	}
+	j.found = true
+	for _, fileTypes := range j.c.files(types) {
+		var name string
+		name, j.err = j.write(fileTypes, "")
+		if j.err != nil {
+			return
+		}
+		if list && name != "" {
+			fmt.Fprintln(&j.stdout, name)
+		}
+	}
+}
@@ -5,15 +5,17 @@
 // +build go1.5
 
 // Stringer is a tool to automate the creation of methods that satisfy the fmt.Stringer
-// interface. Given the name of a (signed or unsigned) integer type T that has constants
-// defined, stringer will create a new self-contained Go source file implementing
+// interface. Given the name of a type T whose underlying type is an integer, floating-point
+// or string type, and that has constants defined, stringer will create a new self-contained
+// Go source file implementing
 //	func (t T) String() string
 // The file is created in the same package and directory as the package that defines T.
 // It has helpful defaults designed for use with go generate.
 //
 // Stringer works best with constants that are consecutive values such as created using iota,
-// but creates good code regardless. In the future it might also provide custom support for
-// constant sets that are bit patterns.
+// but creates good code regardless, finding the names of powers of two by their bit number.
+// For bit flags, whose values combine several constants, the -flags flag prints the name of
+// each flag set.
 //
 // For example, given this snippet,
 //
@@ -49,14 +51,451 @@
 // If multiple constants have the same value, the lexically first matching name will
 // be used (in the example, Acetaminophen will print as "Paracetamol").
 //
+// The constants of type T are found by the type checker, so they may be spread
+// across the files of the package, take their type from a conversion such as
+// Pill(4), or be declared with an alias for T; T itself may also be an alias.
+// Their values may be computed from the constants of other packages, as in
+// Aspirin Pill = drugs.First + iota, which the type checker finds through the
+// export data built by the go command.
+//
 // With no arguments, it processes the package in the current directory.
 // Otherwise, the arguments must name a single directory holding a Go package
 // or a set of Go source files that represent a single Go package.
 //
+// The arguments may instead name several directories, or patterns such as
+// ./... that, as for the go command, match the packages in a directory and
+// its subdirectories. A file is then written into each package that declares
+// any of the types, named after the first of them, and the other packages are
+// skipped; -output does not apply.
+//
+// The -all flag replaces -type: it processes every type whose declaration is
+// marked with the comment
+//
+//	//stringer:generate
+//
+// in the packages named by the arguments, by default ./... . Each package with
+// marked types gets one file for them, named after the first, and the names
+// of the files that changed are printed, so that a single command regenerates
+// a whole tree. Files that are already up to date are left alone.
+//
+// Several packages are processed in parallel, as many at once as the -p flag
+// allows, by default the number of CPUs, and their output is printed in the
+// order of their directories. A package that fails does not stop the others:
+// the error of each is printed, prefixed by its directory, and stringer exits
+// once all are done, with the status of the first.
+//
+// The exit status tells the kind of error: 3 if a file or package could not
+// be read, parsed or type-checked, 4 if no package declares the type, 5 if
+// the type has no constants, and 1 for any other error or a failed check.
+// With the -json-errors flag, errors are printed instead as JSON objects,
+// one per line, such as
+//
+//	{"kind":"novalues","file":"day.go","line":3,"column":6,"message":"no values defined for type Day"}
+//
+// giving the kind of error as other, file, typenotfound or novalues, and its
+// position when known. When several packages are processed, a "dir" field
+// names the package of each. Errors in the flags are still printed as text.
+//
+// With the -watch flag, stringer keeps running, with the packages named by
+// the arguments loaded, and whenever one of their Go files is saved, parses
+// it again and, if the constants of the types have changed, generates the
+// code again, as if run without -watch, so that an editor can keep it up to
+// date. Errors are reported without stopping the watch.
+//
+// The -check flag writes no files. Instead it compares each with the code that
+// would be written, apart from the header recording the command line, prints
+// a diff for each that differs, and exits with status 1 if any does, so that
+// continuous integration can catch constants edited without running go
+// generate.
+//
+// The -exhaustive flag also writes no files. Instead it reports each switch
+// statement on a value of one of the types that has no default case and does
+// not handle all the constants, as in
+//
+//	pill.go:12:2: switch on Pill has no default and misses Ibuprofen
+//
+// and exits with status 1 if there are any.
+//
+// The -gentest flag, which implies -parse, also writes a test beside each
+// file, named t_string_test.go by default, that checks that each constant's
+// name parses as a value printing the same name and that values with no name,
+// just outside the range of the constants or in a gap between them, print as
+// the fallback set by -invalid, or panic. It catches generated files edited by
+// hand and changes in the representation of names between versions of
+// stringer. It does not apply to types whose underlying type is string, or
+// with -outputpkg or -template.
+//
+// The -schema flag also writes a JSON file beside each file, named
+// t_string.json by default, holding a JSON Schema that defines each type as
+// an enumeration of the strings its String method returns, such as
+//
+//	{
+//		"$schema": "https://json-schema.org/draft/2020-12/schema",
+//		"$defs": {
+//			"Pill": {
+//				"type": "string",
+//				"enum": ["Placebo", "Aspirin", "Ibuprofen"]
+//			}
+//		}
+//	}
+//
+// so that API schemas and clients in other languages can keep up with the
+// constants. It does not apply with -template.
+//
+// The -tags flag gives a comma-separated list of build tags that, like those
+// of the go command, select the files of a directory to process.
+//
+// The -goos and -goarch flags set the target operating system and
+// architecture, which, with the suffixes of the file names and the build
+// constraints, select the files of a directory, so that constants declared
+// differently for each platform, as in package syscall, have the right
+// values. The suffix is added to the default output file, as in
+// t_string_linux.go, so that the code generated for each platform is built
+// only for it.
+//
+// In a package that uses cgo, the constants may be defined by C, as in
+// SigInt Sig = C.SIGINT. Stringer runs cgo, as a build would, to find their
+// values, which requires a C compiler.
+//
 // The -type flag accepts a comma-separated list of types so a single run can
 // generate methods for multiple types. The default output file is t_string.go,
 // where t is the lower-cased name of the first type listed. It can be overridden
-// with the -output flag.
+// with the -output flag; -output=- writes the generated code to standard output.
+// The String methods of the types in the file look up their names in one
+// string and one array of their offsets, declared at its end, so that the
+// program holds a single table for them all. Names that several types share,
+// as do parallel types such as Color and ColorFilter, are stored once when
+// they come in the same order. With the -pertype flag, each type is instead
+// written into a file of its own, t_string.go, with its own tables; -output
+// does not apply.
+//
+// The generated file begins with a line such as
+//
+//	// Code generated by "stringer -type=Pill" (stringer v0.1.0); DO NOT EDIT.
+//
+// recording the command line and the version of stringer, by which tools
+// recognize generated files. The -header flag names a file of further lines,
+// such as a license or build constraints, to follow it before the package
+// clause; they must be comments.
+//
+// The -trimprefix flag removes the given prefix from the names of the constants
+// before they are stored in the name table, so that, for instance, with
+// -trimprefix=Color the constant ColorRed prints as "Red".
+//
+// The -trimsuffix flag likewise removes the given suffix, so that with
+// -trimsuffix=Color the constant RedColor prints as "Red". A name that the
+// suffix would leave empty, or the same as that of another constant with a
+// different value, is an error.
+//
+// When constants have the same value, String prints the name of the first
+// declared. The -duplicates flag sets another policy: last, for the name of
+// the last declared, such as a canonical alias added after the others; join,
+// for all their names separated by slashes, as in "One/AnotherOne"; or error,
+// to fail when duplicates are unintended. The default is first.
+//
+// The -transform flag rewrites the names, once trimmed, in one of several
+// styles: snake (max_retries), kebab (max-retries), lower (maxretries), upper
+// (MAXRETRIES), or title (Max Retries), here for the constant MaxRetries.
+//
+// The -parse flag adds a function
+//
+//	func ParseT(s string) (T, error)
+//
+// that returns the constant whose String method returns s. For an unexported
+// type t the function is named parseT.
+//
+// A comment on a constant, or above it, of the form
+//
+//	Monday Day = iota //stringer:alias Mon
+//
+// gives it other names, separated by spaces, that ParseT accepts too, as do
+// the methods added by -text, -json, -sql, -gob and -yaml that read names.
+// With the -fold flag, ParseT also accepts names in any case, as compared by
+// strings.EqualFold. Two constants with different values may not share a
+// name, or with -fold have names differing only in case.
+//
+// The -valueof flag adds a function
+//
+//	func TValueOf(name string) (T, bool)
+//
+// that returns the constant with the given name, which is either the name
+// String prints for it or, for a constant with the same value as one declared
+// before it, its own, so that callers with their own parsing rules can look up
+// aliases too.
+//
+// The -text flag adds MarshalText and UnmarshalText methods, so that T
+// implements encoding.TextMarshaler and encoding.TextUnmarshaler and its
+// values are encoded by name in JSON, XML and the like. UnmarshalText
+// reports an error for names that are not those of constants of type T.
+//
+// By default MarshalText writes what String prints for any value, so that a
+// value with no name is written as T(5), say, which UnmarshalText rejects.
+// With -textinvalid=error, MarshalText instead reports an error for such a
+// value, and with -textinvalid=name:Unknown it writes Unknown, which
+// UnmarshalText reads back if it is the name of a constant.
+//
+// The -json flag adds MarshalJSON and UnmarshalJSON methods that encode
+// values as their names. UnmarshalJSON also accepts the numeric value of a
+// constant, and rejects other names and numbers with a *json.UnmarshalTypeError.
+//
+// The -sql flag adds Scan and Value methods, so that T implements sql.Scanner
+// and driver.Valuer and is stored in databases by name.
+//
+// The -values flag adds a function TValues returning the constants of type T
+// in the order they are declared, omitting duplicate values. Similarly, the
+// -strings flag adds a function TStrings returning their names.
+//
+// The -description flag adds a method
+//
+//	func (t T) Description() string
+//
+// returning the doc comment of the constant with the value of t, with its
+// lines joined, so that help text and error messages can reuse the comments.
+//
+// The -localize flag adds methods
+//
+//	func (t T) MessageKey() string
+//	func (t T) Localized(lang string) string
+//
+// and a variable LocalizeT, a hook that the program sets to translate the
+// names for end users, for instance using a golang.org/x/text/message catalog,
+// while String remains canonical for logs. MessageKey returns the key of the
+// name, T.Name, and Localized passes it to the hook along with a BCP 47
+// language tag, falling back to String. The generated code does not import
+// any translation package.
+//
+// The -ordinal flag adds a method
+//
+//	func (t T) Ordinal() int
+//
+// returning the position of t among the distinct values of the constants, in
+// the order they are declared, so that values that are sparse codes can index
+// a dense array.
+//
+// The -iter flag adds methods
+//
+//	func (t T) Next() (T, bool)
+//	func (t T) Prev() (T, bool)
+//
+// stepping through the values of the constants in increasing order, skipping
+// the values in the gaps between them, and constants FirstT and LastT where
+// such loops begin.
+//
+// The -bounds flag adds constants TMin and TMax, the least and greatest
+// values of the constants, and an untyped TCount, the number of distinct
+// values, so that, for instance, an array declared as [TCount]E keeps its size
+// as constants are added. TCount is TMax-TMin+1 only if the values are
+// consecutive. It is an error for the package to declare any of the three
+// names itself, other than in code generated by stringer.
+//
+// The -gob flag adds GobEncode and GobDecode methods, so that T implements
+// gob.GobEncoder and gob.GobDecoder and is encoded by name, and streams
+// remain readable if the constants are renumbered.
+//
+// The -binary flag adds MarshalBinary and UnmarshalBinary methods, so that
+// T implements encoding.BinaryMarshaler and encoding.BinaryUnmarshaler. A
+// value is encoded big-endian in as many bytes as its type has, 8 for int
+// and uint, and decoding rejects values that are not those of constants.
+//
+// The -yaml flag adds MarshalYAML and UnmarshalYAML methods with the
+// signatures that YAML packages such as gopkg.in/yaml.v2 look for, encoding
+// T by name, without making the generated code depend on any of them.
+//
+// The -proto flag adds maps in the style of the code generated for protocol
+// buffer enums, so that T can be used where such an enum is expected:
+//
+//	var T_name = map[int32]string{...}
+//	var T_value = map[string]int32{...}
+//
+// The constants must have values that fit in an int32.
+//
+// The -isvalid flag adds a method
+//
+//	func (t T) IsValid() bool
+//
+// reporting whether t is the value of one of the constants. The methods added
+// by -json, -sql, -gob, -binary, -yaml and -slog use IsValid, so those flags
+// imply -isvalid.
+//
+// The -outputpkg flag writes the code into another package, with the given
+// name, that imports the package of T; the file is named by -output. Since
+// methods must be declared in the package of their type, only the functions
+// added by -parse, -values and -strings, and the constants added by -bounds,
+// are generated, so that, for
+// instance, an exported package can offer helpers for a type declared in an
+// internal one.
+//
+// By default String finds the name of a value by indexing a table if the
+// values form a few runs of consecutive numbers, and with a map otherwise.
+// The -threshold flag sets the number of runs, by default 10, beyond which
+// the map is used, and -lookup=switch or -lookup=map chooses one or the
+// other outright. With -lookup=binarysearch, String instead searches a
+// sorted array of the values, which, unlike a map, needs no allocation when
+// the program starts and takes less space for large sets of constants.
+// Constants whose values are powers of two, as declared with 1 << iota, in
+// more than one run are instead found by -lookup=bits, the default for them,
+// in a table indexed by the number of the bit set.
+//
+// The -prefixes flag shrinks the names stored for String when many share a
+// long prefix, such as EventUserCreated and EventUserDeleted: each prefix
+// ending at the start of a word is stored once, and String joins it to the
+// rest of the name, at the cost of building the string at each call. It
+// applies only when String indexes tables of consecutive values; names that
+// share no prefix, or too little of one to be worth it, are stored whole.
+//
+// The -blocks flag keeps the structure of a package that declares its
+// constants in several const blocks, such as the client and server errors of
+// a protocol: the names of each block are stored in tables of their own, and
+// no run of consecutive values spans two blocks. Like -prefixes, it applies
+// only when String indexes tables of consecutive values, and the types of a
+// file no longer share their tables. The -group flag adds a method
+//
+//	func (t T) Group() string
+//
+// returning the name of the block declaring the constant with the value of
+// t, given by a comment above the block such as
+//
+//	//stringer:group client
+//
+// or "" for a block with none and for values with no name.
+//
+// The -nofmt flag keeps the generated code from importing fmt, which is
+// large for small programs: the fallback for values with no name and the
+// errors are built with strconv and errors instead. It cannot be combined
+// with -json, -sql, -format or an -invalid format.
+//
+// The -template flag names a file holding a text/template that replaces the
+// built-in generator. It is executed for each type with data of this form:
+//
+//	struct {
+//		TypeName string    // The name of the type.
+//		Package  string    // The name of the package.
+//		Values   []Value   // The constants in declaration order, without duplicates.
+//		Runs     [][]Value // The constants sorted, in runs of consecutive values.
+//	}
+//
+// where each Value has fields Name, the printed name; OriginalName, the name
+// of the constant; and Value, the value as a Go literal. The template may call
+// the function import with the path of a package the generated code uses,
+// and quote to produce a string literal. The output is formatted with gofmt.
+//
+// The -plugin flag adds the code printed by other programs, named in a
+// comma-separated list and looked up in PATH unless they are paths, after the
+// code for each type. Each plugin is run with a description of the type as
+// JSON on its standard input, of this form:
+//
+//	{
+//		"package": "painkiller",
+//		"type": "Pill",
+//		"constants": [
+//			{"name": "Placebo", "string": "Placebo", "value": "0", "doc": "..."},
+//			...
+//		]
+//	}
+//
+// listing the constants in declaration order, each with the name String
+// prints for it and its value as a Go literal. It prints Go declarations,
+// which may begin with imports, to its standard output, and reports failure
+// with a non-zero exit status. Plugins do not apply with -template or to types
+// whose underlying type is string.
+//
+// The -gostring flag adds a GoString method, so that %#v prints a constant as
+// Go syntax qualified by the package name, such as painkiller.Aspirin, and
+// other values as painkiller.Pill(7). If String prints the constants' names,
+// GoString shares its name table and uses IsValid, which it then implies.
+//
+// The -format flag, which implies -gostring, adds a Format method, so that T
+// implements fmt.Formatter and prints consistently whatever the verb: %v and
+// %s print the name, %q the name quoted, %#v what GoString returns, and the
+// other verbs, such as %d, %x and %08b, the number. Width, precision and flags
+// apply to each, so that %-10v pads the name on the right. The generated code
+// requires Go 1.20.
+//
+// The -slog flag adds a LogValue method, so that T implements slog.LogValuer
+// and log/slog logs a constant by name, without formatting it through fmt.
+// A value with no name is logged as a group of what String prints for it and
+// its number, as in {"name":"Pill(7)","code":7} in JSON. The method uses
+// IsValid, which the flag implies; the generated code requires Go 1.21.
+//
+// The -error flag adds an Error method returning what String returns, so
+// that T implements the error interface and its constants, such as the codes
+// of a protocol, can be returned as errors. With -errorprefix the name
+// follows the name of the type and a colon, as in "Status: NotFound". The
+// -erroronly flag instead names the String method Error, so that T has no
+// String method; fmt prints the values through Error all the same. It does
+// not apply with -errorprefix, as the methods that write names, such as
+// MarshalText, would write the prefix too, nor with -template, -plugin or
+// -gentest, whose code calls String.
+//
+// The -method flag names the generated String method otherwise, so that a
+// type with a String method of its own, written by hand, may have the names
+// of its constants too, as in
+//
+//	//go:generate stringer -type=Pill -method=Label
+//
+// which generates
+//
+//	func (Pill) Label() string
+//
+// The other generated methods, such as MarshalText and GoString, call Label
+// where they would call String, so that they write the names. fmt prints the
+// values with the handwritten String, unless -error or -format adds a method
+// that fmt prefers. The flag does not apply with -erroronly, nor with
+// -template, -plugin or -gentest, whose code calls String.
+//
+// With the -ptr flag, String and the other generated methods that take a T
+// take a *T instead, for types whose values are used by pointer. On a nil
+// pointer String returns "<nil>", GoString returns "nil" and the other methods
+// return zero values. Since fmt calls String only when the value it is given
+// has it, values of T must then be printed through pointers, as in
+// fmt.Print(&day).
+//
+// With the -flags flag, the constants are taken to be bit flags, as
+// declared with 1 << iota, and String lists the names of the flags set in a
+// value separated by vertical bars, as in "Read|Write", followed by the
+// numeric fallback for any bits that are not named. Constants with more than
+// one bit set, such as combinations of other flags, are ignored, but a
+// constant whose value is zero names the empty set of flags.
+//
+// The -invalid flag sets the string printed for a value with no name, by
+// default T(%d). It is either a format to which the value is applied, as in
+// -invalid="unknown day %d"; a plain string; the word empty, for the empty
+// string; or the word panic, for String to panic instead.
+//
+// For a type whose underlying type is rune or byte, such as the tokens of a
+// scanner declared with type Token rune, the -char flag prints values as
+// quoted characters, as a Go rune literal such as '/' is written. With
+// -char=fallback, values with no name print so in place of Token(47); with
+// -char=all, the constants do too, in place of their names, unless they are
+// not characters, such as an EOF of -1. The flag may not be combined with
+// -invalid or -flags.
+//
+// Constants of floating-point types, such as a set of sample rates declared
+// with type Rate float64, are also handled. Their names are found with an
+// exact match in a map, and values with no name print as Rate(%g). The -json,
+// -sql and -flags flags do not apply to them.
+//
+// The named type may also have string as its underlying type, as in
+//
+//	type State string
+//
+// Its String method then returns the value itself, and the names of the
+// constants, along with the -trimprefix, -trimsuffix, -transform, -linecomment
+// and -invalid flags, have no effect. The -parse flag, which implies -isvalid
+// for such types, and -values and -strings work as for integer types; -text,
+// -json, -sql, -bounds, -description, -localize, -ordinal, -valueof, -iter,
+// -gentest, -char, -plugin and -flags are rejected, as strings need no help
+// being encoded.
+//
+// With the -linecomment flag, a constant followed by a line comment, as in
+//
+//	StatusNotFound Status = 404 // Not Found
+//
+// prints as the text of the comment, "Not Found", rather than its name.
+// Constants without a line comment are unaffected.
+//
+// The code is generated by the package golang.org/x/tools/stringer, which
+// programs may import to generate it themselves.
 //
 package main // import "golang.org/x/tools/cmd/stringer"
 
@@ -64,575 +503,503 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/build"
-	exact "go/constant"
-	"go/format"
-	"go/importer"
-	"go/parser"
 	"go/token"
-	"go/types"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
 	"strings"
+
+	"golang.org/x/tools/stringer"
 )
 
 var (
-	typeNames = flag.String("type", "", "comma-separated list of type names; must be set")
-	output    = flag.String("output", "", "output file name; default srcdir/<type>_string.go")
+	typeNames   = flag.String("type", "", "comma-separated list of type names; must be set unless -all is")
+	checkFlag   = flag.Bool("check", false, "write no files, but print a diff and exit with status 1 if any is out of date")
+	exhaustive  = flag.Bool("exhaustive", false, "write no files, but report the switch statements on the types that have no default and miss constants")
+	watchFlag   = flag.Bool("watch", false, "keep running, and generate the code again whenever a Go file of the packages changes")
+	allFlag     = flag.Bool("all", false, "generate the code for the types marked with //stringer:generate in the packages, by default ./...")
+	output      = flag.String("output", "", "output file name, or - for standard output; default srcdir/<type>_string.go")
+	trimprefix  = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
+	duplicates  = flag.String("duplicates", "first", "which name String prints for constants with the same value: first, last, join or error")
+	trimsuffix  = flag.String("trimsuffix", "", "trim the `suffix` from the generated constant names")
+	linecomment = flag.Bool("linecomment", false, "use line comment text as printed text when present")
+	parse       = flag.Bool("parse", false, "also generate a ParseT function inverting the String method")
+	fold        = flag.Bool("fold", false, "make ParseT accept names in any case")
+	text        = flag.Bool("text", false, "also generate MarshalText and UnmarshalText methods")
+	textInvalid = flag.String("textinvalid", "fallback", "what MarshalText does with values with no name: fallback, to what String prints, error, or name:`text`")
+	jsonFlag    = flag.Bool("json", false, "also generate MarshalJSON and UnmarshalJSON methods")
+	sqlFlag     = flag.Bool("sql", false, "also generate Scan and Value methods for database/sql")
+	gob         = flag.Bool("gob", false, "also generate GobEncode and GobDecode methods")
+	binaryFlag  = flag.Bool("binary", false, "also generate fixed-width MarshalBinary and UnmarshalBinary methods")
+	yaml        = flag.Bool("yaml", false, "also generate MarshalYAML and UnmarshalYAML methods")
+	proto       = flag.Bool("proto", false, "also generate T_name and T_value maps in the style of protocol buffer enums")
+	valueOf     = flag.Bool("valueof", false, "also generate a TValueOf function looking up the constants by name, including aliases")
+	valuesFlag  = flag.Bool("values", false, "also generate a TValues function listing the constants")
+	bounds      = flag.Bool("bounds", false, "also generate TMin, TMax and TCount constants describing the values")
+	description = flag.Bool("description", false, "also generate a Description method returning the doc comment of each constant")
+	localize    = flag.Bool("localize", false, "also generate MessageKey and Localized methods, translating names through a LocalizeT hook")
+	ordinal     = flag.Bool("ordinal", false, "also generate an Ordinal method returning the position of each constant in declaration order")
+	iter        = flag.Bool("iter", false, "also generate Next and Prev methods and FirstT and LastT constants to step through the values")
+	stringsFlag = flag.Bool("strings", false, "also generate a TStrings function listing the constants' names")
+	schema      = flag.Bool("schema", false, "also write a .json file beside the code holding a JSON Schema that lists the names of each type")
+	gentest     = flag.Bool("gentest", false, "also write a _test.go file checking that the names parse and that values with no name print as the fallback; implies -parse")
+	isValid     = flag.Bool("isvalid", false, "also generate an IsValid method")
+	goString    = flag.Bool("gostring", false, "also generate a GoString method printing the constants as Go syntax")
+	method      = flag.String("method", "String", "`name` of the generated method returning the names, for types that have a String method of their own")
+	formatFlag  = flag.Bool("format", false, "also generate a Format method printing the name for %v and %s and the number for %d; implies -gostring")
+	slogFlag    = flag.Bool("slog", false, "also generate a LogValue method logging the names with log/slog; implies -isvalid")
+	errorFlag   = flag.Bool("error", false, "also generate an Error method returning the name, so that the constants are errors")
+	errorPrefix = flag.Bool("errorprefix", false, "prefix the name returned by Error with the type name and a colon")
+	errorOnly   = flag.Bool("erroronly", false, "name the String method Error, rather than adding one; implies -error")
+	ptr         = flag.Bool("ptr", false, "give the generated methods pointer receivers, handling nil")
+	flags       = flag.Bool("flags", false, "treat the constants as bit flags that may be combined")
+	transform   = flag.String("transform", "", "rewrite the constant names in the given `style`: snake, kebab, lower, upper or title")
+	noFmt       = flag.Bool("nofmt", false, "generate code that does not import fmt")
+	buildTags   = flag.String("tags", "", "comma-separated list of build tags to apply")
+	goos        = flag.String("goos", "", "target `os` selecting the files of a directory; also added to the default output file name")
+	goarch      = flag.String("goarch", "", "target `arch` selecting the files of a directory; also added to the default output file name")
+	lookup      = flag.String("lookup", "auto", "how String finds the name of a value: auto, switch, map, binarysearch or bits")
+	prefixes    = flag.Bool("prefixes", false, "store the prefixes shared by the names once each, joining them to the rest of the name in String")
+	blocks      = flag.Bool("blocks", false, "store the names of the constants of each const block in tables of their own")
+	groupFlag   = flag.Bool("group", false, "also generate a Group method returning the name given to the const block of a value by a //stringer:group comment")
+	threshold   = flag.Int("threshold", stringer.DefaultThreshold, "with -lookup=auto, the most runs of consecutive values for which String uses a switch rather than a map")
+	headerFile  = flag.String("header", "", "add the comment lines in `file`, such as a license or build constraints, to the header of the generated files")
+	plugins     = flag.String("plugin", "", "comma-separated list of programs that print more code for each type, given the constants as JSON")
+	tmplFile    = flag.String("template", "", "generate the code for each type by executing the text/template in `file`")
+	outputPkg   = flag.String("outputpkg", "", "write the -parse, -values, -strings and -bounds helpers into the package with this `name`; requires -output")
+	char        = flag.String("char", "", "print values of rune or byte types as quoted characters: fallback, for those with no name, or all")
+	invalid     = flag.String("invalid", "", "`format` of the string for values with no name, applied to the value; or empty, or panic; default T(%d)")
+	parallel    = flag.Int("p", runtime.GOMAXPROCS(0), "the `number` of packages to process in parallel")
+	perType     = flag.Bool("pertype", false, "write each type into a file of its own, with its own tables of names")
+	jsonErrors  = flag.Bool("json-errors", false, "print errors as JSON objects, one per line, giving their kind and position")
 )
 
 // Usage is a replacement usage function for the flags package.
 func Usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "\tstringer [flags] -type T [directory]\n")
-	fmt.Fprintf(os.Stderr, "\tstringer [flags] -type T files... # Must be a single package\n")
+	fmt.Fprintf(os.Stderr, "\tstringer [flags] -type T[,T...] [directory]\n")
+	fmt.Fprintf(os.Stderr, "\tstringer [flags] -type T[,T...] directories|patterns... # For example, ./...\n")
+	fmt.Fprintf(os.Stderr, "\tstringer [flags] -type T[,T...] files... # Must be a single package\n")
+	fmt.Fprintf(os.Stderr, "\tstringer [flags] -all [directories|patterns...]\n")
 	fmt.Fprintf(os.Stderr, "For more information, see:\n")
 	fmt.Fprintf(os.Stderr, "\thttp://godoc.org/golang.org/x/tools/cmd/stringer\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 }
 
+// command holds the state of a run of stringer: the options passed to the
+// generator, and those that decide what is done with the generated code.
+type command struct {
+	opts       stringer.Options
+	check      bool // Compare the files with the generated code instead of writing them.
+	exhaustive bool // Report incomplete switch statements instead of generating code.
+	gentest    bool // Write a test beside the code.
+	perType    bool // Write each type into a file of its own.
+	schema     bool // Write a JSON Schema beside the code.
+	failed     bool // A check failed; exit with status 1.
+}
+
+// A job generates the code for one package. The jobs for several packages
+// run in parallel, so each holds what it prints until main prints it in turn.
+type job struct {
+	c      *command
+	dir    string
+	pkg    *stringer.Package
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	failed bool  // A check failed.
+	found  bool  // The package has types to generate.
+	err    error // The job failed.
+	done   chan struct{}
+}
+
+// flush prints what the job printed, and records whether a check failed.
+func (j *job) flush() {
+	if _, err := os.Stdout.Write(j.stdout.Bytes()); err != nil {
+		log.Fatalf("writing output: %s", err)
+	}
+	os.Stderr.Write(j.stderr.Bytes())
+	if j.failed {
+		j.c.failed = true
+	}
+}
+
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("stringer: ")
 	flag.Usage = Usage
 	flag.Parse()
-	if len(*typeNames) == 0 {
+	if len(*typeNames) == 0 && !*allFlag {
 		flag.Usage()
 		os.Exit(2)
 	}
+	if len(*typeNames) > 0 && *allFlag {
+		log.Fatalf("-type does not apply with -all, which finds the types by their comments")
+	}
 	types := strings.Split(*typeNames, ",")
+	transformFunc := stringer.TransformStyle(*transform)
+	if *transform != "" && transformFunc == nil {
+		log.Fatalf("unknown -transform style %q", *transform)
+	}
 
-	// We accept either one directory or a list of files. Which do we have?
+	// We accept directories and patterns, or a list of files. Which do we have?
 	args := flag.Args()
-	if len(args) == 0 {
+	if len(args) == 0 && *allFlag {
+		args = []string{"./..."}
+	} else if len(args) == 0 {
 		// Default: process whole package in current directory.
 		args = []string{"."}
 	}
 
-	// Parse the package once.
-	var (
-		dir string
-		g   Generator
-	)
-	if len(args) == 1 && isDirectory(args[0]) {
-		dir = args[0]
-		g.parsePackageDir(args[0])
-	} else {
-		dir = filepath.Dir(args[0])
-		g.parsePackageFiles(args)
+	c := command{
+		opts: stringer.Options{
+			TrimPrefix:  *trimprefix,
+			TrimSuffix:  *trimsuffix,
+			LineComment: *linecomment,
+			Transform:   transformFunc,
+			Parse:       *parse,
+			Fold:        *fold,
+			Text:        *text,
+			TextInvalid: *textInvalid,
+			JSON:        *jsonFlag,
+			SQL:         *sqlFlag,
+			Gob:         *gob,
+			Binary:      *binaryFlag,
+			YAML:        *yaml,
+			Proto:       *proto,
+			ValueOf:     *valueOf,
+			Values:      *valuesFlag,
+			Bounds:      *bounds,
+			Description: *description,
+			Localize:    *localize,
+			Ordinal:     *ordinal,
+			Iter:        *iter,
+			Strings:     *stringsFlag,
+			IsValid:     *isValid,
+			GoString:    *goString,
+			Method:      *method,
+			Slog:        *slogFlag,
+			Format:      *formatFlag,
+			Error:       *errorFlag,
+			ErrorPrefix: *errorPrefix,
+			ErrorOnly:   *errorOnly,
+			Ptr:         *ptr,
+			Flags:       *flags,
+			Invalid:     *invalid,
+			NoFmt:       *noFmt,
+			Prefixes:    *prefixes,
+			Blocks:      *blocks,
+			Group:       *groupFlag,
+			Shared:      !*perType,
+			Args:        os.Args[1:],
+		},
+	}
+	if *noFmt && (*jsonFlag || *sqlFlag) {
+		log.Fatalf("-nofmt: -json and -sql use packages that import fmt")
+	}
+	if *noFmt && *formatFlag {
+		log.Fatalf("-nofmt: -format implements fmt.Formatter")
+	}
+	if *noFmt && strings.Contains(*invalid, "%") {
+		log.Fatalf("-nofmt: -invalid format requires fmt")
+	}
+	if *errorPrefix && (!*errorFlag || *errorOnly) {
+		log.Fatalf("-errorprefix applies only with -error, not -erroronly")
+	}
+	switch *char {
+	case "":
+	case "fallback", "all":
+		if *invalid != "" {
+			log.Fatalf("-char does not apply with -invalid")
+		}
+		c.opts.Char = *char
+	default:
+		log.Fatalf("unknown -char mode %q", *char)
 	}
-
-	// Print the header and package clause.
-	g.Printf("// Code generated by \"stringer %s\"; DO NOT EDIT\n", strings.Join(os.Args[1:], " "))
-	g.Printf("\n")
-	g.Printf("package %s", g.pkg.name)
-	g.Printf("\n")
-	g.Printf("import \"fmt\"\n") // Used by all methods.
-
-	// Run generate for each type.
-	for _, typeName := range types {
-		g.generate(typeName)
+	if len(*buildTags) > 0 {
+		c.opts.Tags = strings.Split(*buildTags, ",")
 	}
-
-	// Format the output.
-	src := g.format()
-
-	// Write to file.
-	outputName := *output
-	if outputName == "" {
-		baseName := fmt.Sprintf("%s_string.go", types[0])
-		outputName = filepath.Join(dir, strings.ToLower(baseName))
+	if len(*plugins) > 0 {
+		if *tmplFile != "" {
+			log.Fatalf("-plugin does not apply with -template")
+		}
+		c.opts.Plugins = strings.Split(*plugins, ",")
 	}
-	err := ioutil.WriteFile(outputName, src, 0644)
-	if err != nil {
-		log.Fatalf("writing output: %s", err)
+	c.opts.GOOS = *goos
+	c.opts.GOARCH = *goarch
+	if *outputPkg != "" {
+		if *output == "" {
+			log.Fatalf("-outputpkg requires -output")
+		}
+		c.opts.OutputPkg = *outputPkg
 	}
-}
-
-// isDirectory reports whether the named file is a directory.
-func isDirectory(name string) bool {
-	info, err := os.Stat(name)
-	if err != nil {
-		log.Fatal(err)
+	if *headerFile != "" {
+		c.opts.Header = readHeader(*headerFile)
 	}
-	return info.IsDir()
-}
-
-// Generator holds the state of the analysis. Primarily used to buffer
-// the output for format.Source.
-type Generator struct {
-	buf bytes.Buffer // Accumulated output.
-	pkg *Package     // Package we are scanning.
-}
-
-func (g *Generator) Printf(format string, args ...interface{}) {
-	fmt.Fprintf(&g.buf, format, args...)
-}
-
-// File holds a single parsed file and associated data.
-type File struct {
-	pkg  *Package  // Package to which this file belongs.
-	file *ast.File // Parsed AST.
-	// These fields are reset for each type being generated.
-	typeName string  // Name of the constant type.
-	values   []Value // Accumulator for constant values of that type.
-}
-
-type Package struct {
-	dir      string
-	name     string
-	defs     map[*ast.Ident]types.Object
-	files    []*File
-	typesPkg *types.Package
-}
-
-// parsePackageDir parses the package residing in the directory.
-func (g *Generator) parsePackageDir(directory string) {
-	pkg, err := build.Default.ImportDir(directory, 0)
-	if err != nil {
-		log.Fatalf("cannot process directory %s: %s", directory, err)
-	}
-	var names []string
-	names = append(names, pkg.GoFiles...)
-	names = append(names, pkg.CgoFiles...)
-	// TODO: Need to think about constants in test files. Maybe write type_string_test.go
-	// in a separate pass? For later.
-	// names = append(names, pkg.TestGoFiles...) // These are also in the "foo" package.
-	names = append(names, pkg.SFiles...)
-	names = prefixDirectory(directory, names)
-	g.parsePackage(directory, names, nil)
-}
-
-// parsePackageFiles parses the package occupying the named files.
-func (g *Generator) parsePackageFiles(names []string) {
-	g.parsePackage(".", names, nil)
-}
-
-// prefixDirectory places the directory name on the beginning of each name in the list.
-func prefixDirectory(directory string, names []string) []string {
-	if directory == "." {
-		return names
+	if *tmplFile != "" {
+		tmpl, err := stringer.ParseTemplate(*tmplFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c.opts.Template = tmpl
 	}
-	ret := make([]string, len(names))
-	for i, name := range names {
-		ret[i] = filepath.Join(directory, name)
+	switch *lookup {
+	case "auto":
+	case "switch", "map", "binarysearch", "bits":
+		c.opts.Lookup = *lookup
+	default:
+		log.Fatalf("unknown -lookup method %q", *lookup)
 	}
-	return ret
-}
-
-// parsePackage analyzes the single package constructed from the named files.
-// If text is non-nil, it is a string to be used instead of the content of the file,
-// to be used for testing. parsePackage exits if there is an error.
-func (g *Generator) parsePackage(directory string, names []string, text interface{}) {
-	var files []*File
-	var astFiles []*ast.File
-	g.pkg = new(Package)
-	fs := token.NewFileSet()
-	for _, name := range names {
-		if !strings.HasSuffix(name, ".go") {
-			continue
+	if *fold && !*parse && !*gentest {
+		log.Fatalf("-fold applies only with -parse")
+	}
+	if *textInvalid != "fallback" {
+		if !*text {
+			log.Fatalf("-textinvalid applies only with -text")
 		}
-		parsedFile, err := parser.ParseFile(fs, name, text, 0)
-		if err != nil {
-			log.Fatalf("parsing package: %s: %s", name, err)
+		if *textInvalid != "error" && !strings.HasPrefix(*textInvalid, "name:") {
+			log.Fatalf("unknown -textinvalid policy %q", *textInvalid)
 		}
-		astFiles = append(astFiles, parsedFile)
-		files = append(files, &File{
-			file: parsedFile,
-			pkg:  g.pkg,
-		})
-	}
-	if len(astFiles) == 0 {
-		log.Fatalf("%s: no buildable Go files", directory)
-	}
-	g.pkg.name = astFiles[0].Name.Name
-	g.pkg.files = files
-	g.pkg.dir = directory
-	// Type check the package.
-	g.pkg.check(fs, astFiles)
-}
-
-// check type-checks the package. The package must be OK to proceed.
-func (pkg *Package) check(fs *token.FileSet, astFiles []*ast.File) {
-	pkg.defs = make(map[*ast.Ident]types.Object)
-	config := types.Config{Importer: importer.Default(), FakeImportC: true}
-	info := &types.Info{
-		Defs: pkg.defs,
-	}
-	typesPkg, err := config.Check(pkg.dir, fs, astFiles, info)
-	if err != nil {
-		log.Fatalf("checking package: %s", err)
 	}
-	pkg.typesPkg = typesPkg
-}
-
-// generate produces the String method for the named type.
-func (g *Generator) generate(typeName string) {
-	values := make([]Value, 0, 100)
-	for _, file := range g.pkg.files {
-		// Set the state for this run of the walker.
-		file.typeName = typeName
-		file.values = nil
-		if file.file != nil {
-			ast.Inspect(file.file, file.genDecl)
-			values = append(values, file.values...)
+	switch *duplicates {
+	case "first", "last", "join", "error":
+		c.opts.Duplicates = *duplicates
+	default:
+		log.Fatalf("unknown -duplicates policy %q", *duplicates)
+	}
+	if *threshold < 1 {
+		log.Fatalf("-threshold must be at least 1")
+	}
+	if *parallel < 1 {
+		log.Fatalf("-p must be at least 1")
+	}
+	c.opts.Threshold = *threshold
+	if *checkFlag {
+		if *output == "-" {
+			log.Fatalf("-check does not apply to standard output")
 		}
+		c.check = true
 	}
-
-	if len(values) == 0 {
-		log.Fatalf("no values defined for type %s", typeName)
-	}
-	runs := splitIntoRuns(values)
-	// The decision of which pattern to use depends on the number of
-	// runs in the numbers. If there's only one, it's easy. For more than
-	// one, there's a tradeoff between complexity and size of the data
-	// and code vs. the simplicity of a map. A map takes more space,
-	// but so does the code. The decision here (crossover at 10) is
-	// arbitrary, but considers that for large numbers of runs the cost
-	// of the linear scan in the switch might become important, and
-	// rather than use yet another algorithm such as binary search,
-	// we punt and use a map. In any case, the likelihood of a map
-	// being necessary for any realistic example other than bitmasks
-	// is very low. And bitmasks probably deserve their own analysis,
-	// to be done some other day.
-	switch {
-	case len(runs) == 1:
-		g.buildOneRun(runs, typeName)
-	case len(runs) <= 10:
-		g.buildMultipleRuns(runs, typeName)
-	default:
-		g.buildMap(runs, typeName)
+	c.exhaustive = *exhaustive
+	if *perType {
+		if *output != "" {
+			log.Fatalf("-output does not apply with -pertype")
+		}
+		c.perType = true
 	}
-}
-
-// splitIntoRuns breaks the values into runs of contiguous sequences.
-// For example, given 1,2,3,5,6,7 it returns {1,2,3},{5,6,7}.
-// The input slice is known to be non-empty.
-func splitIntoRuns(values []Value) [][]Value {
-	// We use stable sort so the lexically first name is chosen for equal elements.
-	sort.Stable(byValue(values))
-	// Remove duplicates. Stable sort has put the one we want to print first,
-	// so use that one. The String method won't care about which named constant
-	// was the argument, so the first name for the given value is the only one to keep.
-	// We need to do this because identical values would cause the switch or map
-	// to fail to compile.
-	j := 1
-	for i := 1; i < len(values); i++ {
-		if values[i].value != values[i-1].value {
-			values[j] = values[i]
-			j++
+	if *schema {
+		if *output == "-" {
+			log.Fatalf("-schema does not apply to standard output")
+		}
+		if *tmplFile != "" {
+			log.Fatalf("-schema does not apply with -template")
 		}
+		c.schema = true
+	}
+	if *gentest {
+		if *output == "-" {
+			log.Fatalf("-gentest does not apply to standard output")
+		}
+		if *tmplFile != "" || *outputPkg != "" {
+			log.Fatalf("-gentest does not apply with -template or -outputpkg")
+		}
+		c.gentest = true
+		c.opts.Parse = true
+	}
+	if *watchFlag {
+		// The header records the command line as if run without -watch.
+		c.opts.Args = withoutWatch(c.opts.Args)
+		c.watch(args, types, *output)
 	}
-	values = values[:j]
-	runs := make([][]Value, 0, 10)
-	for len(values) > 0 {
-		// One contiguous sequence per outer loop.
-		i := 1
-		for i < len(values) && values[i].value == values[i-1].value+1 {
-			i++
+	switch {
+	case *allFlag:
+		if *output != "" {
+			log.Fatalf("-output does not apply with -all")
+		}
+		c.writeMarked(args)
+	case len(args) == 1 && !isPattern(args[0]) && isDirectory(args[0]):
+		pkg, err := stringer.Load(args[0], &c.opts)
+		if err != nil {
+			fatal(err)
+		}
+		c.writeOne(&job{c: &c, dir: args[0], pkg: pkg}, types, *output)
+	case !isPattern(args[0]) && !isDirectory(args[0]):
+		pkg, err := stringer.LoadFiles(args, &c.opts)
+		if err != nil {
+			fatal(err)
+		}
+		c.writeOne(&job{c: &c, dir: filepath.Dir(args[0]), pkg: pkg}, types, *output)
+	default:
+		// Several packages: write a file into each that declares any of the
+		// types, skipping the others.
+		if *output != "" {
+			log.Fatalf("-output applies only to a single package")
+		}
+		declared := func(pkg *stringer.Package) []string {
+			return pkg.Declared(types)
+		}
+		if !c.writePackages(c.packageDirs(args), declared, false) {
+			fatal(&stringer.Error{
+				Kind: stringer.TypeNotFound,
+				Msg:  fmt.Sprintf("no type %s in the packages matching %s", strings.Join(types, ","), strings.Join(args, " ")),
+			})
 		}
-		runs = append(runs, values[:i])
-		values = values[i:]
 	}
-	return runs
+	if c.failed {
+		os.Exit(1)
+	}
 }
 
-// format returns the gofmt-ed contents of the Generator's buffer.
-func (g *Generator) format() []byte {
-	src, err := format.Source(g.buf.Bytes())
+// writeOne writes the code for the named types of the package of the job to
+// the named output file, or with -pertype to a file for each, as write does,
+// and prints the output of the job.
+func (c *command) writeOne(j *job, types []string, outputName string) {
+	var err error
+	for _, fileTypes := range c.files(types) {
+		if _, err = j.write(fileTypes, outputName); err != nil {
+			break
+		}
+	}
+	j.flush()
 	if err != nil {
-		// Should never happen, but can arise when developing this code.
-		// The user can compile the output to see the error.
-		log.Printf("warning: internal error: invalid Go generated: %s", err)
-		log.Printf("warning: compile the package to analyze the error")
-		return g.buf.Bytes()
+		fatal(err)
 	}
-	return src
-}
-
-// Value represents a declared constant.
-type Value struct {
-	name string // The name of the constant.
-	// The value is stored as a bit pattern alone. The boolean tells us
-	// whether to interpret it as an int64 or a uint64; the only place
-	// this matters is when sorting.
-	// Much of the time the str field is all we need; it is printed
-	// by Value.String.
-	value  uint64 // Will be converted to int64 when needed.
-	signed bool   // Whether the constant is a signed type.
-	str    string // The string representation given by the "go/exact" package.
 }
 
-func (v *Value) String() string {
-	return v.str
-}
-
-// byValue lets us sort the constants into increasing order.
-// We take care in the Less method to sort in signed or unsigned order,
-// as appropriate.
-type byValue []Value
-
-func (b byValue) Len() int      { return len(b) }
-func (b byValue) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
-func (b byValue) Less(i, j int) bool {
-	if b[i].signed {
-		return int64(b[i].value) < int64(b[j].value)
+// files returns the lists of the named types whose code is written into each
+// file: one list of them all, or, with -pertype, one for each type.
+func (c *command) files(types []string) [][]string {
+	if !c.perType {
+		return [][]string{types}
 	}
-	return b[i].value < b[j].value
+	files := make([][]string, len(types))
+	for i, typeName := range types {
+		files[i] = []string{typeName}
+	}
+	return files
 }
 
-// genDecl processes one declaration clause.
-func (f *File) genDecl(node ast.Node) bool {
-	decl, ok := node.(*ast.GenDecl)
-	if !ok || decl.Tok != token.CONST {
-		// We only care about const declarations.
-		return true
-	}
-	// The name of the type of the constants we are declaring.
-	// Can change if this is a multi-element declaration.
-	typ := ""
-	// Loop over the elements of the declaration. Each element is a ValueSpec:
-	// a list of names possibly followed by a type, possibly followed by values.
-	// If the type and value are both missing, we carry down the type (and value,
-	// but the "go/types" package takes care of that).
-	for _, spec := range decl.Specs {
-		vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
-		if vspec.Type == nil && len(vspec.Values) > 0 {
-			// "X = 1". With no type but a value, the constant is untyped.
-			// Skip this vspec and reset the remembered type.
-			typ = ""
-			continue
-		}
-		if vspec.Type != nil {
-			// "X T". We have a type. Remember it.
-			ident, ok := vspec.Type.(*ast.Ident)
-			if !ok {
-				continue
-			}
-			typ = ident.Name
-		}
-		if typ != f.typeName {
-			// This is not the type we're looking for.
-			continue
-		}
-		// We now have a list of names (from one line of source code) all being
-		// declared with the desired type.
-		// Grab their names and actual values and store them in f.values.
-		for _, name := range vspec.Names {
-			if name.Name == "_" {
-				continue
+// write generates the code for the named types of the package of the job and
+// writes it to the named output file: to standard output if it is "-", and to
+// a file in the directory of the job named after the first type if it is
+// empty. A file that already holds the code is left alone, and with -check no
+// file is written. With -exhaustive, write reports the incomplete switch
+// statements on the types instead. write returns the name of the file if it
+// changed, and the empty string otherwise.
+func (j *job) write(types []string, outputName string) (string, error) {
+	c := j.c
+	if c.exhaustive {
+		for _, typeName := range types {
+			reports, err := j.pkg.IncompleteSwitches(typeName)
+			if err != nil {
+				return "", err
 			}
-			// This dance lets the type checker find the values for us. It's a
-			// bit tricky: look up the object declared by the name, find its
-			// types.Const, and extract its value.
-			obj, ok := f.pkg.defs[name]
-			if !ok {
-				log.Fatalf("no value for constant %s", name)
+			for _, report := range reports {
+				fmt.Fprintln(&j.stderr, report)
+				j.failed = true
 			}
-			info := obj.Type().Underlying().(*types.Basic).Info()
-			if info&types.IsInteger == 0 {
-				log.Fatalf("can't handle non-integer constant type %s", typ)
-			}
-			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
-			if value.Kind() != exact.Int {
-				log.Fatalf("can't happen: constant is not an integer %s", name)
-			}
-			i64, isInt := exact.Int64Val(value)
-			u64, isUint := exact.Uint64Val(value)
-			if !isInt && !isUint {
-				log.Fatalf("internal error: value of %s is not an integer: %s", name, value.String())
-			}
-			if !isInt {
-				u64 = uint64(i64)
-			}
-			v := Value{
-				name:   name.Name,
-				value:  u64,
-				signed: info&types.IsUnsigned == 0,
-				str:    value.String(),
-			}
-			f.values = append(f.values, v)
 		}
+		return "", nil
 	}
-	return false
-}
-
-// Helpers
-
-// usize returns the number of bits of the smallest unsigned integer
-// type that will hold n. Used to create the smallest possible slice of
-// integers to use as indexes into the concatenated strings.
-func usize(n int) int {
-	switch {
-	case n < 1<<8:
-		return 8
-	case n < 1<<16:
-		return 16
-	default:
-		// 2^32 is enough constants for anyone.
-		return 32
+	opts := c.opts
+	var buf, test, schema bytes.Buffer
+	if c.gentest {
+		opts.Test = &test
 	}
-}
-
-// declareIndexAndNameVars declares the index slices and concatenated names
-// strings representing the runs of values.
-func (g *Generator) declareIndexAndNameVars(runs [][]Value, typeName string) {
-	var indexes, names []string
-	for i, run := range runs {
-		index, name := g.createIndexAndNameDecl(run, typeName, fmt.Sprintf("_%d", i))
-		indexes = append(indexes, index)
-		names = append(names, name)
-	}
-	g.Printf("const (\n")
-	for _, name := range names {
-		g.Printf("\t%s\n", name)
-	}
-	g.Printf(")\n\n")
-	g.Printf("var (")
-	for _, index := range indexes {
-		g.Printf("\t%s\n", index)
-	}
-	g.Printf(")\n\n")
-}
-
-// declareIndexAndNameVar is the single-run version of declareIndexAndNameVars
-func (g *Generator) declareIndexAndNameVar(run []Value, typeName string) {
-	index, name := g.createIndexAndNameDecl(run, typeName, "")
-	g.Printf("const %s\n", name)
-	g.Printf("var %s\n", index)
-}
+	if c.schema {
+		opts.Schema = &schema
+	}
+	if err := stringer.Generate(&buf, j.pkg, types, &opts); err != nil {
+		return "", err
+	}
+	src := buf.Bytes()
 
-// createIndexAndNameDecl returns the pair of declarations for the run. The caller will add "const" and "var".
-func (g *Generator) createIndexAndNameDecl(run []Value, typeName string, suffix string) (string, string) {
-	b := new(bytes.Buffer)
-	indexes := make([]int, len(run))
-	for i := range run {
-		b.WriteString(run[i].name)
-		indexes[i] = b.Len()
-	}
-	nameConst := fmt.Sprintf("_%s_name%s = %q", typeName, suffix, b.String())
-	nameLen := b.Len()
-	b.Reset()
-	fmt.Fprintf(b, "_%s_index%s = [...]uint%d{0, ", typeName, suffix, usize(nameLen))
-	for i, v := range indexes {
-		if i > 0 {
-			fmt.Fprintf(b, ", ")
+	// Write to file, or to standard output.
+	if outputName == "-" {
+		j.stdout.Write(src)
+		return "", nil
+	}
+	if outputName == "" {
+		outputName = defaultOutput(j.dir, types[0], *goos, *goarch)
+	}
+	base := strings.TrimSuffix(outputName, ".go")
+	if c.gentest {
+		if err := j.writeBeside(base+"_test.go", test.Bytes()); err != nil {
+			return "", err
 		}
-		fmt.Fprintf(b, "%d", v)
 	}
-	fmt.Fprintf(b, "}")
-	return b.String(), nameConst
-}
-
-// declareNameVars declares the concatenated names string representing all the values in the runs.
-func (g *Generator) declareNameVars(runs [][]Value, typeName string, suffix string) {
-	g.Printf("const _%s_name%s = \"", typeName, suffix)
-	for _, run := range runs {
-		for i := range run {
-			g.Printf("%s", run[i].name)
+	if c.schema {
+		if err := j.writeBeside(base+".json", schema.Bytes()); err != nil {
+			return "", err
 		}
 	}
-	g.Printf("\"\n")
-}
-
-// buildOneRun generates the variables and String method for a single run of contiguous values.
-func (g *Generator) buildOneRun(runs [][]Value, typeName string) {
-	values := runs[0]
-	g.Printf("\n")
-	g.declareIndexAndNameVar(values, typeName)
-	// The generated code is simple enough to write as a Printf format.
-	lessThanZero := ""
-	if values[0].signed {
-		lessThanZero = "i < 0 || "
-	}
-	if values[0].value == 0 { // Signed or unsigned, 0 is still 0.
-		g.Printf(stringOneRun, typeName, usize(len(values)), lessThanZero)
-	} else {
-		g.Printf(stringOneRunWithOffset, typeName, values[0].String(), usize(len(values)), lessThanZero)
+	if c.check {
+		return "", j.checkFile(outputName, src)
 	}
+	if old, err := ioutil.ReadFile(outputName); err == nil && bytes.Equal(old, src) {
+		return "", nil
+	}
+	err := ioutil.WriteFile(outputName, src, 0644)
+	if err != nil {
+		return "", fmt.Errorf("writing output: %s", err)
+	}
+	return outputName, nil
 }
 
-// Arguments to format are:
-//	[1]: type name
-//	[2]: size of index element (8 for uint8 etc.)
-//	[3]: less than zero check (for signed types)
-const stringOneRun = `func (i %[1]s) String() string {
-	if %[3]si >= %[1]s(len(_%[1]s_index)-1) {
-		return fmt.Sprintf("%[1]s(%%d)", i)
-	}
-	return _%[1]s_name[_%[1]s_index[i]:_%[1]s_index[i+1]]
-}
-`
-
-// Arguments to format are:
-//	[1]: type name
-//	[2]: lowest defined value for type, as a string
-//	[3]: size of index element (8 for uint8 etc.)
-//	[4]: less than zero check (for signed types)
-/*
- */
-const stringOneRunWithOffset = `func (i %[1]s) String() string {
-	i -= %[2]s
-	if %[4]si >= %[1]s(len(_%[1]s_index)-1) {
-		return fmt.Sprintf("%[1]s(%%d)", i + %[2]s)
-	}
-	return _%[1]s_name[_%[1]s_index[i] : _%[1]s_index[i+1]]
+// writeBeside writes src, generated along with the code, to the named file,
+// unless the file already holds it, or with -check compares the two.
+func (j *job) writeBeside(name string, src []byte) error {
+	if j.c.check {
+		return j.checkFile(name, src)
+	}
+	if old, err := ioutil.ReadFile(name); err == nil && bytes.Equal(old, src) {
+		return nil
+	}
+	if err := ioutil.WriteFile(name, src, 0644); err != nil {
+		return fmt.Errorf("writing output: %s", err)
+	}
+	return nil
 }
-`
 
-// buildMultipleRuns generates the variables and String method for multiple runs of contiguous values.
-// For this pattern, a single Printf format won't do.
-func (g *Generator) buildMultipleRuns(runs [][]Value, typeName string) {
-	g.Printf("\n")
-	g.declareIndexAndNameVars(runs, typeName)
-	g.Printf("func (i %s) String() string {\n", typeName)
-	g.Printf("\tswitch {\n")
-	for i, values := range runs {
-		if len(values) == 1 {
-			g.Printf("\tcase i == %s:\n", &values[0])
-			g.Printf("\t\treturn _%s_name_%d\n", typeName, i)
-			continue
-		}
-		g.Printf("\tcase %s <= i && i <= %s:\n", &values[0], &values[len(values)-1])
-		if values[0].value != 0 {
-			g.Printf("\t\ti -= %s\n", &values[0])
-		}
-		g.Printf("\t\treturn _%s_name_%d[_%s_index_%d[i]:_%s_index_%d[i+1]]\n",
-			typeName, i, typeName, i, typeName, i)
+// isDirectory reports whether the named file is a directory.
+func isDirectory(name string) bool {
+	info, err := os.Stat(name)
+	if err != nil {
+		fatal(&stringer.Error{Kind: stringer.FileError, Pos: token.Position{Filename: name}, Msg: err.Error()})
 	}
-	g.Printf("\tdefault:\n")
-	g.Printf("\t\treturn fmt.Sprintf(\"%s(%%d)\", i)\n", typeName)
-	g.Printf("\t}\n")
-	g.Printf("}\n")
+	return info.IsDir()
 }
 
-// buildMap handles the case where the space is so sparse a map is a reasonable fallback.
-// It's a rare situation but has simple code.
-func (g *Generator) buildMap(runs [][]Value, typeName string) {
-	g.Printf("\n")
-	g.declareNameVars(runs, typeName, "")
-	g.Printf("\nvar _%s_map = map[%s]string{\n", typeName, typeName)
-	n := 0
-	for _, values := range runs {
-		for _, value := range values {
-			g.Printf("\t%s: _%s_name[%d:%d],\n", &value, typeName, n, n+len(value.name))
-			n += len(value.name)
-		}
+// defaultOutput returns the name of the file in dir to which the code for
+// the named type, and any others, is written by default. It ends with the
+// target platform, if that is set, so that the file is built only for it.
+func defaultOutput(dir, typeName, goos, goarch string) string {
+	baseName := typeName + "_string"
+	if goos != "" {
+		baseName += "_" + goos
+	}
+	if goarch != "" {
+		baseName += "_" + goarch
 	}
-	g.Printf("}\n\n")
-	g.Printf(stringMap, typeName)
+	return filepath.Join(dir, strings.ToLower(baseName)+".go")
 }
 
-// Argument to format is the type name.
-const stringMap = `func (i %[1]s) String() string {
-	if str, ok := _%[1]s_map[i]; ok {
-		return str
+// readHeader returns the lines of the named file, to be added to the header
+// of the generated files, each of which must be blank or a // comment.
+func readHeader(name string) []byte {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		log.Fatalf("reading header: %s", err)
+	}
+	var header []byte
+	for i, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line != "" && !strings.HasPrefix(line, "//") {
+			log.Fatalf("%s:%d: header line is not a // comment", name, i+1)
+		}
+		header = append(header, line+"\n"...)
 	}
-	return fmt.Sprintf("%[1]s(%%d)", i)
+	return header
 }
-`
@@ -0,0 +1,51 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Constants logged by name with log/slog, with -slog.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+type Alarm int
+
+const (
+	Low Alarm = iota - 1
+	Normal
+	High
+)
+
+func main() {
+	ck(Low, "Low")
+	ck(High, "High")
+	ck(7, "Alarm(7)")
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("known", "p", High)
+	logger.Info("unknown", "p", Alarm(-5))
+	want := `{"level":"INFO","msg":"known","p":"High"}
+{"level":"INFO","msg":"unknown","p":{"name":"Alarm(-5)","code":-5}}
+`
+	if buf.String() != want {
+		panic("alarm.go: LogValue: " + strings.TrimSpace(buf.String()))
+	}
+}
+
+func ck(alarm Alarm, str string) {
+	if fmt.Sprint(alarm) != str {
+		panic("alarm.go: " + str)
+	}
+}
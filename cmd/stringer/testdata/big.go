@@ -0,0 +1,40 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Unsigned constants at the top of the uint64 range.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+type Big uint64
+
+const (
+	big0    Big = 0
+	bigHigh Big = 1 << 63
+	bigMax1 Big = math.MaxUint64 - 1
+	bigMax  Big = math.MaxUint64
+)
+
+func main() {
+	ck(big0, "big0")
+	ck(1, "Big(1)")
+	ck(bigHigh, "bigHigh")
+	ck(bigHigh+1, "Big(9223372036854775809)")
+	ck(bigMax-2, "Big(18446744073709551613)")
+	ck(bigMax1, "bigMax1")
+	ck(bigMax, "bigMax")
+	if !bigMax.IsValid() || (bigMax - 2).IsValid() {
+		panic("big.go: IsValid")
+	}
+}
+
+func ck(big Big, str string) {
+	if fmt.Sprint(big) != str {
+		panic("big.go: " + str)
+	}
+}
@@ -2,12 +2,13 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Import "C" shouldn't be imported.
+// Import "C" shouldn't be imported, but constants may be defined by C.
 
 package main
 
 /*
 #define HELLO 1
+#define WORLD 8
 */
 import "C"
 
@@ -18,11 +19,14 @@ type Cgo uint32
 const (
 	// MustScanSubDirs indicates that events were coalesced hierarchically.
 	MustScanSubDirs Cgo = 1 << iota
+	World           Cgo = C.WORLD
 )
 
 func main() {
 	_ = C.HELLO
 	ck(MustScanSubDirs, "MustScanSubDirs")
+	ck(World, "World")
+	ck(2, "Cgo(2)")
 }
 
 func ck(day Cgo, str string) {
@@ -0,0 +1,50 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Codes declared in groups of const blocks, with -blocks and -group.
+
+package main
+
+import "fmt"
+
+type Code int
+
+//stringer:group client
+const (
+	BadRequest Code = 400 + iota
+	Unauthorized
+	PaymentRequired
+)
+
+// Codes with no group.
+const (
+	Teapot Code = 418 + iota
+	Misdirected
+)
+
+//stringer:group server
+const (
+	InternalError Code = 500 + iota
+	NotImplemented
+	BadGateway
+)
+
+func main() {
+	ck(BadRequest, "BadRequest", "client")
+	ck(PaymentRequired, "PaymentRequired", "client")
+	ck(Teapot, "Teapot", "")
+	ck(Misdirected, "Misdirected", "")
+	ck(BadGateway, "BadGateway", "server")
+	ck(403, "Code(403)", "")
+	ck(503, "Code(503)", "")
+}
+
+func ck(code Code, str, group string) {
+	if fmt.Sprint(code) != str {
+		panic("code.go: " + str)
+	}
+	if code.Group() != group {
+		panic("code.go: Group of " + str)
+	}
+}
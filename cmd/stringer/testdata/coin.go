@@ -0,0 +1,57 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Names parsed in any case with -fold, and aliases given by comments.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Coin int
+
+const (
+	// Heads is the side with the portrait.
+	//stringer:alias H
+	Heads Coin = iota
+	Tails      //stringer:alias T Reverse
+	Edge
+	Obverse = Heads //stringer:alias Front
+)
+
+func main() {
+	ck(Heads, "Heads")
+	ck(Tails, "Tails")
+	ck(3, "Coin(3)")
+	ckParse("Heads", Heads)
+	ckParse("tails", Tails)
+	ckParse("EDGE", Edge)
+	ckParse("H", Heads)
+	ckParse("reverse", Tails)
+	ckParse("front", Heads)
+	if _, err := ParseCoin("Side"); err == nil {
+		panic("coin.go: ParseCoin(Side)")
+	}
+	var c Coin
+	if err := json.Unmarshal([]byte(`"T"`), &c); err != nil || c != Tails {
+		panic("coin.go: UnmarshalText")
+	}
+	if err := json.Unmarshal([]byte(`"t"`), &c); err == nil {
+		panic("coin.go: UnmarshalText ignores case")
+	}
+}
+
+func ck(coin Coin, str string) {
+	if fmt.Sprint(coin) != str {
+		panic("coin.go: " + str)
+	}
+}
+
+func ckParse(s string, coin Coin) {
+	if c, err := ParseCoin(s); err != nil || c != coin {
+		panic("coin.go: ParseCoin(" + s + ")")
+	}
+}
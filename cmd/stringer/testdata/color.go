@@ -0,0 +1,84 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Parse function, text marshaling, values, names and validity, with a
+// trimmed prefix and a gap.
+// Generated with -parse -text -values -strings -isvalid -trimprefix=Color.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Color int
+
+const (
+	ColorRed Color = iota
+	ColorGreen
+	ColorBlue
+	ColorBlack Color = 10
+	ColorNone  Color = ColorRed // Duplicate; not listed by ColorValues.
+)
+
+func main() {
+	ck(ColorRed, "Red")
+	ck(ColorGreen, "Green")
+	ck(ColorBlue, "Blue")
+	ck(ColorBlack, "Black")
+	ck(3, "Color(3)")
+	ckErr("Color(3)")
+	ckErr("ColorRed")
+	ckErr("")
+	ckJSON(map[Color]Color{ColorRed: ColorBlack}, `{"Red":"Black"}`)
+	if fmt.Sprint(ColorValues()) != "[Red Green Blue Black]" {
+		panic("color.go: ColorValues")
+	}
+	if fmt.Sprint(ColorStrings()) != "[Red Green Blue Black]" {
+		panic("color.go: ColorStrings")
+	}
+	if got := fmt.Sprintf("%#v", []Color{ColorGreen, 3}); got != "[]main.Color{main.ColorGreen, main.Color(3)}" {
+		panic("color.go: GoString: " + got)
+	}
+	for c := Color(-1); c <= 11; c++ {
+		if c.IsValid() != (c <= ColorBlue && c >= ColorRed || c == ColorBlack) {
+			panic(fmt.Sprintf("color.go: IsValid(%d)", c))
+		}
+	}
+}
+
+func ck(color Color, str string) {
+	if fmt.Sprint(color) != str {
+		panic("color.go: " + str)
+	}
+	if c, err := ParseColor(str); c != color && err == nil {
+		panic("color.go: ParseColor " + str)
+	}
+}
+
+func ckErr(str string) {
+	if _, err := ParseColor(str); err == nil {
+		panic("color.go: ParseColor accepted " + str)
+	}
+}
+
+func ckJSON(m map[Color]Color, str string) {
+	b, err := json.Marshal(m)
+	if err != nil || string(b) != str {
+		panic("color.go: json.Marshal " + str)
+	}
+	var n map[Color]Color
+	if err := json.Unmarshal(b, &n); err != nil || len(n) != len(m) {
+		panic("color.go: json.Unmarshal " + str)
+	}
+	for k, v := range m {
+		if n[k] != v {
+			panic("color.go: json.Unmarshal " + str)
+		}
+	}
+	if err := json.Unmarshal([]byte(`"Purple"`), new(Color)); err == nil {
+		panic("color.go: json.Unmarshal accepted Purple")
+	}
+}
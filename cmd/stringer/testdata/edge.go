@@ -0,0 +1,43 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Signed constants at both ends of the int64 range.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+type Edge int64
+
+const (
+	edgeMin  Edge = math.MinInt64
+	edgeMin1 Edge = math.MinInt64 + 1
+	edgeMax1 Edge = math.MaxInt64 - 1
+	edgeMax  Edge = math.MaxInt64
+)
+
+func main() {
+	ck(edgeMin, "edgeMin")
+	ck(edgeMin1, "edgeMin1")
+	ck(edgeMin1+1, "Edge(-9223372036854775806)")
+	ck(0, "Edge(0)")
+	ck(edgeMax1-1, "Edge(9223372036854775805)")
+	ck(edgeMax1, "edgeMax1")
+	ck(edgeMax, "edgeMax")
+	if !edgeMin.IsValid() || !edgeMax.IsValid() || Edge(0).IsValid() {
+		panic("edge.go: IsValid")
+	}
+	if edgeMin1.Ordinal() != 1 || edgeMax.Ordinal() != 3 || Edge(0).Ordinal() != -1 {
+		panic("edge.go: Ordinal")
+	}
+}
+
+func ck(edge Edge, str string) {
+	if fmt.Sprint(edge) != str {
+		panic("edge.go: " + str)
+	}
+}
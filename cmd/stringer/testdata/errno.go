@@ -0,0 +1,41 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Error codes with an Error method in place of String, with -erroronly.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Errno uint8
+
+const (
+	EPERM Errno = iota + 1
+	ENOENT
+	EINTR
+)
+
+func main() {
+	ck(ENOENT, "ENOENT")
+	ck(9, "Errno(9)")
+	var err error = EINTR
+	if err.Error() != "EINTR" {
+		panic("errno.go: Error")
+	}
+	if _, ok := interface{}(EPERM).(fmt.Stringer); ok {
+		panic("errno.go: String")
+	}
+	if data, err := json.Marshal(EPERM); err != nil || string(data) != `"EPERM"` {
+		panic("errno.go: MarshalText")
+	}
+}
+
+func ck(errno Errno, str string) {
+	if fmt.Sprint(errno) != str {
+		panic("errno.go: " + str)
+	}
+}
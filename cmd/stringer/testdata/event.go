@@ -0,0 +1,43 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Names sharing prefixes, in several runs, stored with -prefixes.
+
+package main
+
+import "fmt"
+
+type Event int
+
+const (
+	EventUserCreated Event = iota
+	EventUserDeleted
+	EventUserRenamed
+	EventOrderPlaced Event = iota + 7
+	EventOrderShipped
+	EventOrderCancelled
+	EventLogin Event = 20
+)
+
+func main() {
+	ck(EventUserCreated, "EventUserCreated")
+	ck(EventUserRenamed, "EventUserRenamed")
+	ck(EventOrderPlaced, "EventOrderPlaced")
+	ck(EventOrderCancelled, "EventOrderCancelled")
+	ck(EventLogin, "EventLogin")
+	ck(3, "Event(3)")
+	ck(-1, "Event(-1)")
+	if e, err := ParseEvent("EventOrderShipped"); err != nil || e != EventOrderShipped {
+		panic("event.go: ParseEvent")
+	}
+	if _, err := ParseEvent("Shipped"); err == nil {
+		panic("event.go: ParseEvent accepts Shipped")
+	}
+}
+
+func ck(event Event, str string) {
+	if fmt.Sprint(event) != str {
+		panic("event.go: " + str)
+	}
+}
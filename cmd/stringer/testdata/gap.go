@@ -3,6 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // Gaps and an offset.
+// Generated with -ordinal.
 
 package main
 
@@ -35,6 +36,11 @@ func main() {
 	ck(10, "Gap(10)")
 	ck(Eleven, "Eleven")
 	ck(12, "Gap(12)")
+	for i, gap := range []Gap{1, Two, Three, 4, Five, Nine, Eleven, 12} {
+		if ordinal := []int{-1, 0, 1, -1, 2, 6, 7, -1}[i]; gap.Ordinal() != ordinal {
+			panic(fmt.Sprintf("gap.go: Ordinal(%d) = %d", gap, gap.Ordinal()))
+		}
+	}
 }
 
 func ck(gap Gap, str string) {
@@ -0,0 +1,92 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// JSON and SQL methods and validity of a small signed type.
+// Generated with -json -sql -isvalid.
+
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type Level int8
+
+const (
+	Debug Level = iota - 1
+	Info
+	Warning
+	Error
+)
+
+type config struct {
+	Level Level
+}
+
+func main() {
+	ck(Debug, "Debug")
+	ck(Error, "Error")
+	ck(3, "Level(3)")
+	ckJSON(`{"Level":"Warning"}`, Warning)
+	ckJSON(`{"Level":1}`, Warning)
+	ckJSON(`{"Level":-1}`, Debug)
+	ckJSON(`{"Level":null}`, Info)
+	ckErr(`{"Level":"Fatal"}`)
+	ckErr(`{"Level":3}`)
+	ckErr(`{"Level":255}`) // Would be Debug if truncated to int8.
+	ckErr(`{"Level":true}`)
+	b, err := json.Marshal(config{Error})
+	if err != nil || string(b) != `{"Level":"Error"}` {
+		panic("level.go: json.Marshal")
+	}
+	ckSQL()
+	for l := Level(-128); l < 127; l++ {
+		if l.IsValid() != (Debug <= l && l <= Error) {
+			panic(fmt.Sprintf("level.go: IsValid(%d)", l))
+		}
+	}
+}
+
+func ck(level Level, str string) {
+	if fmt.Sprint(level) != str {
+		panic("level.go: " + str)
+	}
+}
+
+func ckJSON(str string, level Level) {
+	var c config
+	if err := json.Unmarshal([]byte(str), &c); err != nil || c.Level != level {
+		panic("level.go: json.Unmarshal " + str)
+	}
+}
+
+func ckErr(str string) {
+	var c config
+	if err := json.Unmarshal([]byte(str), &c); err == nil {
+		panic("level.go: json.Unmarshal accepted " + str)
+	}
+}
+
+func ckSQL() {
+	var l Level
+	for _, value := range []interface{}{"Warning", []byte("Warning"), nil} {
+		if err := l.Scan(value); err != nil || l != Warning {
+			panic(fmt.Sprintf("level.go: Scan(%v)", value))
+		}
+	}
+	for _, value := range []interface{}{"Fatal", 1, int64(1)} {
+		if err := l.Scan(value); err == nil {
+			panic(fmt.Sprintf("level.go: Scan accepted %v", value))
+		}
+	}
+	var valuer driver.Valuer = Error
+	if v, err := valuer.Value(); err != nil || v != "Error" {
+		panic("level.go: Value")
+	}
+	if _, err := Level(3).Value(); err == nil {
+		panic("level.go: Value accepted Level(3)")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// YAML methods, called as a YAML package would.
+// Generated with -yaml.
+
+package main
+
+import "errors"
+
+type Mode int
+
+const (
+	Off Mode = iota
+	Auto
+	On
+)
+
+// unmarshalString returns an unmarshal function, as passed to UnmarshalYAML,
+// for a YAML document holding the scalar s.
+func unmarshalString(s string) func(interface{}) error {
+	return func(v interface{}) error {
+		p, ok := v.(*string)
+		if !ok {
+			return errors.New("not a string")
+		}
+		*p = s
+		return nil
+	}
+}
+
+func main() {
+	var m Mode
+	if err := m.UnmarshalYAML(unmarshalString("Auto")); err != nil || m != Auto {
+		panic("mode.go: UnmarshalYAML")
+	}
+	if err := m.UnmarshalYAML(unmarshalString("Manual")); err == nil {
+		panic("mode.go: UnmarshalYAML accepted Manual")
+	}
+	if v, err := On.MarshalYAML(); err != nil || v != "On" {
+		panic("mode.go: MarshalYAML")
+	}
+	if _, err := Mode(3).MarshalYAML(); err == nil {
+		panic("mode.go: MarshalYAML accepted Mode(3)")
+	}
+}
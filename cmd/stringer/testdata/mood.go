@@ -0,0 +1,39 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Values with no name, marshaled as the name of a constant with
+// -textinvalid=name:Unknown.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Mood uint8
+
+const (
+	MoodUnknown Mood = iota
+	MoodHappy
+	MoodSad
+)
+
+func main() {
+	ck(MoodHappy, "Happy")
+	ck(9, "Mood(9)")
+	if data, err := json.Marshal([]Mood{MoodSad, 9}); err != nil || string(data) != `["Sad","Unknown"]` {
+		panic("mood.go: MarshalText")
+	}
+	var m Mood = MoodSad
+	if err := json.Unmarshal([]byte(`"Unknown"`), &m); err != nil || m != MoodUnknown {
+		panic("mood.go: UnmarshalText")
+	}
+}
+
+func ck(mood Mood, str string) {
+	if fmt.Sprint(mood) != str {
+		panic("mood.go: " + str)
+	}
+}
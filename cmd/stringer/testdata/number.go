@@ -4,6 +4,7 @@
 
 // Enumeration with an offset.
 // Also includes a duplicate.
+// Generated with -valueof.
 
 package main
 
@@ -25,6 +26,14 @@ func main() {
 	ck(Three, "Three")
 	ck(AnotherOne, "One")
 	ck(127, "Number(127)")
+	for name, want := range map[string]Number{"One": One, "Three": Three, "AnotherOne": One} {
+		if n, ok := NumberValueOf(name); !ok || n != want {
+			panic("number.go: NumberValueOf(" + name + ")")
+		}
+	}
+	if _, ok := NumberValueOf("Four"); ok {
+		panic("number.go: NumberValueOf(Four)")
+	}
 }
 
 func ck(num Number, str string) {
@@ -0,0 +1,47 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bit flags, with a combination that is ignored.
+// Generated with -flags -isvalid -parse.
+
+package main
+
+import "fmt"
+
+type Perm uint8
+
+const (
+	Exec Perm = 1 << iota
+	Write
+	Read
+	_
+	Sticky
+	ReadWrite = Read | Write
+)
+
+func main() {
+	ck(0, "Perm(0)")
+	ck(Exec, "Exec")
+	ck(Write, "Write")
+	ck(Read, "Read")
+	ck(ReadWrite, "Write|Read")
+	ck(Exec|Sticky, "Exec|Sticky")
+	ck(Read|8, "Read|Perm(8)")
+	ck(0xE8, "Perm(232)")
+	if !(Exec | Read | Sticky).IsValid() {
+		panic("perm.go: IsValid(Exec|Read|Sticky)")
+	}
+	if (Exec | 8).IsValid() {
+		panic("perm.go: IsValid(Exec|8)")
+	}
+	if p, err := ParsePerm("Sticky"); p != Sticky || err != nil {
+		panic("perm.go: ParsePerm")
+	}
+}
+
+func ck(perm Perm, str string) {
+	if fmt.Sprint(perm) != str {
+		panic("perm.go: " + str)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Gob encoding by name.
+// Generated with -gob.
+
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+)
+
+type Phase uint
+
+const (
+	New Phase = iota + 1
+	Running
+	Done
+)
+
+type job struct {
+	Phase Phase
+}
+
+func main() {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(job{Running}); err != nil {
+		panic("phase.go: Encode: " + err.Error())
+	}
+	if !strings.Contains(buf.String(), "Running") {
+		panic("phase.go: not encoded by name")
+	}
+	var j job
+	if err := gob.NewDecoder(&buf).Decode(&j); err != nil || j.Phase != Running {
+		panic("phase.go: Decode")
+	}
+	if err := gob.NewEncoder(&buf).Encode(job{7}); err == nil {
+		panic("phase.go: encoded Phase(7)")
+	}
+	var p Phase
+	if err := p.GobDecode([]byte("Stopped")); err == nil {
+		panic("phase.go: decoded Stopped")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Pointer receivers.
+// Generated with -ptr and -isvalid.
+
+package main
+
+import "fmt"
+
+type Pointer int
+
+const (
+	Near Pointer = iota
+	Far
+)
+
+func main() {
+	p := Far
+	if got := fmt.Sprint(&p); got != "Far" {
+		panic("pointer.go: Sprint(&Far) = " + got)
+	}
+	var nilPointer *Pointer
+	if got := fmt.Sprint(nilPointer); got != "<nil>" {
+		panic("pointer.go: Sprint(nil) = " + got)
+	}
+	if nilPointer.IsValid() {
+		panic("pointer.go: nil is valid")
+	}
+	p = 7
+	if got := p.String(); got != "Pointer(7)" {
+		panic("pointer.go: Pointer(7).String() = " + got)
+	}
+	if p.IsValid() {
+		panic("pointer.go: Pointer(7) is valid")
+	}
+}
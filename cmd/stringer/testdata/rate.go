@@ -0,0 +1,42 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Constants of a floating-point type.
+
+package main
+
+import "fmt"
+
+type Rate float32
+
+const (
+	RatePhone Rate = 8000
+	RateCD    Rate = 44100
+	RateDVD   Rate = 48e3
+	RateSlow  Rate = 0.1
+	RateBack  Rate = -1.5
+)
+
+func main() {
+	ck(RatePhone, "Phone")
+	ck(RateCD, "CD")
+	ck(RateDVD, "DVD")
+	ck(RateSlow, "Slow")
+	ck(RateBack, "Back")
+	ck(0.2, "Rate(0.2)")
+	ck(-1, "Rate(-1)")
+	if !RateSlow.IsValid() || Rate(0.2).IsValid() {
+		panic("rate.go: IsValid")
+	}
+	var r Rate
+	if err := r.UnmarshalText([]byte("Slow")); err != nil || r != RateSlow {
+		panic("rate.go: UnmarshalText")
+	}
+}
+
+func ck(rate Rate, str string) {
+	if fmt.Sprint(rate) != str {
+		panic("rate.go: " + str)
+	}
+}
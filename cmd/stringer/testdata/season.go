@@ -0,0 +1,58 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Descriptions and translated names.
+// Generated with -description -localize.
+
+package main
+
+type Season int
+
+const (
+	// Spring is when the days grow longer.
+	Spring Season = iota
+	Summer
+	// Autumn is also known as fall.
+	Autumn
+	Winter
+	Fall = Autumn
+)
+
+var french = map[string]string{
+	"Season.Spring": "printemps",
+	"Season.Autumn": "automne",
+}
+
+func main() {
+	if Spring.Description() != "Spring is when the days grow longer." || Fall.Description() != "Autumn is also known as fall." || Summer.Description() != "" {
+		panic("season.go: Description")
+	}
+	if Fall.MessageKey() != "Season.Autumn" || Season(7).MessageKey() != "" {
+		panic("season.go: MessageKey")
+	}
+	if Autumn.Localized("fr") != "Autumn" {
+		panic("season.go: Localized without hook")
+	}
+	LocalizeSeason = func(lang, key string) (string, bool) {
+		if lang != "fr" {
+			return "", false
+		}
+		s, ok := french[key]
+		return s, ok
+	}
+	for _, test := range []struct {
+		season Season
+		lang   string
+		name   string
+	}{
+		{Autumn, "fr", "automne"},
+		{Autumn, "en", "Autumn"},
+		{Winter, "fr", "Winter"},
+		{Season(7), "fr", "Season(7)"},
+	} {
+		if got := test.season.Localized(test.lang); got != test.name {
+			panic("season.go: Localized: " + got)
+		}
+	}
+}
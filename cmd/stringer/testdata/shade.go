@@ -0,0 +1,30 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Constants printed with any verb, width and flags, with -format.
+
+package main
+
+import "fmt"
+
+type Shade uint8
+
+const (
+	Light Shade = iota
+	Medium
+	Dark Shade = 10
+)
+
+func main() {
+	ck(fmt.Sprintf("%v %s %q %d %x", Dark, Dark, Dark, Dark, Dark), `Dark Dark "Dark" 10 a`)
+	ck(fmt.Sprintf("%#v %#v", Medium, Shade(3)), "main.Medium main.Shade(3)")
+	ck(fmt.Sprintf("[%-7v|%7s|%03d|%08b]", Light, Light, Light, Medium), "[Light  |  Light|000|00000001]")
+	ck(fmt.Sprint(Shade(3)), "Shade(3)")
+}
+
+func ck(got, str string) {
+	if got != str {
+		panic("shade.go: " + got)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A custom fallback format, with an offset.
+// Generated with -invalid="unknown signal %v".
+
+package main
+
+import "fmt"
+
+type Signal int
+
+const (
+	Hangup Signal = iota + 1
+	Interrupt
+	Quit
+)
+
+func main() {
+	ck(Hangup, "Hangup")
+	ck(Quit, "Quit")
+	ck(0, "unknown signal 0")
+	ck(-1, "unknown signal -1")
+	ck(4, "unknown signal 4")
+}
+
+func ck(signal Signal, str string) {
+	if fmt.Sprint(signal) != str {
+		panic("signal.go: " + str)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Signed constants at the bottom of the int64 range, whose offset cannot be
+// negated.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+type Small int64
+
+const (
+	small0 Small = math.MinInt64 + iota
+	small1
+	small2
+)
+
+func main() {
+	ck(small0, "small0")
+	ck(small1, "small1")
+	ck(small2, "small2")
+	ck(small2+1, "Small(-9223372036854775805)")
+	ck(0, "Small(0)")
+	ck(math.MaxInt64, "Small(9223372036854775807)")
+	if !small0.IsValid() || !small2.IsValid() || (small2 + 1).IsValid() || Small(math.MaxInt64).IsValid() {
+		panic("small.go: IsValid")
+	}
+	if small0.Ordinal() != 0 || small2.Ordinal() != 2 || Small(-1).Ordinal() != -1 {
+		panic("small.go: Ordinal")
+	}
+}
+
+func ck(small Small, str string) {
+	if fmt.Sprint(small) != str {
+		panic("small.go: " + str)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Sparse signed values found by binary search.
+// Generated with -lookup=binarysearch -isvalid -iter.
+
+package main
+
+import "fmt"
+
+type Sparse int16
+
+const (
+	s100   Sparse = 100
+	sm5    Sparse = -5
+	s30    Sparse = 30
+	sm70   Sparse = -70
+	s2     Sparse = 2
+	s1000  Sparse = 1000
+	s40    Sparse = 40
+	s8     Sparse = 8
+	sm300  Sparse = -300
+	s12    Sparse = 12
+	s20000 Sparse = 20000
+	s0     Sparse = 0
+	sMin   Sparse = -1 << 15
+)
+
+func main() {
+	ck(sMin, "sMin")
+	ck(sm300, "sm300")
+	ck(sm5, "sm5")
+	ck(s0, "s0")
+	ck(s12, "s12")
+	ck(s20000, "s20000")
+	ck(-301, "Sparse(-301)")
+	ck(1, "Sparse(1)")
+	ck(13, "Sparse(13)")
+	ck(1<<15-1, "Sparse(32767)")
+	for i := -1 << 15; i < 1<<15; i++ {
+		s := Sparse(i)
+		if s.IsValid() != (fmt.Sprintf("Sparse(%d)", i) != s.String()) {
+			panic(fmt.Sprintf("sparse.go: IsValid(%d)", i))
+		}
+	}
+	n, prev := 0, Sparse(0)
+	for s, ok := FirstSparse, true; ok; s, ok = s.Next() {
+		if !s.IsValid() || n > 0 && s <= prev {
+			panic(fmt.Sprintf("sparse.go: Next visited %d", s))
+		}
+		n, prev = n+1, s
+	}
+	for s, ok := LastSparse, true; ok; s, ok = s.Prev() {
+		n--
+	}
+	if n != 0 || FirstSparse != sMin || LastSparse != s20000 {
+		panic("sparse.go: Next and Prev visit different values")
+	}
+	if s, ok := Sparse(1).Next(); !ok || s != s2 {
+		panic("sparse.go: Next(1)")
+	}
+	if s, ok := Sparse(1).Prev(); !ok || s != s0 {
+		panic("sparse.go: Prev(1)")
+	}
+	if _, ok := LastSparse.Next(); ok {
+		panic("sparse.go: Next(LastSparse)")
+	}
+}
+
+func ck(sparse Sparse, str string) {
+	if fmt.Sprint(sparse) != str {
+		panic("sparse.go: " + str)
+	}
+}
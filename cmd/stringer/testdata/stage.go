@@ -0,0 +1,42 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A type with a String method of its own, and a Label method, with -method.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Stage int
+
+const (
+	Draft Stage = iota
+	Review
+	Final
+)
+
+func (s Stage) String() string {
+	return fmt.Sprintf("stage %d", int(s))
+}
+
+func main() {
+	ck(Draft, "Draft")
+	ck(Final, "Final")
+	ck(7, "Stage(7)")
+	if s := fmt.Sprint(Review); s != "stage 1" {
+		panic("stage.go: String: " + s)
+	}
+	if data, err := json.Marshal(Review); err != nil || string(data) != `"Review"` {
+		panic("stage.go: MarshalText")
+	}
+}
+
+func ck(stage Stage, str string) {
+	if stage.Label() != str {
+		panic("stage.go: " + str)
+	}
+}
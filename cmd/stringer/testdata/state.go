@@ -0,0 +1,45 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A type whose underlying type is string.
+
+package main
+
+import "fmt"
+
+type State string
+
+const (
+	Active   State = "active"
+	Inactive State = "inactive"
+	Idle     State = "inactive"
+	Pending  State = "pending"
+)
+
+func main() {
+	ck(Active, "active")
+	ck(Idle, "inactive")
+	ck("other", "other")
+	if s, err := ParseState("pending"); err != nil || s != Pending {
+		panic("state.go: ParseState")
+	}
+	if _, err := ParseState("other"); err == nil {
+		panic("state.go: ParseState accepts other")
+	}
+	if State("other").IsValid() {
+		panic("state.go: IsValid")
+	}
+	if fmt.Sprint(StateValues()) != "[active inactive pending]" {
+		panic("state.go: StateValues")
+	}
+	if fmt.Sprint(StateStrings()) != "[active inactive pending]" {
+		panic("state.go: StateStrings")
+	}
+}
+
+func ck(state State, str string) {
+	if fmt.Sprint(state) != str {
+		panic("state.go: " + str)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Error codes returned as errors with -error and -errorprefix.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusNotFound
+	StatusGone
+)
+
+func find(name string) error {
+	if name == "" {
+		return StatusNotFound
+	}
+	return nil
+}
+
+func main() {
+	ck(StatusNotFound, "StatusNotFound")
+	ck(7, "Status(7)")
+	err := find("")
+	if err.Error() != "Status: StatusNotFound" {
+		panic("status.go: Error")
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), StatusNotFound) {
+		panic("status.go: errors.Is")
+	}
+	if fmt.Sprint(err) != "Status: StatusNotFound" {
+		panic("status.go: Sprint of the error")
+	}
+}
+
+func ck(status Status, str string) {
+	if status.String() != str {
+		panic("status.go: " + str)
+	}
+}
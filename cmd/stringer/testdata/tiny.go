@@ -0,0 +1,39 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated with -nofmt, in a program that does not import fmt.
+
+package main
+
+type Tiny int8
+
+const (
+	tinyA Tiny = iota - 2
+	tinyB
+	tinyC
+	tinyE Tiny = 5
+)
+
+func main() {
+	ck(tinyA, "tinyA")
+	ck(tinyC, "tinyC")
+	ck(tinyE, "tinyE")
+	ck(-3, "Tiny(-3)")
+	ck(1, "Tiny(1)")
+	if _, err := ParseTiny("tinyD"); err == nil || err.Error() != `invalid Tiny "tinyD"` {
+		panic("tiny.go: ParseTiny")
+	}
+	if t, err := ParseTiny("tinyB"); err != nil || t != tinyB {
+		panic("tiny.go: ParseTiny")
+	}
+	if tinyB.GoString() != "main.tinyB" || Tiny(-100).GoString() != "main.Tiny(-100)" {
+		panic("tiny.go: GoString")
+	}
+}
+
+func ck(tiny Tiny, str string) {
+	if tiny.String() != str {
+		panic("tiny.go: " + str)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Values with no name print as quoted characters.
+// Generated with -char=fallback.
+
+package main
+
+import "fmt"
+
+type Token rune
+
+const (
+	EOF   Token = -1
+	Ident Token = 'a'
+	Plus  Token = '+'
+	Minus Token = '-'
+)
+
+func main() {
+	ck(EOF, "EOF")
+	ck(Ident, "Ident")
+	ck(Plus, "Plus")
+	ck(Minus, "Minus")
+	ck('/', "'/'")
+	ck('\n', `'\n'`)
+	ck('é', "'é'")
+}
+
+func ck(token Token, str string) {
+	if fmt.Sprint(token) != str {
+		panic("token.go: " + str)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Fixed-width binary encoding of a signed type.
+// Generated with -binary.
+
+package main
+
+import "bytes"
+
+type Wire int16
+
+const (
+	Low  Wire = -300
+	Zero Wire = 0
+	High Wire = 1000
+)
+
+func main() {
+	b, err := Low.MarshalBinary()
+	if err != nil || !bytes.Equal(b, []byte{0xfe, 0xd4}) {
+		panic("wire.go: MarshalBinary(Low)")
+	}
+	var w Wire
+	if err := w.UnmarshalBinary(b); err != nil || w != Low {
+		panic("wire.go: UnmarshalBinary(Low)")
+	}
+	if err := w.UnmarshalBinary([]byte{0x03, 0xe8}); err != nil || w != High {
+		panic("wire.go: UnmarshalBinary(High)")
+	}
+	if _, err := Wire(7).MarshalBinary(); err == nil {
+		panic("wire.go: marshaled Wire(7)")
+	}
+	if err := w.UnmarshalBinary([]byte{0, 7}); err == nil {
+		panic("wire.go: unmarshaled Wire(7)")
+	}
+	if err := w.UnmarshalBinary([]byte{0}); err == nil {
+		panic("wire.go: unmarshaled short data")
+	}
+}
@@ -7,71 +7,49 @@
 package main
 
 import (
-	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
-// Helpers to save typing in the test cases.
-type u []uint64
-type uu [][]uint64
-
-type SplitTest struct {
-	input  u
-	output uu
-	signed bool
+var withoutWatchTests = []struct {
+	args   string
+	output string
+}{
+	{"-watch -type Day", "-type Day"},
+	{"-type Day --watch=true .", "-type Day ."},
+	{"-trimprefix -watch -watch -type=Day", "-trimprefix -watch -type=Day"},
+	{"-json -watch -output day.go -- -watch", "-json -output day.go -- -watch"},
+	{"-type Day dir -watch", "-type Day dir -watch"},
 }
 
-var (
-	m2  = uint64(2)
-	m1  = uint64(1)
-	m0  = uint64(0)
-	m_1 = ^uint64(0)     // -1 when signed.
-	m_2 = ^uint64(0) - 1 // -2 when signed.
-)
+func TestWithoutWatch(t *testing.T) {
+	for _, test := range withoutWatchTests {
+		got := strings.Join(withoutWatch(strings.Fields(test.args)), " ")
+		if got != test.output {
+			t.Errorf("withoutWatch(%q) = %q; expected %q", test.args, got, test.output)
+		}
+	}
+}
 
-var splitTests = []SplitTest{
-	// No need for a test for the empty case; that's picked off before splitIntoRuns.
-	// Single value.
-	{u{1}, uu{u{1}}, false},
-	// Out of order.
-	{u{3, 2, 1}, uu{u{1, 2, 3}}, true},
-	// Out of order.
-	{u{3, 2, 1}, uu{u{1, 2, 3}}, false},
-	// A gap at the beginning.
-	{u{1, 33, 32, 31}, uu{u{1}, u{31, 32, 33}}, true},
-	// A gap in the middle, in mixed order.
-	{u{33, 7, 32, 31, 9, 8}, uu{u{7, 8, 9}, u{31, 32, 33}}, true},
-	// Gaps throughout
-	{u{33, 44, 1, 32, 45, 31}, uu{u{1}, u{31, 32, 33}, u{44, 45}}, true},
-	// Unsigned values spanning 0.
-	{u{m1, m0, m_1, m2, m_2}, uu{u{m0, m1, m2}, u{m_2, m_1}}, false},
-	// Signed values spanning 0
-	{u{m1, m0, m_1, m2, m_2}, uu{u{m_2, m_1, m0, m1, m2}}, true},
+var defaultOutputTests = []struct {
+	goos, goarch string
+	output       string
+}{
+	{"", "", "day_string.go"},
+	{"linux", "amd64", "day_string_linux_amd64.go"},
+	{"darwin", "arm64", "day_string_darwin_arm64.go"},
+	{"windows", "arm64", "day_string_windows_arm64.go"},
+	{"windows", "", "day_string_windows.go"},
+	{"", "386", "day_string_386.go"},
 }
 
-func TestSplitIntoRuns(t *testing.T) {
-Outer:
-	for n, test := range splitTests {
-		values := make([]Value, len(test.input))
-		for i, v := range test.input {
-			values[i] = Value{"", v, test.signed, fmt.Sprint(v)}
-		}
-		runs := splitIntoRuns(values)
-		if len(runs) != len(test.output) {
-			t.Errorf("#%d: %v: got %d runs; expected %d", n, test.input, len(runs), len(test.output))
-			continue
-		}
-		for i, run := range runs {
-			if len(run) != len(test.output[i]) {
-				t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
-				continue Outer
-			}
-			for j, v := range run {
-				if v.value != test.output[i][j] {
-					t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
-					continue Outer
-				}
-			}
+func TestDefaultOutput(t *testing.T) {
+	dir := filepath.Join("testdata", "platform")
+	for _, test := range defaultOutputTests {
+		got := defaultOutput(dir, "Day", test.goos, test.goarch)
+		if want := filepath.Join(dir, test.output); got != want {
+			t.Errorf("defaultOutput(%q, %q) = %q; expected %q", test.goos, test.goarch, got, want)
 		}
 	}
 }
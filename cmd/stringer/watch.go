@@ -0,0 +1,175 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -watch flag.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/stringer"
+)
+
+// watchInterval is how often -watch looks for changed files.
+const watchInterval = 500 * time.Millisecond
+
+// A watched package is one named by the arguments, kept loaded by watch.
+type watched struct {
+	dir    string            // Directory of the package.
+	files  []string          // Files named by the arguments, if they are not a directory.
+	pkg    *stringer.Package // The package as last loaded, or nil.
+	consts string            // The constants the code was last generated for.
+	err    string            // The error last reported, not to be repeated.
+}
+
+// watch generates the code for the packages named by args, as the command
+// would, and then again whenever the constants of the types it generates the
+// code for change, and never returns. The packages stay loaded: every
+// watchInterval, the files of each that changed are parsed again, and unless
+// the constants are the same the code is generated again. Errors, such as
+// those in a file saved half-edited, are reported without ending the watch.
+func (c *command) watch(args, types []string, outputName string) {
+	var packages []*watched
+	typesOf := func(*stringer.Package) []string {
+		return types
+	}
+	switch {
+	case *allFlag:
+		if outputName != "" {
+			log.Fatalf("-output does not apply with -all")
+		}
+		typesOf = func(pkg *stringer.Package) []string {
+			return pkg.Marked()
+		}
+		for _, dir := range c.packageDirs(args) {
+			packages = append(packages, &watched{dir: dir})
+		}
+	case len(args) == 1 && !isPattern(args[0]) && isDirectory(args[0]):
+		packages = []*watched{{dir: args[0]}}
+	case !isPattern(args[0]) && !isDirectory(args[0]):
+		packages = []*watched{{dir: filepath.Dir(args[0]), files: args}}
+	default:
+		if outputName != "" {
+			log.Fatalf("-output applies only to a single package")
+		}
+		typesOf = func(pkg *stringer.Package) []string {
+			return pkg.Declared(types)
+		}
+		for _, dir := range c.packageDirs(args) {
+			packages = append(packages, &watched{dir: dir})
+		}
+	}
+	for ; ; time.Sleep(watchInterval) {
+		for _, w := range packages {
+			if err := w.update(c, typesOf, outputName); err != nil {
+				if err.Error() != w.err {
+					report(w.dir, err)
+				}
+				w.err = err.Error()
+				continue
+			}
+			w.err = ""
+		}
+	}
+}
+
+// update loads the package again, parsing only the files that changed, and if
+// the constants of its types have changed generates the code for them.
+func (w *watched) update(c *command, typesOf func(*stringer.Package) []string, outputName string) error {
+	var pkg *stringer.Package
+	var err error
+	switch {
+	case w.pkg != nil:
+		pkg, err = w.pkg.Reload(&c.opts)
+	case w.files != nil:
+		pkg, err = stringer.LoadFiles(w.files, &c.opts)
+	default:
+		pkg, err = stringer.Load(w.dir, &c.opts)
+	}
+	if err != nil {
+		return err
+	}
+	if pkg == w.pkg {
+		return nil
+	}
+	w.pkg = pkg
+	types := typesOf(pkg)
+	consts, err := constants(pkg, types, &c.opts)
+	if err != nil || consts == w.consts {
+		return err
+	}
+	j := &job{c: c, dir: w.dir, pkg: pkg}
+	for _, fileTypes := range c.files(types) {
+		if _, err = j.write(fileTypes, outputName); err != nil {
+			break
+		}
+	}
+	j.flush()
+	if err == nil {
+		w.consts = consts
+	}
+	return err
+}
+
+// constants returns a description of the constants of the named types of the
+// package, as named with opts, that changes whenever they do.
+func constants(pkg *stringer.Package, types []string, opts *stringer.Options) (string, error) {
+	var buf bytes.Buffer
+	for _, typeName := range types {
+		values, err := pkg.Values(typeName, opts)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "type %s\n", typeName)
+		for _, v := range values {
+			fmt.Fprintf(&buf, "%s %q %s %q\n", v.OriginalName(), v.Name(), v.String(), v.Doc())
+		}
+	}
+	return buf.String(), nil
+}
+
+// withoutWatch returns the command line args, parsed by the flag package,
+// without the -watch flag, so that the header of the generated file is the
+// same as it would be without -watch.
+func withoutWatch(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") || arg == "-" {
+			return append(out, args[i:]...)
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+		if name == "watch" {
+			continue
+		}
+		out = append(out, arg)
+		// A flag that is not boolean takes the next argument as its value
+		// unless it is given with =.
+		if f := flag.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) && i+1 < len(args) {
+			i++
+			out = append(out, args[i])
+		}
+	}
+	return out
+}
+
+// isBoolFlag reports whether the flag, like those defined by flag.Bool, needs
+// no value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
@@ -213,8 +213,26 @@ func DeleteNamedImport(fset *token.FileSet, f *ast.File, name, path string) (del
 				f.Decls = f.Decls[:len(f.Decls)-1]
 				i--
 				break
-			} else if len(gen.Specs) == 1 {
-				gen.Lparen = token.NoPos // drop parens
+			} else if len(gen.Specs) == 1 && gen.Specs[0].(*ast.ImportSpec).Doc == nil {
+				// Drop the parens, unless the spec left has a doc
+				// comment, such as the cgo preamble of import "C",
+				// which would then come between "import" and it.
+				gen.Lparen = token.NoPos
+			}
+			if j == 0 && gen.Lparen.IsValid() {
+				// We deleted the first entry; close the hole
+				// between the lparen and what now follows it.
+				next := gen.Specs[0].Pos()
+				for _, c := range f.Comments {
+					if gen.Lparen < c.Pos() && c.Pos() < next {
+						next = c.Pos()
+						break
+					}
+				}
+				lparen := fset.Position(gen.Lparen).Line
+				for line := fset.Position(next).Line; line > lparen+1; line-- {
+					fset.File(gen.Rparen).MergeLine(lparen + 1)
+				}
 			}
 			if j > 0 {
 				lastImpspec := gen.Specs[j-1].(*ast.ImportSpec)
@@ -904,6 +904,47 @@ import y "fmt"
 		out: `package main
 
 import y "fmt"
+`,
+	},
+	{
+		name: "import.19",
+		pkg:  "os",
+		in: `package main
+
+import (
+	"os"
+
+	// #include <stdio.h>
+	"C"
+)
+`,
+		out: `package main
+
+import (
+	// #include <stdio.h>
+	"C"
+)
+`,
+	},
+	{
+		name: "import.20",
+		pkg:  "os",
+		in: `package main
+
+import (
+	"os"
+	// #include <stdio.h>
+	"C"
+	"fmt"
+)
+`,
+		out: `package main
+
+import (
+	// #include <stdio.h>
+	"C"
+	"fmt"
+)
 `,
 	},
 }
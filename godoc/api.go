@@ -0,0 +1,221 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements the /api/pkg/ handler, which serves the
+// documentation of a package as JSON for programs to consume.
+
+package godoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"log"
+	"net/http"
+	pathpkg "path"
+)
+
+// apiPkgPrefix is the URL path under which the JSON API serves packages.
+const apiPkgPrefix = "/api/pkg/"
+
+// An apiPos is the position of a declaration: the name of its file, of the
+// form /src/<path>/<filename>, and its line (1-based).
+type apiPos struct {
+	Filename string
+	Line     int
+}
+
+// An apiPackage is the documentation of a package as the JSON API serves it.
+// Declarations are given as source, formatted as on the package page.
+type apiPackage struct {
+	ImportPath string
+	Name       string
+	Doc        string
+	Filenames  []string
+	Notes      map[string][]apiNote `json:",omitempty"`
+	Consts     []apiValue
+	Vars       []apiValue
+	Types      []apiType
+	Funcs      []apiFunc
+	Examples   []apiExample
+}
+
+type apiNote struct {
+	apiPos
+	UID  string
+	Body string
+}
+
+type apiValue struct {
+	apiPos
+	Names []string
+	Doc   string
+	Decl  string
+}
+
+type apiType struct {
+	apiPos
+	Name     string
+	Doc      string
+	Decl     string
+	Consts   []apiValue
+	Vars     []apiValue
+	Funcs    []apiFunc
+	Methods  []apiFunc
+	Examples []apiExample
+}
+
+type apiFunc struct {
+	apiPos
+	Name     string
+	Recv     string `json:",omitempty"`
+	Doc      string
+	Decl     string
+	Examples []apiExample
+}
+
+type apiExample struct {
+	Name   string // name of the item the example is for, followed by "_suffix" if any
+	Doc    string
+	Code   string
+	Output string
+}
+
+// serveAPIPackage serves the documentation of the package whose import path
+// follows apiPkgPrefix in the URL, as JSON. As on the package pages, the
+// form values m (only "all" and "methods" apply), GOOS, GOARCH and v select
+// what is documented. The only format is "json", which is the default.
+func (p *Presentation) serveAPIPackage(w http.ResponseWriter, r *http.Request) {
+	if format := r.FormValue("format"); format != "" && format != "json" {
+		http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return
+	}
+	relpath := pathpkg.Clean(r.URL.Path[len(apiPkgPrefix):])
+	abspath, err := p.pkgHandler.versionPath(r.FormValue("v"), relpath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	mode := p.GetPageInfoMode(r) & (NoFiltering | AllMethods)
+	if relpath == builtinPkgPath {
+		mode = NoFiltering | NoTypeAssoc
+	}
+	info := p.pkgHandler.GetPageInfo(abspath, relpath, mode, r.FormValue("GOOS"), r.FormValue("GOARCH"))
+	if info.Err == nil && info.PDoc == nil {
+		info.Err = errors.New("no Go package in " + abspath)
+	}
+	if info.Err != nil {
+		log.Print(info.Err)
+		http.Error(w, info.Err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(marshalJSON(p.apiPackage(info.FSet, info.PDoc, info.Examples, info.Notes)))
+}
+
+// apiPackage converts the documentation of a package to its JSON form.
+// Examples are associated with the package, types, functions and methods
+// they are for, as on the package page.
+func (p *Presentation) apiPackage(fset *token.FileSet, pdoc *doc.Package, examples []*doc.Example, notes map[string][]*doc.Note) *apiPackage {
+	examplesFor := func(name string) []apiExample {
+		list := []apiExample{}
+		for _, eg := range examples {
+			if stripExampleSuffix(eg.Name) != name {
+				continue
+			}
+			list = append(list, apiExample{
+				Name:   eg.Name,
+				Doc:    eg.Doc,
+				Code:   p.apiNode(fset, &printer.CommentedNode{Node: eg.Code, Comments: eg.Comments}),
+				Output: eg.Output,
+			})
+		}
+		return list
+	}
+	values := func(list []*doc.Value) []apiValue {
+		out := []apiValue{}
+		for _, v := range list {
+			out = append(out, apiValue{
+				apiPos: apiPosition(fset, v.Decl.Pos()),
+				Names:  v.Names,
+				Doc:    v.Doc,
+				Decl:   p.apiNode(fset, v.Decl),
+			})
+		}
+		return out
+	}
+	funcs := func(list []*doc.Func, typeName string) []apiFunc {
+		out := []apiFunc{}
+		for _, f := range list {
+			name := f.Name
+			if f.Recv != "" {
+				name = typeName + "_" + f.Name
+			}
+			out = append(out, apiFunc{
+				apiPos:   apiPosition(fset, f.Decl.Pos()),
+				Name:     f.Name,
+				Recv:     f.Recv,
+				Doc:      f.Doc,
+				Decl:     p.apiNode(fset, f.Decl),
+				Examples: examplesFor(name),
+			})
+		}
+		return out
+	}
+
+	pkg := &apiPackage{
+		ImportPath: pdoc.ImportPath,
+		Name:       pdoc.Name,
+		Doc:        pdoc.Doc,
+		Filenames:  pdoc.Filenames,
+		Consts:     values(pdoc.Consts),
+		Vars:       values(pdoc.Vars),
+		Types:      []apiType{},
+		Funcs:      funcs(pdoc.Funcs, ""),
+		Examples:   examplesFor(""),
+	}
+	for _, t := range pdoc.Types {
+		pkg.Types = append(pkg.Types, apiType{
+			apiPos:   apiPosition(fset, t.Decl.Pos()),
+			Name:     t.Name,
+			Doc:      t.Doc,
+			Decl:     p.apiNode(fset, t.Decl),
+			Consts:   values(t.Consts),
+			Vars:     values(t.Vars),
+			Funcs:    funcs(t.Funcs, ""),
+			Methods:  funcs(t.Methods, t.Name),
+			Examples: examplesFor(t.Name),
+		})
+	}
+	if len(notes) > 0 {
+		pkg.Notes = make(map[string][]apiNote)
+		for marker, list := range notes {
+			for _, n := range list {
+				pkg.Notes[marker] = append(pkg.Notes[marker], apiNote{
+					apiPos: apiPosition(fset, n.Pos),
+					UID:    n.UID,
+					Body:   n.Body,
+				})
+			}
+		}
+	}
+	return pkg
+}
+
+// apiNode returns the source of node, formatted as on the package page but
+// without HTML escaping.
+func (p *Presentation) apiNode(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	p.writeNode(&buf, fset, node)
+	return buf.String()
+}
+
+func apiPosition(fset *token.FileSet, pos token.Pos) apiPos {
+	position := fset.Position(pos)
+	return apiPos{Filename: position.Filename, Line: position.Line}
+}
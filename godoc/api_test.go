@@ -0,0 +1,121 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/godoc/vfs/mapfs"
+)
+
+func TestAPIPackage(t *testing.T) {
+	const src = `// Package p is for testing.
+package p
+
+// Max is the maximum.
+const Max = 10
+
+// T is a type.
+type T struct{}
+
+// NewT returns a T.
+func NewT() *T { return nil }
+
+// M does nothing.
+func (t *T) M() {}
+
+// BUG(gri): M should do something.
+`
+	const testSrc = `package p_test
+
+func ExampleT_M() {
+	// Output: hi
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "/src/p/p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFile, err := parser.ParseFile(fset, "/src/p/p_test.go", testSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &ast.Package{Name: "p", Files: map[string]*ast.File{"/src/p/p.go": file}}
+	pdoc := doc.New(pkg, "p", 0)
+	examples := doc.Examples(testFile)
+
+	c := NewCorpus(mapfs.New(nil))
+	p := NewPresentation(c)
+	got := p.apiPackage(fset, pdoc, examples, pdoc.Notes)
+
+	// Compare the JSON, which is what clients see.
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"ImportPath": "p",
+		"Name":       "p",
+		"Doc":        "Package p is for testing.\n",
+		"Filenames":  []interface{}{"/src/p/p.go"},
+		"Notes": map[string]interface{}{
+			"BUG": []interface{}{map[string]interface{}{
+				"Filename": "/src/p/p.go", "Line": 16.0, "UID": "gri", "Body": "M should do something.\n",
+			}},
+		},
+		"Consts": []interface{}{map[string]interface{}{
+			"Filename": "/src/p/p.go", "Line": 5.0,
+			"Names": []interface{}{"Max"},
+			"Doc":   "Max is the maximum.\n",
+			"Decl":  "const Max = 10",
+		}},
+		"Vars": []interface{}{},
+		"Types": []interface{}{map[string]interface{}{
+			"Filename": "/src/p/p.go", "Line": 8.0,
+			"Name":   "T",
+			"Doc":    "T is a type.\n",
+			"Decl":   "type T struct{}",
+			"Consts": []interface{}{},
+			"Vars":   []interface{}{},
+			"Funcs": []interface{}{map[string]interface{}{
+				"Filename": "/src/p/p.go", "Line": 11.0,
+				"Name":     "NewT",
+				"Doc":      "NewT returns a T.\n",
+				"Decl":     "func NewT() *T",
+				"Examples": []interface{}{},
+			}},
+			"Methods": []interface{}{map[string]interface{}{
+				"Filename": "/src/p/p.go", "Line": 14.0,
+				"Name": "M",
+				"Recv": "*T",
+				"Doc":  "M does nothing.\n",
+				"Decl": "func (t *T) M()",
+				"Examples": []interface{}{map[string]interface{}{
+					"Name":   "T_M",
+					"Doc":    "",
+					"Code":   "{\n    // Output: hi\n}",
+					"Output": "hi\n",
+				}},
+			}},
+			"Examples": []interface{}{},
+		}},
+		"Funcs":    []interface{}{},
+		"Examples": []interface{}{},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("apiPackage =\n%s\nwant\n%s", data, marshalJSON(want))
+	}
+}
@@ -33,6 +33,13 @@ type Corpus struct {
 	// order.
 	IndexFiles string
 
+	// IndexCacheFile optionally names a file in which the search index
+	// is kept between runs. If not empty, the indexer starts from the
+	// index in the file, if there is one built with the same options,
+	// and writes the index to it after each update, so that a restart
+	// only needs to index the directories that changed in between.
+	IndexCacheFile string
+
 	// IndexThrottle specifies the indexing throttle value
 	// between 0.0 and 1.0. At 0.0, the indexer always sleeps.
 	// At 1.0, the indexer never sleeps. Because 0.0 is useless
@@ -86,6 +93,14 @@ type Corpus struct {
 	// If nil, all directories are indexed if indexing is enabled.
 	IndexDirectory func(dir string) bool
 
+	// Versions optionally lists the names of other versions of the
+	// source tree, such as releases of a module or other workspaces,
+	// to serve side by side with it. The tree of each is bound in the
+	// file system at VersionsDir/<name>, with its packages under
+	// VersionsDir/<name>/src. They are left out of the directory tree
+	// and the search index.
+	Versions []string
+
 	testDir string // TODO(bradfitz,adg): migrate old godoc flag? looks unused.
 
 	// Send a value on this channel to trigger a metadata refresh.
@@ -58,6 +58,9 @@ func (b *treeBuilder) newDirTree(fset *token.FileSet, path, name string, depth i
 	if name == testdataDirName {
 		return nil
 	}
+	if depth > 0 && path == VersionsDir && len(b.c.Versions) > 0 {
+		return nil
+	}
 
 	if depth >= b.maxDepth {
 		// return a dummy directory so that the parent directory
@@ -0,0 +1,242 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements the linking of the identifiers that doc comments
+// mention to their documentation.
+
+package godoc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	pathpkg "path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A docLinker links the exported identifiers mentioned in the doc comments
+// of a package to their documentation: those the package declares, written
+// as T.Method, as pkg.Name with the name of the package, or as Name if it
+// could not be an ordinary word, and those of the packages it imports,
+// written as pkg.Name.
+type docLinker struct {
+	pkgName string
+	names   map[string]bool   // exported names declared, including T.Method
+	imports map[string]string // names of the packages imported to their paths; "" if ambiguous
+}
+
+// newDocLinker returns a docLinker for the package documented by pdoc, whose
+// imports are those of the files.
+func newDocLinker(pdoc *doc.Package, files map[string]*ast.File) *docLinker {
+	l := &docLinker{
+		pkgName: pdoc.Name,
+		names:   make(map[string]bool),
+		imports: make(map[string]string),
+	}
+	addValues := func(list []*doc.Value) {
+		for _, v := range list {
+			for _, name := range v.Names {
+				l.add(name)
+			}
+		}
+	}
+	addFuncs := func(list []*doc.Func) {
+		for _, f := range list {
+			l.add(f.Name)
+		}
+	}
+	addValues(pdoc.Consts)
+	addValues(pdoc.Vars)
+	addFuncs(pdoc.Funcs)
+	for _, t := range pdoc.Types {
+		l.add(t.Name)
+		addValues(t.Consts)
+		addValues(t.Vars)
+		addFuncs(t.Funcs)
+		if ast.IsExported(t.Name) {
+			for _, m := range t.Methods {
+				if ast.IsExported(m.Name) {
+					l.names[t.Name+"."+m.Name] = true
+				}
+			}
+		}
+	}
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			// The comments may refer to the package by the name it
+			// is likely to declare, or by the name it is imported as.
+			l.addImport(importPathToName(path), path)
+			if spec.Name != nil && spec.Name.Name != "_" && spec.Name.Name != "." {
+				l.addImport(spec.Name.Name, path)
+			}
+		}
+	}
+	return l
+}
+
+func (l *docLinker) add(name string) {
+	if ast.IsExported(name) {
+		l.names[name] = true
+	}
+}
+
+// addImport records that name refers to the package with the import path,
+// unless it refers to another one too.
+func (l *docLinker) addImport(name, path string) {
+	if old, ok := l.imports[name]; ok && old != path {
+		path = ""
+	}
+	l.imports[name] = path
+}
+
+// importPathToName returns the name that the package with the import path is
+// likely to declare: the last element of the path, leaving out a major
+// version, as in "/v2" or ".v2", and a "go-" prefix or "-go" suffix.
+func importPathToName(path string) string {
+	base := pathpkg.Base(path)
+	if isMajorVersion(base) && pathpkg.Dir(path) != "." {
+		base = pathpkg.Base(pathpkg.Dir(path))
+	}
+	if i := strings.LastIndex(base, ".v"); i > 0 && isMajorVersion(base[i+1:]) {
+		base = base[:i]
+	}
+	base = strings.TrimPrefix(base, "go-")
+	return strings.TrimSuffix(base, "-go")
+}
+
+// isMajorVersion reports whether s is a major version such as v2.
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// distinctive reports whether the exported name, written alone, is unlikely
+// to be an ordinary word, as "New", "Get" or "Reader" may be: past its first
+// letter it has an upper-case letter, a digit or an underscore.
+func distinctive(name string) bool {
+	for i, r := range name {
+		if i > 0 && (unicode.IsUpper(r) || unicode.IsDigit(r) || r == '_') {
+			return true
+		}
+	}
+	return false
+}
+
+// url returns the URL of the documentation of the identifier ident,
+// which may be qualified, or "" if it is not known.
+func (l *docLinker) url(ident string) string {
+	i := strings.IndexByte(ident, '.')
+	if i < 0 {
+		if l.names[ident] && distinctive(ident) {
+			return "#" + ident
+		}
+		return ""
+	}
+	if l.names[ident] {
+		return "#" + ident // T.Method
+	}
+	pkg, name := ident[:i], ident[i+1:]
+	if !ast.IsExported(name) {
+		return ""
+	}
+	if pkg == l.pkgName {
+		if l.names[name] {
+			return "#" + name
+		}
+		return ""
+	}
+	if path := l.imports[pkg]; path != "" {
+		return "/pkg/" + path + "/#" + name
+	}
+	return ""
+}
+
+// docIdentRx matches an identifier, optionally qualified.
+var docIdentRx = regexp.MustCompile(`[\pL_][\pL\pN_]*(\.[\pL_][\pL\pN_]*)?`)
+
+// linkHTML returns the HTML of a doc comment with the identifiers that l
+// knows linked to their documentation. Text that is already a link, and the
+// tags and entities of the HTML, are left alone.
+func (l *docLinker) linkHTML(html []byte) []byte {
+	var buf bytes.Buffer
+	inLink := false
+	for len(html) > 0 {
+		if html[0] == '<' {
+			end := bytes.IndexByte(html, '>')
+			if end < 0 {
+				end = len(html) - 1
+			}
+			tag := html[:end+1]
+			switch {
+			case bytes.HasPrefix(tag, []byte("<a ")) || bytes.Equal(tag, []byte("<a>")):
+				inLink = true
+			case bytes.Equal(tag, []byte("</a>")):
+				inLink = false
+			}
+			buf.Write(tag)
+			html = html[end+1:]
+			continue
+		}
+		end := bytes.IndexByte(html, '<')
+		if end < 0 {
+			end = len(html)
+		}
+		text := html[:end]
+		html = html[end:]
+		if inLink {
+			buf.Write(text)
+			continue
+		}
+		l.linkText(&buf, text)
+	}
+	return buf.Bytes()
+}
+
+// linkText writes text, HTML without tags, to buf with the identifiers that
+// l knows linked.
+func (l *docLinker) linkText(buf *bytes.Buffer, text []byte) {
+	last := 0
+	for _, m := range docIdentRx.FindAllIndex(text, -1) {
+		start, end := m[0], m[1]
+		if start > 0 && (text[start-1] == '&' || text[start-1] == '#') {
+			continue // entity
+		}
+		ident := string(text[start:end])
+		url := l.url(ident)
+		if url == "" {
+			// Try the first part of a qualified identifier on its own,
+			// as in "See T.fields".
+			if i := strings.IndexByte(ident, '.'); i >= 0 {
+				ident = ident[:i]
+				end = start + i
+				url = l.url(ident)
+			}
+		}
+		if url == "" {
+			continue
+		}
+		buf.Write(text[last:start])
+		buf.WriteString(`<a href="`)
+		buf.WriteString(url)
+		buf.WriteString(`">`)
+		buf.WriteString(ident)
+		buf.WriteString(`</a>`)
+		last = end
+	}
+	buf.Write(text[last:])
+}
@@ -0,0 +1,86 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestDocLinks(t *testing.T) {
+	const src = `package p
+
+import (
+	"io"
+
+	"example.com/go-foo"
+	yml "gopkg.in/yaml.v2"
+	"example.com/mod/v2"
+	"example.com/a/rand"
+	"example.com/b/rand"
+)
+
+const Max = 1
+
+const MaxSize = 2
+
+type Buffer struct{}
+
+func NewBuffer() *Buffer { return nil }
+
+func (b *Buffer) Write(p []byte) {}
+
+func (b *Buffer) reset() {}
+
+func helper() {}
+
+var _ io.Reader
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdoc := doc.New(&ast.Package{Name: "p", Files: map[string]*ast.File{"p.go": file}}, "p", 0)
+	l := newDocLinker(pdoc, map[string]*ast.File{"p.go": file})
+
+	for _, test := range []struct{ in, want string }{
+		{
+			// Names that could be ordinary words are linked only if
+			// qualified.
+			"<p>NewBuffer returns a Buffer of at most MaxSize or p.Max bytes.\n",
+			`<p><a href="#NewBuffer">NewBuffer</a> returns a Buffer of at most <a href="#MaxSize">MaxSize</a> or <a href="#Max">p.Max</a> bytes.` + "\n",
+		},
+		{
+			"<p>Use Buffer.Write, p.Buffer or io.Reader.\n",
+			`<p>Use <a href="#Buffer.Write">Buffer.Write</a>, <a href="#Buffer">p.Buffer</a> or <a href="/pkg/io/#Reader">io.Reader</a>.` + "\n",
+		},
+		{
+			// Packages are known by the names they are likely to
+			// declare, or are imported as.
+			"<p>See foo.Bar, yaml.Marshal, yml.Unmarshal and mod.New.\n",
+			`<p>See <a href="/pkg/example.com/go-foo/#Bar">foo.Bar</a>, <a href="/pkg/gopkg.in/yaml.v2/#Marshal">yaml.Marshal</a>, <a href="/pkg/gopkg.in/yaml.v2/#Unmarshal">yml.Unmarshal</a> and <a href="/pkg/example.com/mod/v2/#New">mod.New</a>.` + "\n",
+		},
+		{
+			// Unexported names, unknown packages and packages of
+			// ambiguous names are not linked; qualified names fall
+			// back to their first part.
+			"<p>See helper, NewBuffer.reset, fmt.Println, rand.Int and Max.\n",
+			`<p>See helper, <a href="#NewBuffer">NewBuffer</a>.reset, fmt.Println, rand.Int and Max.` + "\n",
+		},
+		{
+			// Links, tags and entities are left alone.
+			`<p>Read <a href="http://example.com/MaxSize">MaxSize</a> &amp; <b class="MaxSize">MaxSize</b>.`,
+			`<p>Read <a href="http://example.com/MaxSize">MaxSize</a> &amp; <b class="MaxSize"><a href="#MaxSize">MaxSize</a></b>.`,
+		},
+	} {
+		if got := string(l.linkHTML([]byte(test.in))); got != test.want {
+			t.Errorf("linkHTML(%q) =\n%q\nwant\n%q", test.in, got, test.want)
+		}
+	}
+}
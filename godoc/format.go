@@ -235,6 +235,14 @@ func lineSelection(text []byte) Selection {
 // consecutive occurrences of token sel in the Go src text.
 //
 func tokenSelection(src []byte, sel token.Token) Selection {
+	return tokenClassSelection(src, func(tok token.Token) bool { return tok == sel })
+}
+
+// tokenClassSelection returns, as a selection, the sequence of
+// consecutive occurrences of the tokens in the Go src text
+// for which class returns true.
+//
+func tokenClassSelection(src []byte, class func(token.Token) bool) Selection {
 	var s scanner.Scanner
 	fset := token.NewFileSet()
 	file := fset.AddFile("", fset.Base(), len(src))
@@ -246,7 +254,7 @@ func tokenSelection(src []byte, sel token.Token) Selection {
 				break
 			}
 			offs := file.Offset(pos)
-			if tok == sel {
+			if class(tok) {
 				seg = Segment{offs, offs + len(lit)}
 				break
 			}
@@ -332,6 +340,33 @@ func selectionTag(w io.Writer, text []byte, selections int) {
 	template.HTMLEscape(w, text)
 }
 
+// sourceTag is like selectionTag but for Go source with two more
+// selections: bit 3 for keywords and bit 4 for string and character
+// literals. Their segments get the "keyword" or "string" span class,
+// prefixed by "highlight-" and "selection-" as for comments.
+//
+func sourceTag(w io.Writer, text []byte, selections int) {
+	var class string
+	switch {
+	case selections&(1<<3) != 0:
+		class = "keyword"
+	case selections&(1<<4) != 0:
+		class = "string"
+	default:
+		selectionTag(w, text, selections)
+		return
+	}
+	if selections&(1<<1) != 0 {
+		class = "highlight-" + class
+	}
+	if selections&(1<<2) != 0 {
+		class = "selection-" + class
+	}
+	fmt.Fprintf(w, `<span class="%s">`, class)
+	template.HTMLEscape(w, text)
+	w.Write(endTag)
+}
+
 // FormatText HTML-escapes text and writes it to w.
 // Consecutive text segments are wrapped in HTML spans (with tags as
 // defined by startTags and endTag) as follows:
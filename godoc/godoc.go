@@ -72,11 +72,12 @@ func (p *Presentation) initFuncMap() {
 		"infoSnippet_html": p.infoSnippet_htmlFunc,
 
 		// formatting of AST nodes
-		"node":         p.nodeFunc,
-		"node_html":    p.node_htmlFunc,
-		"comment_html": comment_htmlFunc,
-		"comment_text": comment_textFunc,
-		"sanitize":     sanitizeFunc,
+		"node":                p.nodeFunc,
+		"node_html":           p.node_htmlFunc,
+		"comment_html":        p.comment_htmlFunc,
+		"comment_linked_html": p.comment_linked_htmlFunc,
+		"comment_text":        comment_textFunc,
+		"sanitize":            sanitizeFunc,
 
 		// support for URL attributes
 		"pkgLink":     pkgLinkFunc,
@@ -197,14 +198,32 @@ func (p *Presentation) node_htmlFunc(info *PageInfo, node interface{}, linkify b
 	return buf2.String()
 }
 
-func comment_htmlFunc(comment string) string {
+func (p *Presentation) comment_htmlFunc(comment string) string {
 	var buf bytes.Buffer
+	if p.DocMarkdown {
+		markdownToHTML(&buf, comment)
+		return buf.String()
+	}
 	// TODO(gri) Provide list of words (e.g. function parameters)
 	//           to be emphasized by ToHTML.
 	doc.ToHTML(&buf, comment, nil) // does html-escaping
 	return buf.String()
 }
 
+// comment_linked_htmlFunc returns the HTML of a doc comment of the package
+// of info, as comment_htmlFunc does, with the exported identifiers it mentions
+// linked to their documentation if p.DocLinks is set.
+func (p *Presentation) comment_linked_htmlFunc(info *PageInfo, comment string) string {
+	html := p.comment_htmlFunc(comment)
+	if !p.DocLinks || info.PDoc == nil {
+		return html
+	}
+	if info.docLinker == nil {
+		info.docLinker = newDocLinker(info.PDoc, info.pkgFiles)
+	}
+	return string(info.docLinker.linkHTML([]byte(html)))
+}
+
 // punchCardWidth is the number of columns of fixed-width
 // characters to assume when wrapping text.  Very few people
 // use terminals or cards smaller than 80 characters, so 80 it is.
@@ -291,6 +310,12 @@ type PageInfo struct {
 	PAst       map[string]*ast.File   // nil if no AST with package exports
 	IsMain     bool                   // true for package main
 	IsFiltered bool                   // true if results were filtered
+	pkgFiles   map[string]*ast.File   // files of PDoc, whose imports its comments may mention
+	docLinker  *docLinker             // links of comment_linked_html; computed on first use
+
+	// version info
+	Version  string   // name of the version shown; "" for the default one
+	Versions []string // versions that have the directory, "" for the default; nil if there are no others
 
 	// analysis info
 	TypeInfoIndex  map[string]int  // index of JSON datum for type T (if -analysis=type)
@@ -5,6 +5,7 @@
 package godoc
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -116,3 +117,32 @@ func TestSanitizeFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatGoSource(t *testing.T) {
+	const src = "package p // p\n\nvar s = \"a<b\" + 'c'\n"
+	for _, tc := range []struct {
+		highlight bool
+		want      string
+	}{
+		{
+			true,
+			`<span id="L1" class="ln">     1</span>	<span class="keyword">package</span> p <span class="comment">// p</span>` + "\n" +
+				`<span id="L2" class="ln">     2</span>	` + "\n" +
+				`<span id="L3" class="ln">     3</span>	<span class="keyword">var</span> s = <span class="string">&#34;a&lt;b&#34;</span> + <span class="string">&#39;c&#39;</span>` + "\n" +
+				`<span id="L4" class="ln">     4</span>	` + "\n",
+		},
+		{
+			false,
+			`<span id="L1" class="ln">     1</span>	package p <span class="comment">// p</span>` + "\n" +
+				`<span id="L2" class="ln">     2</span>	` + "\n" +
+				`<span id="L3" class="ln">     3</span>	var s = &#34;a&lt;b&#34; + &#39;c&#39;` + "\n" +
+				`<span id="L4" class="ln">     4</span>	` + "\n",
+		},
+	} {
+		var buf bytes.Buffer
+		formatGoSource(&buf, []byte(src), nil, "", nil, tc.highlight)
+		if got := buf.String(); got != tc.want {
+			t.Errorf("formatGoSource(highlight=%v) =\n%s\nwant\n%s", tc.highlight, got, tc.want)
+		}
+	}
+}
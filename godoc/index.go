@@ -308,6 +308,25 @@ func (h HitList) filter(pakname string) HitList {
 	return hh
 }
 
+// byImporters sorts a HitList by the number of packages importing the
+// packages of its PakRuns, most imported first.
+type byImporters struct {
+	HitList   HitList
+	Importers map[string]int
+}
+
+func (h byImporters) Len() int      { return len(h.HitList) }
+func (h byImporters) Swap(i, j int) { h.HitList[i], h.HitList[j] = h.HitList[j], h.HitList[i] }
+func (h byImporters) Less(i, j int) bool {
+	pi, pj := h.HitList[i].Pak, h.HitList[j].Pak
+	ri := h.Importers[dirPackagePath(pi.Path)]
+	rj := h.Importers[dirPackagePath(pj.Path)]
+	if ri == rj {
+		return pi.less(pj)
+	}
+	return ri > rj
+}
+
 // ----------------------------------------------------------------------------
 // AltWords
 
@@ -456,6 +475,8 @@ type Indexer struct {
 	exports       map[string]map[string]SpotKind // "net/http" => "ListenAndServe" => FuncDecl
 	curPkgExports map[string]SpotKind
 	idents        map[SpotKind]map[string][]Ident // kind => name => list of Idents
+	dirs          map[string]*dirIndex            // directory => what the index holds of it
+	dir           *dirIndex                       // directory of current file
 }
 
 func (x *Indexer) intern(s string) string {
@@ -517,6 +538,7 @@ func (x *Indexer) visitIdent(kind SpotKind, id *ast.Ident) {
 	}
 
 	x.stats.Spots++
+	x.dir.Stats.Spots++
 }
 
 func (x *Indexer) visitFieldList(kind SpotKind, flist *ast.FieldList) {
@@ -536,7 +558,9 @@ func (x *Indexer) visitSpec(kind SpotKind, spec ast.Spec) {
 		x.visitIdent(ImportDecl, n.Name)
 		if n.Path != nil {
 			if imp, err := strconv.Unquote(n.Path.Value); err == nil {
-				x.importCount[x.intern(imp)]++
+				imp = x.intern(imp)
+				x.importCount[imp]++
+				x.dir.ImportCount[imp]++
 			}
 		}
 
@@ -723,12 +747,18 @@ func isWhitelisted(filename string) bool {
 	return whitelisted[key]
 }
 
+// dirPackagePath returns the import path of the package in the directory
+// dirname.
+func dirPackagePath(dirname string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(dirname, "/src/"), "pkg/")
+}
+
 func (x *Indexer) indexDocs(dirname string, filename string, astFile *ast.File) {
 	pkgName := x.intern(astFile.Name.Name)
 	if pkgName == "main" {
 		return
 	}
-	pkgPath := x.intern(strings.TrimPrefix(strings.TrimPrefix(dirname, "/src/"), "pkg/"))
+	pkgPath := x.intern(dirPackagePath(dirname))
 	astPkg := ast.Package{
 		Name: pkgName,
 		Files: map[string]*ast.File{
@@ -829,6 +859,16 @@ func (x *Indexer) indexDocs(dirname string, filename string, astFile *ast.File)
 func (x *Indexer) indexGoFile(dirname string, filename string, file *token.File, astFile *ast.File) {
 	pkgName := astFile.Name.Name
 
+	// Record the imports of the package, for ranking packages by the
+	// number of packages importing them; those of tests don't count.
+	if !strings.HasSuffix(filename, "_test.go") {
+		for _, imp := range astFile.Imports {
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+				x.dir.Imports[x.intern(path)] = true
+			}
+		}
+	}
+
 	if x.c.IndexGoCode {
 		x.current = file
 		pak := x.lookupPackage(dirname, pkgName)
@@ -850,7 +890,7 @@ func (x *Indexer) indexGoFile(dirname string, filename string, file *token.File,
 	if _, ok := x.packagePath[ppKey]; !ok {
 		x.packagePath[ppKey] = make(map[string]bool)
 	}
-	pkgPath := x.intern(strings.TrimPrefix(strings.TrimPrefix(dirname, "/src/"), "pkg/"))
+	pkgPath := x.intern(dirPackagePath(dirname))
 	x.packagePath[ppKey][pkgPath] = true
 
 	// Merge in exported symbols found walking this file into
@@ -909,6 +949,7 @@ func (x *Indexer) visitFile(dirname string, fi os.FileInfo) {
 
 	x.throttle.Throttle()
 
+	x.dir = x.dirs[dirname]
 	x.curPkgExports = make(map[string]SpotKind)
 	file, fast := x.addFile(f, filename, goFile)
 	if file == nil {
@@ -923,6 +964,9 @@ func (x *Indexer) visitFile(dirname string, fi os.FileInfo) {
 	x.stats.Bytes += file.Size()
 	x.stats.Files++
 	x.stats.Lines += file.LineCount()
+	x.dir.Stats.Bytes += file.Size()
+	x.dir.Stats.Files++
+	x.dir.Stats.Lines += file.LineCount()
 }
 
 // indexOptions contains information that affects the contents of an index.
@@ -963,9 +1007,11 @@ type Index struct {
 	snippets    []*Snippet               // all snippets, indexed by snippet index
 	stats       Statistics
 	importCount map[string]int                 // package path ("net/http") => count
+	importers   map[string]int                 // package path ("net/http") => number of packages importing it
 	packagePath map[string]map[string]bool     // "template" => "text/template" => true
 	exports     map[string]map[string]SpotKind // "net/http" => "ListenAndServe" => FuncDecl
 	idents      map[SpotKind]map[string][]Ident
+	dirs        map[string]*dirIndex // directory => what the index holds of it
 	opts        indexOptions
 }
 
@@ -991,6 +1037,11 @@ func (c *Corpus) throttle() float64 {
 
 // NewIndex creates a new index for the .go files provided by the corpus.
 func (c *Corpus) NewIndex() *Index {
+	return c.newIndex(c.indexDirnames())
+}
+
+// newIndex creates a new index for the files in the directories dirnames.
+func (c *Corpus) newIndex(dirnames <-chan string) *Index {
 	// initialize Indexer
 	// (use some reasonably sized maps to start)
 	x := &Indexer{
@@ -1005,15 +1056,13 @@ func (c *Corpus) NewIndex() *Index {
 		packagePath: make(map[string]map[string]bool),
 		exports:     make(map[string]map[string]SpotKind),
 		idents:      make(map[SpotKind]map[string][]Ident, 4),
+		dirs:        make(map[string]*dirIndex),
 	}
 
 	// index all files in the directories given by dirnames
 	var wg sync.WaitGroup // outstanding ReadDir + visitFile
 	dirGate := make(chan bool, maxOpenDirs)
-	for dirname := range c.fsDirnames() {
-		if c.IndexDirectory != nil && !c.IndexDirectory(dirname) {
-			continue
-		}
+	for dirname := range dirnames {
 		dirGate <- true
 		wg.Add(1)
 		go func(dirname string) {
@@ -1025,6 +1074,9 @@ func (c *Corpus) NewIndex() *Index {
 				log.Printf("ReadDir(%q): %v; skipping directory", dirname, err)
 				return // ignore this directory
 			}
+			x.mu.Lock()
+			x.dirs[dirname] = newDirIndex(list)
+			x.mu.Unlock()
 			for _, fi := range list {
 				wg.Add(1)
 				go func(fi os.FileInfo) {
@@ -1061,17 +1113,7 @@ func (c *Corpus) NewIndex() *Index {
 		x.throttle.Throttle()
 	}
 	x.stats.Words = len(words)
-
-	// reduce the word list {canonical(w), w} into
-	// a list of AltWords runs {canonical(w), {w}}
-	alist := wlist.reduce(lessWordPair, newAltWords)
-
-	// convert alist into a map of alternative spellings
-	alts := make(map[string]*AltWords)
-	for i := 0; i < len(alist); i++ {
-		a := alist[i].(*AltWords)
-		alts[a.Canon] = a
-	}
+	alts := altSpellings(wlist)
 
 	// create text index
 	var suffixes *suffixarray.Index
@@ -1079,10 +1121,11 @@ func (c *Corpus) NewIndex() *Index {
 		suffixes = suffixarray.New(x.sources.Bytes())
 	}
 
-	// sort idents by the number of imports of their respective packages
+	// sort idents by the number of importers of their respective packages
+	importers := countImporters(x.dirs)
 	for _, idMap := range x.idents {
 		for _, ir := range idMap {
-			sort.Sort(byImportCount{ir, x.importCount})
+			sort.Sort(byImportCount{ir, importers})
 		}
 	}
 
@@ -1094,9 +1137,11 @@ func (c *Corpus) NewIndex() *Index {
 		snippets:    x.snippets,
 		stats:       x.stats,
 		importCount: x.importCount,
+		importers:   importers,
 		packagePath: x.packagePath,
 		exports:     x.exports,
 		idents:      x.idents,
+		dirs:        x.dirs,
 		opts: indexOptions{
 			Docs:       x.c.IndexDocs,
 			GoCode:     x.c.IndexGoCode,
@@ -1106,9 +1151,37 @@ func (c *Corpus) NewIndex() *Index {
 	}
 }
 
+// countImporters returns the number of the directories dirs whose packages
+// import each package path.
+func countImporters(dirs map[string]*dirIndex) map[string]int {
+	importers := make(map[string]int)
+	for _, d := range dirs {
+		for path := range d.Imports {
+			importers[path]++
+		}
+	}
+	return importers
+}
+
+// altSpellings returns the map of alternative spellings of the words in the
+// word list wlist of {canonical(w), w} pairs.
+func altSpellings(wlist RunList) map[string]*AltWords {
+	// reduce the word list {canonical(w), w} into
+	// a list of AltWords runs {canonical(w), {w}}
+	alist := wlist.reduce(lessWordPair, newAltWords)
+
+	// convert alist into a map of alternative spellings
+	alts := make(map[string]*AltWords)
+	for i := 0; i < len(alist); i++ {
+		a := alist[i].(*AltWords)
+		alts[a.Canon] = a
+	}
+	return alts
+}
+
 var ErrFileIndexVersion = errors.New("file index version out of date")
 
-const fileIndexVersion = 3
+const fileIndexVersion = 5
 
 // fileIndex is the subset of Index that's gob-encoded for use by
 // Index.Write and Index.Read.
@@ -1123,6 +1196,7 @@ type fileIndex struct {
 	PackagePath map[string]map[string]bool
 	Exports     map[string]map[string]SpotKind
 	Idents      map[SpotKind]map[string][]Ident
+	Dirs        map[string]*dirIndex
 	Opts        indexOptions
 }
 
@@ -1152,6 +1226,7 @@ func (x *Index) WriteTo(w io.Writer) (n int64, err error) {
 		PackagePath: x.packagePath,
 		Exports:     x.exports,
 		Idents:      x.idents,
+		Dirs:        x.dirs,
 		Opts:        x.opts,
 	}
 	if err := fx.Write(w); err != nil {
@@ -1195,6 +1270,8 @@ func (x *Index) ReadFrom(r io.Reader) (n int64, err error) {
 	x.packagePath = fx.PackagePath
 	x.exports = fx.Exports
 	x.idents = fx.Idents
+	x.dirs = fx.Dirs
+	x.importers = countImporters(x.dirs)
 	x.opts = fx.Opts
 	if fx.Fulltext {
 		x.fset = token.NewFileSet()
@@ -1222,6 +1299,12 @@ func (x *Index) ImportCount() map[string]int {
 	return x.importCount
 }
 
+// Importers returns a map from import paths to the number of packages
+// importing them.
+func (x *Index) Importers() map[string]int {
+	return x.importers
+}
+
 // PackagePath returns a map from short package name to a set
 // of full package path names that use that short package name.
 func (x *Index) PackagePath() map[string]map[string]bool {
@@ -1287,12 +1370,14 @@ func (x *Index) Lookup(query string) (*SearchResult, error) {
 		rslt.Hit, rslt.Alt = x.lookupWord(ident)
 		if rslt.Hit != nil {
 			// found a match - filter packages with same name
-			// for the list of packages called ident, if any
+			// for the list of packages called ident, if any,
+			// most imported first
 			rslt.Pak = rslt.Hit.Others.filter(ident)
+			sort.Stable(byImporters{rslt.Pak, x.importers})
 		}
 		for k, v := range x.idents {
 			const rsltLimit = 50
-			ids := byImportCount{v[ident], x.importCount}
+			ids := byImportCount{v[ident], x.importers}
 			rslt.Idents[k] = ids.top(rsltLimit)
 		}
 
@@ -1307,7 +1392,7 @@ func (x *Index) Lookup(query string) (*SearchResult, error) {
 			rslt.Hit = &LookupResult{decls, others}
 		}
 		for k, v := range x.idents {
-			ids := byImportCount{v[ident], x.importCount}
+			ids := byImportCount{v[ident], x.importers}
 			rslt.Idents[k] = ids.filter(pakname)
 		}
 
@@ -1461,6 +1546,21 @@ func (c *Corpus) fsDirnames() <-chan string {
 	return ch
 }
 
+// indexDirnames returns a channel sending the names of the directories
+// to index.
+func (c *Corpus) indexDirnames() <-chan string {
+	ch := make(chan string, 256)
+	go func() {
+		for dirname := range c.fsDirnames() {
+			if c.IndexDirectory == nil || c.IndexDirectory(dirname) {
+				ch <- dirname
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
 // CompatibleWith reports whether the Index x is compatible with the corpus
 // indexing options set in c.
 func (x *Index) CompatibleWith(c *Corpus) bool {
@@ -1503,14 +1603,29 @@ func (c *Corpus) ReadIndexFrom(r io.Reader) error {
 	return nil
 }
 
+// UpdateIndex brings the search index up to date with the file systems.
+// If the corpus already has a compatible index, only the directories whose
+// files changed since it was built are indexed again.
+// If c.IndexCacheFile is set, the updated index is written to it.
 func (c *Corpus) UpdateIndex() {
 	if c.Verbose {
 		log.Printf("updating index...")
 	}
 	start := time.Now()
-	index := c.NewIndex()
+	old, _ := c.CurrentIndex()
+	var index *Index
+	if old != nil && old.dirs != nil && old.CompatibleWith(c) {
+		index = c.reindex(old)
+	} else {
+		index = c.NewIndex()
+	}
 	stop := time.Now()
 	c.searchIndex.Set(index)
+	if c.IndexCacheFile != "" && index != old {
+		if err := c.writeIndexCache(index); err != nil {
+			log.Printf("error writing index cache %s: %v", c.IndexCacheFile, err)
+		}
+	}
 	if c.Verbose {
 		secs := stop.Sub(start).Seconds()
 		stats := index.Stats()
@@ -1540,6 +1655,13 @@ func (c *Corpus) RunIndexer() {
 		return
 	}
 
+	// start from the index of the last run, if it was kept
+	if c.IndexCacheFile != "" {
+		if err := c.readIndexCache(); err != nil && !os.IsNotExist(err) {
+			log.Printf("error reading index cache %s: %v", c.IndexCacheFile, err)
+		}
+	}
+
 	// Repeatedly update the package directory tree and index.
 	// TODO(bgarcia): Use fsnotify to only update when notified of a filesystem change.
 	for {
@@ -6,7 +6,12 @@ package godoc
 
 import (
 	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
@@ -134,6 +139,146 @@ func TestIndexWriteRead(t *testing.T) {
 	}
 }
 
+func TestIndexUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godoc-index-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, docs := range []bool{true, false} {
+		for _, goCode := range []bool{true, false} {
+			for _, fullText := range []bool{true, false} {
+				files := map[string]string{
+					"src/foo/foo.go": "// Package foo is an example.\npackage foo\n\nimport \"bar\"\n\n// Foo is stuff.\ntype Foo struct{}\n",
+					"src/bar/bar.go": "// Package bar is another example.\npackage bar\n\nfunc Bar() {}\n",
+					"src/old/old.go": "// Package old goes away.\npackage old\n\nimport \"bar\"\n\nfunc Old() {}\n",
+				}
+				newCorpus := func() *Corpus {
+					c := NewCorpus(mapfs.New(files))
+					c.IndexDocs = docs
+					c.IndexGoCode = goCode
+					c.IndexFullText = fullText
+					if err := c.initFSTree(); err != nil {
+						t.Fatal(err)
+					}
+					return c
+				}
+				t.Logf("docs, goCode, fullText = %v,%v,%v", docs, goCode, fullText)
+
+				c := newCorpus()
+				c.IndexCacheFile = filepath.Join(dir, "index")
+				c.UpdateIndex()
+				ix, _ := c.CurrentIndex()
+				if c.UpdateIndex(); !sameIndex(c, ix) {
+					t.Errorf("index of unchanged files was rebuilt")
+				}
+
+				// An index kept in the cache is up to date.
+				c2 := newCorpus()
+				c2.IndexCacheFile = c.IndexCacheFile
+				if err := c2.readIndexCache(); err != nil {
+					t.Fatal(err)
+				}
+				ix2, _ := c2.CurrentIndex()
+				if c2.UpdateIndex(); !sameIndex(c2, ix2) {
+					t.Errorf("index read from cache was rebuilt")
+				}
+
+				// Change a directory, add one and remove one.
+				files["src/foo/foo.go"] += "\n// New returns a Foo.\nfunc New() *Foo { return nil }\n"
+				files["src/baz/baz.go"] = "// Package baz is new.\npackage baz\n\nimport \"foo\"\n\nfunc Baz() {}\n"
+				delete(files, "src/old/old.go")
+				c.initFSTree()
+				c.UpdateIndex()
+				got, _ := c.CurrentIndex()
+				want := newCorpus().NewIndex()
+				compareIndexes(t, got, want)
+			}
+		}
+	}
+}
+
+// sameIndex reports whether ix is the current index of c.
+func sameIndex(c *Corpus, ix *Index) bool {
+	current, _ := c.CurrentIndex()
+	return current == ix
+}
+
+// compareIndexes checks that the indexes got and want hold the same entries.
+func compareIndexes(t *testing.T, got, want *Index) {
+	if !reflect.DeepEqual(got.Stats(), want.Stats()) {
+		t.Errorf("Stats = %#v; want %#v", got.Stats(), want.Stats())
+	}
+	if !reflect.DeepEqual(got.ImportCount(), want.ImportCount()) {
+		t.Errorf("ImportCount = %v; want %v", got.ImportCount(), want.ImportCount())
+	}
+	if !reflect.DeepEqual(got.Importers(), want.Importers()) {
+		t.Errorf("Importers = %v; want %v", got.Importers(), want.Importers())
+	}
+	if !reflect.DeepEqual(got.PackagePath(), want.PackagePath()) {
+		t.Errorf("PackagePath = %v; want %v", got.PackagePath(), want.PackagePath())
+	}
+	if !reflect.DeepEqual(got.Exports(), want.Exports()) {
+		t.Errorf("Exports = %v; want %v", got.Exports(), want.Exports())
+	}
+	if !reflect.DeepEqual(got.Idents(), want.Idents()) {
+		t.Errorf("Idents = %v; want %v", got.Idents(), want.Idents())
+	}
+	if g, w := indexAlts(got), indexAlts(want); !reflect.DeepEqual(g, w) {
+		t.Errorf("alts = %v; want %v", g, w)
+	}
+	if g, w := indexSpots(got), indexSpots(want); !reflect.DeepEqual(g, w) {
+		t.Errorf("spots = %q; want %q", g, w)
+	}
+	if (got.suffixes == nil) != (want.suffixes == nil) {
+		t.Fatalf("full text index = %v; want %v", got.suffixes != nil, want.suffixes != nil)
+	}
+	if got.suffixes != nil {
+		rx := regexp.MustCompile("func|package")
+		gn, g := got.LookupRegexp(rx, 100)
+		wn, w := want.LookupRegexp(rx, 100)
+		if gn != wn || !reflect.DeepEqual(g, w) {
+			t.Errorf("LookupRegexp = %d, %v; want %d, %v", gn, g, wn, w)
+		}
+	}
+}
+
+// indexAlts returns the sorted alternative spellings of the words of ix.
+func indexAlts(ix *Index) map[string][]string {
+	alts := make(map[string][]string)
+	for canon, a := range ix.alts {
+		alts[canon] = append([]string(nil), a.Alts...)
+		sort.Strings(alts[canon])
+	}
+	return alts
+}
+
+// indexSpots returns the spots of each word of ix, with their snippets.
+func indexSpots(ix *Index) map[string][]string {
+	spots := make(map[string][]string)
+	for w, r := range ix.words {
+		for _, h := range []HitList{r.Decls, r.Others} {
+			for _, p := range h {
+				for _, f := range p.Files {
+					for _, g := range f.Groups {
+						for _, info := range g {
+							s := fmt.Sprintf("%s %s %v", f.File.Path(), p.Pak.Name, info.Kind())
+							if info.IsIndex() {
+								s += " " + ix.Snippet(info.Lori()).Text
+							} else {
+								s += fmt.Sprintf(" line %d", info.Lori())
+							}
+							spots[w] = append(spots[w], s)
+						}
+					}
+				}
+			}
+		}
+	}
+	return spots
+}
+
 func testIndex(t *testing.T, c *Corpus, ix *Index) {
 	if _, ok := ix.words["Skip"]; ok {
 		t.Errorf("the word Skip was found; expected it to be skipped")
@@ -257,6 +402,51 @@ func checkIdents(t *testing.T, c *Corpus, ix *Index) {
 	}
 }
 
+func TestLookupRanking(t *testing.T) {
+	// hobby/http is imported by more files than net/http, but net/http by
+	// more packages; imports of tests don't count.
+	files := map[string]string{
+		"src/net/http/http.go":   "// Package http serves HTTP.\npackage http\n",
+		"src/hobby/http/http.go": "// Package http is a toy.\npackage http\n",
+	}
+	for _, p := range []string{"a", "b", "c"} {
+		files["src/"+p+"/"+p+".go"] = "package " + p + "\n\nimport _ \"net/http\"\n"
+	}
+	for i := 0; i < 4; i++ {
+		files[fmt.Sprintf("src/d/d%d.go", i)] = "package d\n\nimport _ \"hobby/http\"\n"
+	}
+	for _, p := range []string{"e", "f", "g"} {
+		files["src/"+p+"/"+p+"_test.go"] = "package " + p + "\n\nimport _ \"hobby/http\"\n"
+	}
+	c := NewCorpus(mapfs.New(files))
+	if err := c.initFSTree(); err != nil {
+		t.Fatal(err)
+	}
+	ix := c.NewIndex()
+
+	if got, want := ix.Importers(), map[string]int{"net/http": 3, "hobby/http": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Importers = %v; want %v", got, want)
+	}
+	rslt, err := ix.Lookup("http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, id := range rslt.Idents[PackageClause] {
+		paths = append(paths, id.Path)
+	}
+	if want := []string{"net/http", "hobby/http"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("packages found = %v; want %v", paths, want)
+	}
+	paths = nil
+	for _, p := range rslt.Pak {
+		paths = append(paths, p.Pak.Path)
+	}
+	if want := []string{"/src/net/http", "/src/hobby/http"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("package hits = %v; want %v", paths, want)
+	}
+}
+
 func TestIdentResultSort(t *testing.T) {
 	ic := map[string]int{
 		"/a/b/pkg1": 10,
@@ -0,0 +1,186 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements the rendering of the subset of Markdown that
+// Presentation.DocMarkdown enables in doc comments: fenced code blocks,
+// lists, and links. Everything else is left to go/doc.
+
+package godoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// markdownToHTML converts comment text to HTML as doc.ToHTML does, except
+// that lines between ``` fences are preformatted, runs of lines beginning
+// with list markers (-, *, + or a number followed by . or ) ) after a blank
+// line are lists, and [text](url) is a link.
+func markdownToHTML(w io.Writer, text string) {
+	lines := strings.SplitAfter(text, "\n")
+	var plain []string // lines left to doc.ToHTML
+	flush := func() {
+		if len(plain) > 0 {
+			w.Write(markdownPlainHTML(strings.Join(plain, "")))
+			plain = nil
+		}
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isFence(line):
+			flush()
+			i++
+			io.WriteString(w, "<pre>")
+			for ; i < len(lines) && !isFence(lines[i]); i++ {
+				template.HTMLEscape(w, []byte(lines[i]))
+			}
+			io.WriteString(w, "</pre>\n")
+			i++ // closing fence
+		case listMarker(line) != "" && (i == 0 || isBlankLine(lines[i-1])):
+			flush()
+			i = writeList(w, lines, i)
+		default:
+			plain = append(plain, line)
+			i++
+		}
+	}
+	flush()
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "```")
+}
+
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// listMarker returns the list marker beginning line, after any indentation,
+// with the blank after it, or "" if there is none.
+func listMarker(line string) string {
+	s := strings.TrimLeft(line, " \t")
+	if len(s) >= 2 && strings.IndexByte("-*+", s[0]) >= 0 && (s[1] == ' ' || s[1] == '\t') {
+		return s[:2]
+	}
+	i := 0
+	for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(s) && (s[i] == '.' || s[i] == ')') && (s[i+1] == ' ' || s[i+1] == '\t') {
+		return s[:i+2]
+	}
+	return ""
+}
+
+// writeList writes the list beginning at lines[start] and returns the index
+// of the line after it. A line with a list marker begins an item; other
+// lines continue it. The list ends at a blank line not followed by another
+// item.
+func writeList(w io.Writer, lines []string, start int) int {
+	tag := "ul"
+	if m := listMarker(lines[start]); m[0] >= '0' && m[0] <= '9' {
+		tag = "ol"
+	}
+	var items []string
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if isBlankLine(line) {
+			j := i + 1
+			for j < len(lines) && isBlankLine(lines[j]) {
+				j++
+			}
+			if j == len(lines) || listMarker(lines[j]) == "" {
+				break
+			}
+			i = j
+			continue
+		}
+		s := strings.TrimLeft(line, " \t")
+		if m := listMarker(line); m != "" {
+			items = append(items, s[len(m):])
+		} else {
+			items[len(items)-1] += s
+		}
+		i++
+	}
+
+	fmt.Fprintf(w, "<%s>\n", tag)
+	for _, item := range items {
+		io.WriteString(w, "<li>")
+		w.Write(markdownInlineHTML(item))
+		io.WriteString(w, "</li>\n")
+	}
+	fmt.Fprintf(w, "</%s>\n", tag)
+	return i
+}
+
+// markdownLinkRx matches a Markdown link, [text](url).
+var markdownLinkRx = regexp.MustCompile(`\[([^\[\]\n]+)\]\(([^()\s]+)\)`)
+
+// markdownPlainHTML converts text to HTML with doc.ToHTML, turning its
+// Markdown links into HTML ones. As doc.ToHTML would escape them, the links
+// are replaced by placeholders, which it leaves alone, and put back after.
+func markdownPlainHTML(text string) []byte {
+	var links [][]byte
+	text = markdownLinkRx.ReplaceAllStringFunc(text, func(s string) string {
+		m := markdownLinkRx.FindStringSubmatch(s)
+		if !safeLinkURL(m[2]) {
+			return s
+		}
+		var buf bytes.Buffer
+		buf.WriteString(`<a href="`)
+		template.HTMLEscape(&buf, []byte(m[2]))
+		buf.WriteString(`">`)
+		template.HTMLEscape(&buf, []byte(m[1]))
+		buf.WriteString(`</a>`)
+		links = append(links, buf.Bytes())
+		return linkPlaceholder(len(links) - 1)
+	})
+
+	var buf bytes.Buffer
+	doc.ToHTML(&buf, text, nil)
+	out := buf.Bytes()
+	for i, link := range links {
+		out = bytes.Replace(out, []byte(linkPlaceholder(i)), link, 1)
+	}
+	return out
+}
+
+// linkPlaceholder returns the placeholder for the ith link of a text, made of
+// characters from the private use area, which doc.ToHTML neither escapes nor
+// takes for parts of words or URLs.
+func linkPlaceholder(i int) string {
+	return fmt.Sprintf("\ue000%d\ue001", i)
+}
+
+// markdownInlineHTML converts the text of a list item to HTML as
+// markdownPlainHTML does, but without wrapping it in a paragraph.
+// Depending on the version of go/doc, the paragraph may not be closed.
+func markdownInlineHTML(text string) []byte {
+	out := bytes.TrimSpace(markdownPlainHTML(strings.TrimSpace(text)))
+	out = bytes.TrimPrefix(out, []byte("<p>"))
+	out = bytes.TrimSuffix(out, []byte("</p>"))
+	return bytes.TrimSpace(out)
+}
+
+// safeLinkURL reports whether url may be the target of a link: a relative
+// URL, or one with a scheme that cannot run script.
+func safeLinkURL(url string) bool {
+	i := strings.IndexAny(url, ":/?#")
+	if i < 0 || url[i] != ':' {
+		return true
+	}
+	switch strings.ToLower(url[:i]) {
+	case "http", "https", "ftp", "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"bytes"
+	"go/doc"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	// plain returns the HTML of go/doc for text, which differs between
+	// versions of Go.
+	plain := func(text string) string {
+		var buf bytes.Buffer
+		doc.ToHTML(&buf, text, nil)
+		return buf.String()
+	}
+	// links replaces the placeholders for links in plain text.
+	links := strings.NewReplacer(
+		"LINK1", `<a href="https://golang.org/ref/spec">the spec</a>`,
+		"LINK2", `<a href="#Foo">this</a>`,
+	)
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain",
+			in:   "Package p does things.\n\n\tcode()\n",
+			want: plain("Package p does things.\n\n\tcode()\n"),
+		},
+		{
+			name: "link",
+			in:   "See [the spec](https://golang.org/ref/spec) and [this](#Foo).\n",
+			want: plain("See LINK1 and LINK2.\n"),
+		},
+		{
+			name: "unsafe link",
+			in:   "A [trap](javascript:alert).\n",
+			want: plain("A [trap](javascript:alert).\n"),
+		},
+		{
+			name: "fence",
+			in:   "Use it so:\n\n```go\nif a < b {\n\tf()\n}\n```\n\nDone.\n",
+			want: plain("Use it so:\n\n") + "<pre>if a &lt; b {\n\tf()\n}\n</pre>\n" + plain("\nDone.\n"),
+		},
+		{
+			name: "unordered list",
+			in:   "Options:\n\n - one\n - two,\n   continued\n\nAfter.\n",
+			want: plain("Options:\n\n") + "<ul>\n<li>one</li>\n<li>two,\ncontinued</li>\n</ul>\n" + plain("\nAfter.\n"),
+		},
+		{
+			name: "ordered list",
+			in:   "1. first, see [x](http://x.org)\n\n2) second\n",
+			want: "<ol>\n<li>first, see <a href=\"http://x.org\">x</a></li>\n<li>second</li>\n</ol>\n",
+		},
+		{
+			name: "marker within paragraph",
+			in:   "Subtract\n- one\n",
+			want: plain("Subtract\n- one\n"),
+		},
+	} {
+		tc.want = links.Replace(tc.want)
+		var buf bytes.Buffer
+		markdownToHTML(&buf, tc.in)
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s: markdownToHTML(%q) =\n%q\nwant\n%q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
@@ -40,13 +40,18 @@ func (p *Presentation) ServeError(w http.ResponseWriter, r *http.Request, relpat
 		Title:    "File " + relpath,
 		Subtitle: relpath,
 		Body:     applyTemplate(p.ErrorHTML, "errorHTML", err), // err may contain an absolute path!
-		Share:    allowShare(r),
+		Share:    p.allowShare(r),
 	})
 }
 
 var onAppengine = false // overriden in appengine.go when on app engine
 
-func allowShare(r *http.Request) bool {
+// allowShare reports whether the page served for r may show the buttons that
+// share code through the golang.org playground.
+func (p *Presentation) allowShare(r *http.Request) bool {
+	if p.DisableShare {
+		return false
+	}
 	if !onAppengine {
 		return true
 	}
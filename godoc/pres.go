@@ -49,6 +49,20 @@ type Presentation struct {
 	ShowExamples   bool
 	DeclLinks      bool
 
+	// DisableShare hides the buttons that share code through the
+	// golang.org playground, for instance when the playground runs
+	// programs elsewhere.
+	DisableShare bool
+
+	// DocLinks links the exported identifiers that doc comments mention
+	// to their documentation, in HTML.
+	DocLinks bool
+
+	// DocMarkdown enables the rendering of a subset of Markdown in doc
+	// comments in HTML: fenced code blocks, lists, and [text](url) links.
+	// Plain-text output is unaffected.
+	DocMarkdown bool
+
 	// SrcMode outputs source code instead of documentation in command-line mode.
 	SrcMode bool
 	// HTMLMode outputs HTML instead of plain text in command-line mode.
@@ -134,6 +148,7 @@ func NewPresentation(c *Corpus) *Presentation {
 	p.mux.HandleFunc("/", p.ServeFile)
 	p.mux.HandleFunc("/search", p.HandleSearch)
 	p.mux.HandleFunc("/opensearch.xml", p.serveSearchDesc)
+	p.mux.HandleFunc(apiPkgPrefix, p.serveAPIPackage)
 	return p
 }
 
@@ -0,0 +1,398 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements the incremental update of the search index, and the
+// index cache that keeps it between runs.
+//
+// An index records for each directory it indexed a fingerprint of the files
+// in it, and what those files contributed to its statistics and import
+// counts, and the packages they import. To update the index, the directories
+// whose fingerprints changed are indexed again on their own, and the result
+// is merged into the index without the entries of the old versions of these
+// directories.
+
+package godoc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"go/token"
+	"hash/fnv"
+	"index/suffixarray"
+	"io/ioutil"
+	"log"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+)
+
+// A dirIndex describes what an index holds of a directory.
+type dirIndex struct {
+	Fingerprint uint64          // of the files in the directory
+	Stats       Statistics      // of the files indexed; Words is not used
+	ImportCount map[string]int  // imports of the files indexed
+	Imports     map[string]bool // paths imported by the package, not its tests
+}
+
+func newDirIndex(list []os.FileInfo) *dirIndex {
+	return &dirIndex{
+		Fingerprint: fingerprint(list),
+		ImportCount: make(map[string]int),
+		Imports:     make(map[string]bool),
+	}
+}
+
+// fingerprint returns a hash of the names, sizes and modification times of
+// the files in list, a directory listing.
+func fingerprint(list []os.FileInfo) uint64 {
+	var files []os.FileInfo
+	for _, fi := range list {
+		if !fi.IsDir() {
+			files = append(files, fi)
+		}
+	}
+	sort.Sort(byName(files))
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, fi := range files {
+		h.Write([]byte(fi.Name()))
+		h.Write([]byte{0})
+		binary.LittleEndian.PutUint64(buf[:], uint64(fi.Size()))
+		h.Write(buf[:])
+		binary.LittleEndian.PutUint64(buf[:], uint64(fi.ModTime().UnixNano()))
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+type byName []os.FileInfo
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i].Name() < s[j].Name() }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// reindex returns the index x updated to the current contents of the
+// directories to index, or x itself if none of them changed.
+func (c *Corpus) reindex(x *Index) *Index {
+	fingerprints := make(map[string]uint64)
+	for dirname := range c.indexDirnames() {
+		list, err := c.fs.ReadDir(dirname)
+		if err != nil {
+			continue // newIndex would skip it, too
+		}
+		fingerprints[dirname] = fingerprint(list)
+	}
+
+	stale := make(map[string]bool) // directories to remove from x
+	for dirname, d := range x.dirs {
+		if fp, ok := fingerprints[dirname]; !ok || fp != d.Fingerprint {
+			stale[dirname] = true
+		}
+	}
+	var changed []string // directories to index again
+	for dirname, fp := range fingerprints {
+		if d, ok := x.dirs[dirname]; !ok || fp != d.Fingerprint {
+			changed = append(changed, dirname)
+		}
+	}
+	if len(stale) == 0 && len(changed) == 0 {
+		return x
+	}
+	if c.Verbose {
+		log.Printf("indexing %d changed directories", len(changed))
+	}
+
+	ch := make(chan string, len(changed))
+	for _, dirname := range changed {
+		ch <- dirname
+	}
+	close(ch)
+	return x.merge(stale, c.newIndex(ch))
+}
+
+// merge returns a new index that holds the entries of x, except those of the
+// directories stale, and those of y, an index of other directories built
+// with the same options.
+func (x *Index) merge(stale map[string]bool, y *Index) *Index {
+	stalePkgs := make(map[string]bool) // import paths of the stale directories
+	for dirname := range stale {
+		stalePkgs[dirPackagePath(dirname)] = true
+	}
+
+	// Words and snippets. Only declarations have snippets; the snippets
+	// kept are numbered anew, so that those of removed directories do
+	// not accumulate.
+	var snippets []*Snippet
+	renumber := func(list []*Snippet) func(int) int {
+		index := make(map[int]int)
+		return func(i int) int {
+			j, ok := index[i]
+			if !ok {
+				j = len(snippets)
+				snippets = append(snippets, list[i])
+				index[i] = j
+			}
+			return j
+		}
+	}
+	words := make(map[string]*LookupResult, len(x.words))
+	xsnippet := renumber(x.snippets)
+	for w, r := range x.words {
+		decls := r.Decls.filterDirs(stale).withSnippets(xsnippet)
+		others := r.Others.filterDirs(stale)
+		if len(decls) > 0 || len(others) > 0 {
+			words[w] = &LookupResult{Decls: decls, Others: others}
+		}
+	}
+	ysnippet := renumber(y.snippets)
+	for w, r := range y.words {
+		decls := r.Decls.withSnippets(ysnippet)
+		if xr, ok := words[w]; ok {
+			words[w] = &LookupResult{
+				Decls:  mergeHits(xr.Decls, decls),
+				Others: mergeHits(xr.Others, r.Others),
+			}
+		} else {
+			words[w] = &LookupResult{Decls: decls, Others: r.Others}
+		}
+	}
+	var wlist RunList
+	for w := range words {
+		wlist = append(wlist, &wordPair{canonical(w), w})
+	}
+
+	// Statistics and import counts.
+	stats := x.stats
+	importCount := make(map[string]int)
+	for path, n := range x.importCount {
+		importCount[path] = n
+	}
+	for dirname := range stale {
+		d := x.dirs[dirname]
+		stats.Bytes -= d.Stats.Bytes
+		stats.Files -= d.Stats.Files
+		stats.Lines -= d.Stats.Lines
+		stats.Spots -= d.Stats.Spots
+		for path, n := range d.ImportCount {
+			if importCount[path] -= n; importCount[path] <= 0 {
+				delete(importCount, path)
+			}
+		}
+	}
+	stats.Bytes += y.stats.Bytes
+	stats.Files += y.stats.Files
+	stats.Lines += y.stats.Lines
+	stats.Spots += y.stats.Spots
+	stats.Words = len(words)
+	for path, n := range y.importCount {
+		importCount[path] += n
+	}
+
+	// Directories.
+	dirs := make(map[string]*dirIndex, len(x.dirs))
+	for dirname, d := range x.dirs {
+		if !stale[dirname] {
+			dirs[dirname] = d
+		}
+	}
+	for dirname, d := range y.dirs {
+		dirs[dirname] = d
+	}
+	importers := countImporters(dirs)
+
+	// Packages, exports and identifiers.
+	packagePath := make(map[string]map[string]bool)
+	for name, paths := range x.packagePath {
+		for path := range paths {
+			if !stalePkgs[path] {
+				addPackagePath(packagePath, name, path)
+			}
+		}
+	}
+	for name, paths := range y.packagePath {
+		for path := range paths {
+			addPackagePath(packagePath, name, path)
+		}
+	}
+	exports := make(map[string]map[string]SpotKind)
+	for path, m := range x.exports {
+		if !stalePkgs[path] {
+			exports[path] = m
+		}
+	}
+	for path, m := range y.exports {
+		exports[path] = m
+	}
+	idents := make(map[SpotKind]map[string][]Ident)
+	for _, xy := range []*Index{x, y} {
+		for kind, m := range xy.idents {
+			if idents[kind] == nil {
+				idents[kind] = make(map[string][]Ident)
+			}
+			for name, list := range m {
+				for _, id := range list {
+					if xy == y || !stalePkgs[id.Path] {
+						idents[kind][name] = append(idents[kind][name], id)
+					}
+				}
+			}
+		}
+	}
+	for kind, m := range idents {
+		for name, list := range m {
+			if len(list) == 0 {
+				delete(m, name)
+				continue
+			}
+			sort.Sort(byImportCount{list, importers})
+		}
+		if len(m) == 0 {
+			delete(idents, kind)
+		}
+	}
+
+	z := &Index{
+		words:       words,
+		alts:        altSpellings(wlist),
+		snippets:    snippets,
+		stats:       stats,
+		importCount: importCount,
+		importers:   importers,
+		packagePath: packagePath,
+		exports:     exports,
+		idents:      idents,
+		dirs:        dirs,
+		opts:        x.opts,
+	}
+	if x.suffixes != nil && y.suffixes != nil {
+		z.fset, z.suffixes = mergeSources(x, y, stale)
+	}
+	return z
+}
+
+func addPackagePath(pp map[string]map[string]bool, name, path string) {
+	if pp[name] == nil {
+		pp[name] = make(map[string]bool)
+	}
+	pp[name][path] = true
+}
+
+// mergeSources returns the file set and text index of the sources of the
+// files of x, except those in the directories stale, and of those of y.
+func mergeSources(x, y *Index, stale map[string]bool) (*token.FileSet, *suffixarray.Index) {
+	// As in Indexer.addFile, the file set's base offset and the size of
+	// the sources must be in lock-step.
+	fset := token.NewFileSet()
+	var sources bytes.Buffer
+	add := func(ix *Index, keep func(filename string) bool) {
+		data := ix.suffixes.Bytes()
+		ix.fset.Iterate(func(f *token.File) bool {
+			if keep(f.Name()) {
+				src := data[f.Base() : f.Base()+f.Size()]
+				sources.WriteByte(0)
+				base := fset.Base()
+				if sources.Len() != base {
+					panic("internal error: file base incorrect")
+				}
+				sources.Write(src)
+				fset.AddFile(f.Name(), base, len(src)).SetLinesForContent(src)
+			}
+			return true
+		})
+	}
+	add(x, func(filename string) bool { return !stale[pathpkg.Dir(filename)] })
+	add(y, func(string) bool { return true })
+	return fset, suffixarray.New(sources.Bytes())
+}
+
+// filterDirs returns the PakRuns of h whose packages are not in the
+// directories stale.
+func (h HitList) filterDirs(stale map[string]bool) HitList {
+	var list HitList
+	for _, p := range h {
+		if !stale[p.Pak.Path] {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// withSnippets returns a copy of h with the snippet index i of each spot
+// that has one replaced by index(i).
+func (h HitList) withSnippets(index func(int) int) HitList {
+	list := make(HitList, len(h))
+	for i, p := range h {
+		files := make([]*FileRun, len(p.Files))
+		for j, f := range p.Files {
+			groups := make([]KindRun, len(f.Groups))
+			for k, g := range f.Groups {
+				run := make(KindRun, len(g))
+				for l, info := range g {
+					if info.IsIndex() {
+						info = makeSpotInfo(info.Kind(), index(info.Lori()), true)
+					}
+					run[l] = info
+				}
+				groups[k] = run
+			}
+			files[j] = &FileRun{f.File, groups}
+		}
+		list[i] = &PakRun{p.Pak, files}
+	}
+	return list
+}
+
+// mergeHits merges the HitLists a and b, which hold PakRuns of different
+// packages, sorted by package.
+func mergeHits(a, b HitList) HitList {
+	list := make(HitList, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0].Pak.less(b[0].Pak) {
+			list = append(list, a[0])
+			a = a[1:]
+		} else {
+			list = append(list, b[0])
+			b = b[1:]
+		}
+	}
+	list = append(list, a...)
+	return append(list, b...)
+}
+
+// readIndexCache sets the current index from the file c.IndexCacheFile.
+func (c *Corpus) readIndexCache() error {
+	f, err := os.Open(c.IndexCacheFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.ReadIndexFrom(f)
+}
+
+// writeIndexCache writes the index x to the file c.IndexCacheFile. The index
+// is written to a temporary file first, so that a failure does not leave a
+// truncated index behind.
+func (c *Corpus) writeIndexCache(x *Index) error {
+	f, err := ioutil.TempFile(filepath.Dir(c.IndexCacheFile), filepath.Base(c.IndexCacheFile))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	_, err = x.WriteTo(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.IndexCacheFile)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -22,6 +22,7 @@ import (
 	pathpkg "path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -135,6 +136,7 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 				m |= doc.AllMethods
 			}
 			info.PDoc = doc.New(pkg, pathpkg.Clean(relpath), m) // no trailing '/' in importpath
+			info.pkgFiles = files
 			if mode&NoTypeAssoc != 0 {
 				for _, t := range info.PDoc.Types {
 					info.PDoc.Consts = append(info.PDoc.Consts, t.Consts...)
@@ -243,7 +245,12 @@ func (h *handlerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	relpath := pathpkg.Clean(r.URL.Path[len(h.stripPrefix)+1:])
-	abspath := pathpkg.Join(h.fsRoot, relpath)
+	version := r.FormValue("v")
+	abspath, err := h.versionPath(version, relpath)
+	if err != nil {
+		h.p.ServeError(w, r, relpath, err)
+		return
+	}
 	mode := h.p.GetPageInfoMode(r)
 	if relpath == builtinPkgPath {
 		mode = NoFiltering | NoTypeAssoc
@@ -254,6 +261,8 @@ func (h *handlerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.p.ServeError(w, r, relpath, info.Err)
 		return
 	}
+	info.Version = version
+	info.Versions = h.versionsOf(relpath)
 
 	if mode&NoHTML != 0 {
 		h.p.ServeText(w, applyTemplate(h.p.PackageText, "packageText", info))
@@ -307,7 +316,7 @@ func (h *handlerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		info.TypeInfoIndex[ti.Name] = i
 	}
 
-	info.Share = allowShare(r)
+	info.Share = h.p.allowShare(r)
 	h.p.ServePage(w, Page{
 		Title:    title,
 		Tabtitle: tabtitle,
@@ -541,8 +550,14 @@ func (p *Presentation) serveTextFile(w http.ResponseWriter, r *http.Request, abs
 			fmt.Fprintf(&buf, "<span style='color: grey'>[%s]</span><br/>", htmlpkg.EscapeString(status))
 		}
 
+		// Highlight the syntax unless asked not to with hl=0.
+		highlight := true
+		if v := r.FormValue("hl"); v != "" {
+			highlight, _ = strconv.ParseBool(v)
+		}
+
 		buf.WriteString("<pre>")
-		formatGoSource(&buf, src, fi.Links, h, s)
+		formatGoSource(&buf, src, fi.Links, h, s, highlight)
 		buf.WriteString("</pre>")
 	} else {
 		buf.WriteString("<pre>")
@@ -555,14 +570,15 @@ func (p *Presentation) serveTextFile(w http.ResponseWriter, r *http.Request, abs
 		Title:    title + " " + relpath,
 		Tabtitle: relpath,
 		Body:     buf.Bytes(),
-		Share:    allowShare(r),
+		Share:    p.allowShare(r),
 	})
 }
 
-// formatGoSource HTML-escapes Go source text and writes it to w,
-// decorating it with the specified analysis links.
-//
-func formatGoSource(buf *bytes.Buffer, text []byte, links []analysis.Link, pattern string, selection Selection) {
+// formatGoSource writes the HTML of the Go source text to buf, with line
+// numbers, the given links, the pattern and selection highlighted, and its
+// comments, and if highlight is set also its keywords and literal strings,
+// marked with span classes.
+func formatGoSource(buf *bytes.Buffer, text []byte, links []analysis.Link, pattern string, selection Selection, highlight bool) {
 	// Emit to a temp buffer so that we can add line anchors at the end.
 	saved, buf := buf, new(bytes.Buffer)
 
@@ -586,7 +602,15 @@ func formatGoSource(buf *bytes.Buffer, text []byte, links []analysis.Link, patte
 		highlights = regexpSelection(text, pattern)
 	}
 
-	FormatSelections(buf, text, linkWriter, segmentIter, selectionTag, comments, highlights, selection)
+	if highlight {
+		keywords := tokenClassSelection(text, token.Token.IsKeyword)
+		literals := tokenClassSelection(text, func(tok token.Token) bool {
+			return tok == token.STRING || tok == token.CHAR
+		})
+		FormatSelections(buf, text, linkWriter, segmentIter, sourceTag, comments, highlights, selection, keywords, literals)
+	} else {
+		FormatSelections(buf, text, linkWriter, segmentIter, selectionTag, comments, highlights, selection)
+	}
 
 	// Now copy buf to saved, adding line anchors.
 
@@ -616,7 +640,7 @@ func (p *Presentation) serveDirectory(w http.ResponseWriter, r *http.Request, ab
 		Title:    "Directory " + relpath,
 		Tabtitle: relpath,
 		Body:     applyTemplate(p.DirlistHTML, "dirlistHTML", list),
-		Share:    allowShare(r),
+		Share:    p.allowShare(r),
 	})
 }
 
@@ -645,7 +669,7 @@ func (p *Presentation) ServeHTMLDoc(w http.ResponseWriter, r *http.Request, absp
 	page := Page{
 		Title:    meta.Title,
 		Subtitle: meta.Subtitle,
-		Share:    allowShare(r),
+		Share:    p.allowShare(r),
 	}
 
 	// evaluate as template if indicated
@@ -38,6 +38,7 @@ var files = []string{
 	"callgraph.html",
 	"codewalk.html",
 	"codewalkdir.html",
+	"dark.css",
 	"dirlist.html",
 	"error.html",
 	"example.html",
@@ -68,6 +69,7 @@ var files = []string{
 	"searchdoc.html",
 	"searchtxt.html",
 	"style.css",
+	"theme.css",
 }
 
 func main() {
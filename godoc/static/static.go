@@ -380,6 +380,93 @@ var Files = map[string]string{
 </tr>
 {{end}}
 </table>
+`,
+
+	"dark.css": `/*
+ * A dark theme for godoc. It overrides the colors of style.css; see
+ * theme.css for how to use it. Printed pages keep the light colors.
+ */
+@media screen {
+	body {
+		background-color: #1E1E1E;
+		color: #D4D4D4;
+	}
+	a,
+	.exampleHeading .text {
+		color: #6FA8DC;
+	}
+	.top-heading a,
+	div#blog .read a {
+		color: #D4D4D4;
+	}
+	div#topbar,
+	h2,
+	div#blog .read a,
+	div#playground {
+		background: #2D3A4A;
+	}
+	pre,
+	#content .code,
+	#content .playground {
+		background: #2A2A2A;
+	}
+	pre .comment {
+		color: #6A9955;
+	}
+	pre .keyword,
+	pre .highlight-keyword,
+	pre .selection-keyword,
+	pre .selection-highlight-keyword {
+		color: #569CD6;
+	}
+	pre .string,
+	pre .highlight-string,
+	pre .selection-string,
+	pre .selection-highlight-string {
+		color: #CE9178;
+	}
+	pre .highlight,
+	pre .highlight-comment,
+	pre .highlight-keyword,
+	pre .highlight-string,
+	pre .selection-highlight,
+	pre .selection-highlight-comment,
+	pre .selection-highlight-keyword,
+	pre .selection-highlight-string {
+		background: #5A5A00;
+	}
+	pre .selection,
+	pre .selection-comment,
+	pre .selection-keyword,
+	pre .selection-string {
+		background: #7A4A10;
+	}
+	pre .ln,
+	div#footer,
+	div#blog .when {
+		color: #888;
+	}
+	.alert {
+		color: #F47272;
+	}
+	div#menu > input {
+		background: #2A2A2A;
+		color: #D4D4D4;
+		border-color: #555;
+	}
+	div.play .output pre,
+	div#learn .output pre {
+		background: #2A2A1E;
+	}
+	hr {
+		border-top-color: #555;
+	}
+	#lowframe {
+		/* the frame is styled inline in godoc.html */
+		background-color: #1E1E1E !important;
+		border-top-color: #555 !important;
+	}
+}
 `,
 
 	"dirlist.html": `<!--
@@ -474,6 +561,7 @@ var Files = map[string]string{
 <link rel="search" type="application/opensearchdescription+xml" title="godoc" href="/opensearch.xml" />
 {{end}}
 <link rel="stylesheet" href="/lib/godoc/jquery.treeview.css">
+<link type="text/css" rel="stylesheet" href="/lib/godoc/theme.css">
 <script type="text/javascript">window.initFuncs = [];</script>
 </head>
 <body>
@@ -1592,6 +1680,18 @@ function cgAddChild(tree, ul, cgn) {
 	them to conflict with generated attributes (some of which
 	correspond to Go identifiers).
 -->
+{{if .Versions}}
+	<div id="pkg-versions">
+	Version:
+	{{range .Versions}}
+		{{if eq . $.Version}}
+			<b>{{if .}}{{html .}}{{else}}default{{end}}</b>
+		{{else}}
+			<a href="?v={{urlquery .}}">{{if .}}{{html .}}{{else}}default{{end}}</a>
+		{{end}}
+	{{end}}
+	</div>
+{{end}}
 {{with .PDoc}}
 	<script type='text/javascript'>
 	document.ANALYSIS_DATA = {{$.AnalysisData}};
@@ -1600,7 +1700,7 @@ function cgAddChild(tree, ul, cgn) {
 
 	{{if $.IsMain}}
 		{{/* command documentation */}}
-		{{comment_html .Doc}}
+		{{comment_linked_html $ .Doc}}
 	{{else}}
 		{{/* package documentation */}}
 		<div id="short-nav">
@@ -1625,7 +1725,7 @@ function cgAddChild(tree, ul, cgn) {
 			</div>
 			<div class="expanded">
 				<h2 class="toggleButton" title="Click to hide Overview section">Overview ▾</h2>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 			</div>
 		</div>
 		{{example_html $ ""}}
@@ -1735,14 +1835,14 @@ function cgAddChild(tree, ul, cgn) {
 			<h2 id="pkg-constants">Constants</h2>
 			{{range .}}
 				<pre>{{node_html $ .Decl true}}</pre>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 			{{end}}
 		{{end}}
 		{{with .Vars}}
 			<h2 id="pkg-variables">Variables</h2>
 			{{range .}}
 				<pre>{{node_html $ .Decl true}}</pre>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 			{{end}}
 		{{end}}
 		{{range .Funcs}}
@@ -1752,7 +1852,7 @@ function cgAddChild(tree, ul, cgn) {
 				<a class="permalink" href="#{{$name_html}}">&#xb6;</a>
 			</h2>
 			<pre>{{node_html $ .Decl true}}</pre>
-			{{comment_html .Doc}}
+			{{comment_linked_html $ .Doc}}
 			{{example_html $ .Name}}
 			{{callgraph_html $ "" .Name}}
 
@@ -1764,16 +1864,16 @@ function cgAddChild(tree, ul, cgn) {
 				<a class="permalink" href="#{{$tname_html}}">&#xb6;</a>
 			</h2>
 			<pre>{{node_html $ .Decl true}}</pre>
-			{{comment_html .Doc}}
+			{{comment_linked_html $ .Doc}}
 
 			{{range .Consts}}
 				<pre>{{node_html $ .Decl true}}</pre>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 			{{end}}
 
 			{{range .Vars}}
 				<pre>{{node_html $ .Decl true}}</pre>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 			{{end}}
 
 			{{example_html $ $tname}}
@@ -1786,7 +1886,7 @@ function cgAddChild(tree, ul, cgn) {
 					<a class="permalink" href="#{{$name_html}}">&#xb6;</a>
 				</h3>
 				<pre>{{node_html $ .Decl true}}</pre>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 				{{example_html $ .Name}}
 				{{callgraph_html $ "" .Name}}
 			{{end}}
@@ -1797,7 +1897,7 @@ function cgAddChild(tree, ul, cgn) {
 					<a class="permalink" href="#{{$tname_html}}.{{$name_html}}">&#xb6;</a>
 				</h3>
 				<pre>{{node_html $ .Decl true}}</pre>
-				{{comment_html .Doc}}
+				{{comment_linked_html $ .Doc}}
 				{{$name := printf "%s_%s" $tname .Name}}
 				{{example_html $ $name}}
 				{{callgraph_html $ .Recv .Name}}
@@ -1810,7 +1910,7 @@ function cgAddChild(tree, ul, cgn) {
 			<h2 id="pkg-note-{{$marker}}">{{noteTitle $marker | html}}s</h2>
 			<ul style="list-style: none; padding: 0;">
 			{{range .}}
-			<li><a href="{{posLink_url $ .}}" style="float: left;">&#x261e;</a> {{comment_html .Body}}</li>
+			<li><a href="{{posLink_url $ .}}" style="float: left;">&#x261e;</a> {{comment_linked_html $ .Body}}</li>
 			{{end}}
 			</ul>
 		{{end}}
@@ -2820,14 +2920,33 @@ pre {
 pre .comment {
 	color: #006600;
 }
+pre .keyword,
+pre .highlight-keyword,
+pre .selection-keyword,
+pre .selection-highlight-keyword {
+	color: #000080;
+	font-weight: bold;
+}
+pre .string,
+pre .highlight-string,
+pre .selection-string,
+pre .selection-highlight-string {
+	color: #A31515;
+}
 pre .highlight,
 pre .highlight-comment,
+pre .highlight-keyword,
+pre .highlight-string,
 pre .selection-highlight,
-pre .selection-highlight-comment {
+pre .selection-highlight-comment,
+pre .selection-highlight-keyword,
+pre .selection-highlight-string {
 	background: #FFFF00;
 }
 pre .selection,
-pre .selection-comment {
+pre .selection-comment,
+pre .selection-keyword,
+pre .selection-string {
 	background: #FF9632;
 }
 pre .ln {
@@ -3582,5 +3701,14 @@ a.error {
 		white-space: pre-wrap;
 	}
 }
+`,
+
+	"theme.css": `/*
+ * Overrides of the styles in style.css, applied after it on every page.
+ * This file is empty; a theme.css in the directory given to godoc with
+ * -templates replaces it. To use the dark theme, it may contain just
+ *
+ *	@import "dark.css";
+ */
 `,
 }
@@ -0,0 +1,54 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"fmt"
+	pathpkg "path"
+)
+
+// VersionsDir is the directory of the file system under which the trees of
+// the versions listed in Corpus.Versions are bound.
+const VersionsDir = "/versions"
+
+// hasVersion reports whether name is one of c.Versions.
+func (c *Corpus) hasVersion(name string) bool {
+	for _, v := range c.Versions {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// versionPath returns the directory of the file system that holds relpath,
+// a path relative to the root of h, in the version name of the source
+// tree, or in the default one if name is "".
+func (h *handlerServer) versionPath(name, relpath string) (string, error) {
+	if name == "" {
+		return pathpkg.Join(h.fsRoot, relpath), nil
+	}
+	if !h.c.hasVersion(name) {
+		return "", fmt.Errorf("unknown version %q", name)
+	}
+	return pathpkg.Join(VersionsDir, name, h.fsRoot, relpath), nil
+}
+
+// versionsOf returns the names of the versions of the source tree that have
+// a directory relpath, relative to the root of h, with "" for the default
+// one, or nil if the corpus has no other versions.
+func (h *handlerServer) versionsOf(relpath string) []string {
+	if len(h.c.Versions) == 0 {
+		return nil
+	}
+	var names []string
+	for _, name := range append([]string{""}, h.c.Versions...) {
+		abspath, _ := h.versionPath(name, relpath)
+		if fi, err := h.c.fs.Stat(abspath); err == nil && fi.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,64 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/godoc/vfs"
+	"golang.org/x/tools/godoc/vfs/mapfs"
+)
+
+func TestVersions(t *testing.T) {
+	fs := make(vfs.NameSpace)
+	fs.Bind("/", mapfs.New(map[string]string{
+		"src/lib/lib.go": "package lib",
+		"src/new/new.go": "package new",
+	}), "/", vfs.BindReplace)
+	fs.Bind(VersionsDir+"/v1", mapfs.New(map[string]string{
+		"src/lib/lib.go": "package lib",
+		"src/old/old.go": "package old",
+	}), "/", vfs.BindReplace)
+	c := NewCorpus(fs)
+	c.Versions = []string{"v1"}
+	h := NewPresentation(c).pkgHandler
+
+	for _, test := range []struct {
+		version, relpath string
+		want             string // "" if an error is wanted
+	}{
+		{"", "lib", "/src/lib"},
+		{"v1", "lib", "/versions/v1/src/lib"},
+		{"v2", "lib", ""},
+	} {
+		got, err := h.versionPath(test.version, test.relpath)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("versionPath(%q, %q) = %q, want error", test.version, test.relpath, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("versionPath(%q, %q) = %q, %v, want %q", test.version, test.relpath, got, err, test.want)
+		}
+	}
+
+	for relpath, want := range map[string][]string{
+		"lib":  {"", "v1"},
+		"new":  {""},
+		"old":  {"v1"},
+		"none": nil,
+	} {
+		if got := h.versionsOf(relpath); !reflect.DeepEqual(got, want) {
+			t.Errorf("versionsOf(%q) = %q, want %q", relpath, got, want)
+		}
+	}
+
+	c.Versions = nil
+	if got := h.versionsOf("lib"); got != nil {
+		t.Errorf("versionsOf(%q) without versions = %q, want nil", "lib", got)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the reading of the manifests in which projects list
+// their dependencies, so that findImportGoPath can prefer the packages of
+// those to others of the same name.
+
+package imports
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// manifests are the files, relative to the root of a project, that may list
+// its dependencies, in the order they are looked for, with the functions
+// parsing them.
+var manifests = []struct {
+	name  string
+	parse func(data []byte) depSet
+}{
+	{"go.mod", parseGoMod},
+	{filepath.Join("Godeps", "Godeps.json"), parseGodeps},
+	{filepath.Join("vendor", "vendor.json"), parseVendorJSON},
+}
+
+// A depSet holds the import paths of the dependencies of a project.
+// Each stands for the packages below it too.
+type depSet []string
+
+// has reports whether the package with the import path is in the set.
+func (deps depSet) has(importPath string) bool {
+	for _, dep := range deps {
+		if importPath == dep || strings.HasPrefix(importPath, dep+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// projectDeps returns the dependencies listed by the nearest manifest in the
+// directory of filename or above it, or nil if there is none.
+func projectDeps(filename string) depSet {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil
+	}
+	return dirDeps(filepath.Dir(abs))
+}
+
+// depsCache holds the dependencies found by dirDeps, by directory. Like the
+// package index, it is kept for the life of the process, so a manifest is
+// read only once.
+var depsCache struct {
+	sync.Mutex
+	m map[string]depSet
+}
+
+// dirDeps returns the dependencies listed by the nearest manifest in the
+// directory or above it, or nil if there is none.
+func dirDeps(dir string) depSet {
+	depsCache.Lock()
+	deps, ok := depsCache.m[dir]
+	depsCache.Unlock()
+	if ok {
+		return deps
+	}
+	deps = readDeps(dir)
+	depsCache.Lock()
+	if depsCache.m == nil {
+		depsCache.m = make(map[string]depSet)
+	}
+	depsCache.m[dir] = deps
+	depsCache.Unlock()
+	return deps
+}
+
+// readDeps returns the dependencies listed by the manifest in the directory,
+// or, if it has none, those that dirDeps finds for its parent.
+func readDeps(dir string) depSet {
+	for _, m := range manifests {
+		if data, err := ioutil.ReadFile(filepath.Join(dir, m.name)); err == nil {
+			return m.parse(data)
+		}
+	}
+	parent := filepath.Dir(dir)
+	if parent == dir {
+		return nil
+	}
+	return dirDeps(parent)
+}
+
+// parseGoMod returns the path of the module that a go.mod file declares, the
+// module paths that it requires, and those that it replaces others with,
+// other than directories.
+func parseGoMod(data []byte) depSet {
+	var deps depSet
+	block := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		if block != "" {
+			if f[0] == ")" {
+				block = ""
+				continue
+			}
+			// Within a block, a line is as it would be after the verb.
+			f = append([]string{block}, f...)
+		} else if (f[0] == "require" || f[0] == "replace") && len(f) == 2 && f[1] == "(" {
+			block = f[0]
+			continue
+		}
+		switch {
+		case f[0] == "module" && len(f) > 1, f[0] == "require" && len(f) > 1:
+			deps = append(deps, strings.Trim(f[1], `"`))
+		case f[0] == "replace":
+			// The target follows the arrow: a module path and a version,
+			// or a directory alone.
+			for i := 1; i+2 < len(f); i++ {
+				if f[i] == "=>" {
+					deps = append(deps, strings.Trim(f[i+1], `"`))
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// parseGodeps returns the import paths listed by a Godeps.json file of godep.
+func parseGodeps(data []byte) depSet {
+	var godeps struct {
+		Deps []struct {
+			ImportPath string
+		}
+	}
+	if err := json.Unmarshal(data, &godeps); err != nil {
+		return nil
+	}
+	var deps depSet
+	for _, dep := range godeps.Deps {
+		deps = append(deps, dep.ImportPath)
+	}
+	return deps
+}
+
+// parseVendorJSON returns the import paths listed by a vendor.json file of
+// govendor.
+func parseVendorJSON(data []byte) depSet {
+	var vendor struct {
+		Package []struct {
+			Path string
+		}
+	}
+	if err := json.Unmarshal(data, &vendor); err != nil {
+		return nil
+	}
+	var deps depSet
+	for _, pkg := range vendor.Package {
+		deps = append(deps, pkg.Path)
+	}
+	return deps
+}
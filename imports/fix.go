@@ -5,30 +5,47 @@
 package imports
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/build"
 	"go/parser"
 	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/ast/astutil"
 )
 
 // importToGroup is a list of functions which map from an import path to
-// a group number.
-var importToGroup = []func(importPath string) (num int, ok bool){
-	func(importPath string) (num int, ok bool) {
+// a group number, given the comma-separated prefixes of Options.LocalPrefix.
+var importToGroup = []func(localPrefix, importPath string) (num int, ok bool){
+	func(localPrefix, importPath string) (num int, ok bool) {
+		if localPrefix == "" {
+			return
+		}
+		for _, p := range strings.Split(localPrefix, ",") {
+			if p != "" && strings.HasPrefix(importPath, p) {
+				return 3, true
+			}
+		}
+		return
+	},
+	func(localPrefix, importPath string) (num int, ok bool) {
 		if strings.HasPrefix(importPath, "appengine") {
 			return 2, true
 		}
 		return
 	},
-	func(importPath string) (num int, ok bool) {
+	func(localPrefix, importPath string) (num int, ok bool) {
 		if strings.Contains(importPath, ".") {
 			return 1, true
 		}
@@ -36,16 +53,52 @@ var importToGroup = []func(importPath string) (num int, ok bool){
 	},
 }
 
-func importGroup(importPath string) int {
+// importGroup returns the group number of the import path, as opt says.
+func importGroup(opt *Options, importPath string) int {
+	if len(opt.Groups) > 0 {
+		return patternGroup(opt.Groups, importPath)
+	}
 	for _, fn := range importToGroup {
-		if n, ok := fn(importPath); ok {
+		if n, ok := fn(opt.LocalPrefix, importPath); ok {
 			return n
 		}
 	}
 	return 0
 }
 
-func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []string, err error) {
+// patternGroup returns the index of the pattern of Options.Groups that
+// places the import path, or len(patterns) if none does.
+func patternGroup(patterns []string, importPath string) int {
+	group, longest, std, all := -1, -1, -1, -1
+	for i, p := range patterns {
+		switch p {
+		case "std":
+			if std < 0 {
+				std = i
+			}
+		case "*":
+			if all < 0 {
+				all = i
+			}
+		default:
+			p = strings.TrimSuffix(p, "*")
+			if strings.HasPrefix(importPath, p) && len(p) > longest {
+				group, longest = i, len(p)
+			}
+		}
+	}
+	switch {
+	case group >= 0:
+		return group
+	case std >= 0 && !strings.Contains(strings.SplitN(importPath, "/", 2)[0], "."):
+		return std
+	case all >= 0:
+		return all
+	}
+	return len(patterns)
+}
+
+func fixImports(fset *token.FileSet, f *ast.File, filename string, opt *Options) (added, removed []string, err error) {
 	// refs are a set of possible package references currently unsatisfied by imports.
 	// first key: either base package (e.g. "fmt") or renamed package
 	// second key: referenced package symbol (e.g. "Println")
@@ -56,9 +109,10 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 
 	abs, err := filepath.Abs(filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	srcDir := path.Dir(abs)
+	ctxt := opt.context()
 
 	// collect potential uses of packages.
 	var visitor visitFn
@@ -71,7 +125,7 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 			if v.Name != nil {
 				decls[v.Name.Name] = v
 			} else {
-				local := importPathToName(strings.Trim(v.Path.Value, `\"`), srcDir)
+				local := importPathToName(strings.Trim(v.Path.Value, `\"`), srcDir, ctxt)
 				decls[local] = v
 			}
 		case *ast.SelectorExpr:
@@ -98,7 +152,7 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 	// Nil out any unused ImportSpecs, to be removed in following passes
 	unusedImport := map[string]string{}
 	for pkg, is := range decls {
-		if refs[pkg] == nil && pkg != "_" && pkg != "." {
+		if refs[pkg] == nil && pkg != "_" && pkg != "." && !opt.NoRemoves {
 			name := ""
 			if is.Name != nil {
 				name = is.Name.Name
@@ -111,8 +165,11 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 			// Don't remove cgo stuff.
 			continue
 		}
-		astutil.DeleteNamedImport(fset, f, name, ipath)
+		if astutil.DeleteNamedImport(fset, f, name, ipath) {
+			removed = append(removed, ipath)
+		}
 	}
+	sort.Strings(removed)
 
 	// Search for imports matching potential package references.
 	searches := 0
@@ -122,12 +179,30 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 		err   error
 	}
 	results := make(chan result)
+	var pkgDecls map[string]bool // names declared by the other files of the package
 	for pkgName, symbols := range refs {
-		if len(symbols) == 0 {
+		if len(symbols) == 0 || opt.NoAdds {
 			continue // skip over packages already imported
 		}
+		if pkgDecls == nil {
+			pkgDecls = siblingDecls(f.Name.Name, abs, opt)
+		}
+		if pkgDecls[pkgName] {
+			continue // not a package at all
+		}
+		if ipath, ok := opt.Prefer[pkgName]; ok {
+			// The preferred package is imported, named if need be,
+			// whatever other packages of the name there are.
+			r := result{ipath: ipath}
+			if importPathToName(ipath, srcDir, ctxt) != pkgName {
+				r.name = pkgName
+			}
+			go func() { results <- r }()
+			searches++
+			continue
+		}
 		go func(pkgName string, symbols map[string]bool) {
-			ipath, rename, err := findImport(pkgName, symbols, filename)
+			ipath, rename, err := findImport(pkgName, symbols, filename, opt)
 			r := result{ipath: ipath, err: err}
 			if rename {
 				r.name = pkgName
@@ -139,7 +214,7 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 	for i := 0; i < searches; i++ {
 		result := <-results
 		if result.err != nil {
-			return nil, result.err
+			return nil, nil, result.err
 		}
 		if result.ipath != "" {
 			if result.name != "" {
@@ -151,21 +226,102 @@ func fixImports(fset *token.FileSet, f *ast.File, filename string) (added []stri
 		}
 	}
 
-	return added, nil
+	sort.Strings(added)
+	return added, removed, nil
+}
+
+// siblingDecls returns the names declared at package level by the files of
+// package pkgName in the directory of filename, an absolute path, other than
+// filename itself, that the build context of opt selects. Test files count
+// only if filename is one. Files are read from opt.Overlay if it has them,
+// which may add files not yet on disk.
+func siblingDecls(pkgName, filename string, opt *Options) map[string]bool {
+	decls := make(map[string]bool)
+	if !strings.HasSuffix(filename, ".go") {
+		// Source read from standard input, unless named with -srcfile,
+		// is not in the directory it names.
+		return decls
+	}
+	dir := filepath.Dir(filename)
+	files := make(map[string]bool)
+	for name := range opt.Overlay {
+		if filepath.Dir(name) == dir {
+			files[name] = true
+		}
+	}
+	if fis, err := ioutil.ReadDir(dir); err == nil {
+		for _, fi := range fis {
+			files[filepath.Join(dir, fi.Name())] = true
+		}
+	}
+
+	// The build constraints of a file are read as its contents are.
+	ctxt := *opt.context()
+	openFile := ctxt.OpenFile
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		if data, ok := opt.Overlay[name]; ok {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		}
+		if openFile != nil {
+			return openFile(name)
+		}
+		return os.Open(name)
+	}
+	isTest := strings.HasSuffix(filename, "_test.go")
+
+	fset := token.NewFileSet()
+	for name := range files {
+		if name == filename || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !isTest {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(dir, filepath.Base(name)); err != nil || !ok {
+			continue
+		}
+		var src interface{}
+		if data, ok := opt.Overlay[name]; ok {
+			src = data
+		}
+		// A file being edited may not parse; use what there is of it.
+		f, _ := parser.ParseFile(fset, name, src, 0)
+		if f == nil || f.Name.Name != pkgName {
+			continue
+		}
+		for obj := range f.Scope.Objects {
+			decls[obj] = true
+		}
+	}
+	return decls
 }
 
 // importPathToName returns the package name for the given import path.
 var importPathToName = importPathToNameGoPath
 
-// importPathToNameBasic assumes the package name is the base of import path.
+// importPathToNameBasic assumes the package name is the base of import path,
+// without a "go-" prefix or a suffix beginning with a character that cannot be
+// in a name, as in "github.com/mattn/go-sqlite3" and "gopkg.in/yaml.v2".
 func importPathToNameBasic(importPath, srcDir string) (packageName string) {
-	return path.Base(importPath)
+	base := strings.TrimPrefix(path.Base(importPath), "go-")
+	if i := strings.IndexFunc(base, notIdentifier); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// notIdentifier reports whether ch cannot be in a Go identifier.
+func notIdentifier(ch rune) bool {
+	return !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' ||
+		'0' <= ch && ch <= '9' ||
+		ch == '_' ||
+		ch >= utf8.RuneSelf && (unicode.IsLetter(ch) || unicode.IsDigit(ch)))
 }
 
-// importPathToNameGoPath finds out the actual package name, as declared in its .go files.
-// If there's a problem, it falls back to using importPathToNameBasic.
-func importPathToNameGoPath(importPath, srcDir string) (packageName string) {
-	if buildPkg, err := build.Import(importPath, srcDir, 0); err == nil {
+// importPathToNameGoPath finds out the actual package name, as declared in its .go files
+// in ctxt. If there's a problem, it falls back to using importPathToNameBasic.
+func importPathToNameGoPath(importPath, srcDir string, ctxt *build.Context) (packageName string) {
+	if buildPkg, err := ctxt.Import(importPath, srcDir, 0); err == nil {
 		return buildPkg.Name
 	} else {
 		return importPathToNameBasic(importPath, srcDir)
@@ -177,11 +333,51 @@ type pkg struct {
 	dir        string // absolute file path to pkg directory e.g. "/usr/lib/go/src/fmt"
 }
 
+// A packageIndex holds the packages in the source directories of a build
+// context by the names they declare.
+type packageIndex struct {
+	sync.Mutex
+	m map[string][]pkg // shortname => []pkg, e.g "http" => "net/http"
+}
+
 var pkgIndexOnce = &sync.Once{}
 
-var pkgIndex struct {
+// pkgIndex is the index for build.Default, loaded by pkgIndexOnce.
+var pkgIndex = &packageIndex{}
+
+// contextIndexes holds the indexes for the build contexts of
+// Options.Context whose source directories differ from those of
+// build.Default, by their source directories.
+var contextIndexes struct {
 	sync.Mutex
-	m map[string][]pkg // shortname => []pkg, e.g "http" => "net/http"
+	m map[string]*contextIndex
+}
+
+type contextIndex struct {
+	once sync.Once
+	packageIndex
+}
+
+// indexFor returns the index of the packages in the source directories of
+// ctxt, loading it the first time it is asked for.
+func indexFor(ctxt *build.Context) *packageIndex {
+	key := strings.Join(ctxt.SrcDirs(), string(filepath.ListSeparator))
+	if key == strings.Join(build.Default.SrcDirs(), string(filepath.ListSeparator)) {
+		pkgIndexOnce.Do(loadPkgIndex)
+		return pkgIndex
+	}
+	contextIndexes.Lock()
+	if contextIndexes.m == nil {
+		contextIndexes.m = make(map[string]*contextIndex)
+	}
+	ci := contextIndexes.m[key]
+	if ci == nil {
+		ci = new(contextIndex)
+		contextIndexes.m[key] = ci
+	}
+	contextIndexes.Unlock()
+	ci.once.Do(func() { ci.load(ctxt) })
+	return &ci.packageIndex
 }
 
 // gate is a semaphore for limiting concurrency.
@@ -195,12 +391,17 @@ func (g gate) leave() { <-g }
 var fsgate = make(gate, 8)
 
 func loadPkgIndex() {
-	pkgIndex.Lock()
-	pkgIndex.m = make(map[string][]pkg)
-	pkgIndex.Unlock()
+	pkgIndex.load(&build.Default)
+}
+
+// load fills the index with the packages in the source directories of ctxt.
+func (idx *packageIndex) load(ctxt *build.Context) {
+	idx.Lock()
+	idx.m = make(map[string][]pkg)
+	idx.Unlock()
 
 	var wg sync.WaitGroup
-	for _, path := range build.Default.SrcDirs() {
+	for _, path := range ctxt.SrcDirs() {
 		fsgate.enter()
 		f, err := os.Open(path)
 		if err != nil {
@@ -215,12 +416,13 @@ func loadPkgIndex() {
 			fmt.Fprint(os.Stderr, err)
 			continue
 		}
+		ignored := readGoimportsIgnore(path)
 		for _, child := range children {
-			if child.IsDir() {
+			if child.IsDir() && !skipScan(child.Name(), child.Name(), ignored) {
 				wg.Add(1)
 				go func(path, name string) {
 					defer wg.Done()
-					loadPkg(&wg, path, name)
+					idx.loadPkg(ctxt, ignored, &wg, path, name)
 				}(path, child.Name())
 			}
 		}
@@ -228,7 +430,42 @@ func loadPkgIndex() {
 	wg.Wait()
 }
 
-func loadPkg(wg *sync.WaitGroup, root, pkgrelpath string) {
+// skipDirs are the names of the directories, besides those beginning with "."
+// or "_", whose trees the package scan skips: test data, and the trees of the
+// tools of other languages and build systems, which may be huge.
+var skipDirs = map[string]bool{
+	"testdata":     true,
+	"node_modules": true,
+	"bazel-out":    true,
+}
+
+// skipScan reports whether the package scan skips the file or directory with
+// the name and the slash-separated path relpath below its source directory,
+// the .goimportsignore file of which lists the ignored directories.
+func skipScan(name, relpath string, ignored map[string]bool) bool {
+	return name == "" || name[0] == '.' || name[0] == '_' || skipDirs[name] || ignored[relpath]
+}
+
+// readGoimportsIgnore returns the directories that the .goimportsignore file
+// in the source directory root lists, one on each line, as slash-separated
+// paths relative to root. Blank lines and lines beginning with "#" are skipped.
+func readGoimportsIgnore(root string) map[string]bool {
+	data, err := ioutil.ReadFile(filepath.Join(root, ".goimportsignore"))
+	if err != nil {
+		return nil
+	}
+	ignored := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ignored[path.Clean(filepath.ToSlash(line))] = true
+	}
+	return ignored
+}
+
+func (idx *packageIndex) loadPkg(ctxt *build.Context, ignored map[string]bool, wg *sync.WaitGroup, root, pkgrelpath string) {
 	importpath := filepath.ToSlash(pkgrelpath)
 	dir := filepath.Join(root, importpath)
 
@@ -249,9 +486,9 @@ func loadPkg(wg *sync.WaitGroup, root, pkgrelpath string) {
 	// then the calls to importPathToName below can be expensive.
 	hasGo := false
 	for _, child := range children {
-		// Avoid .foo, _foo, and testdata directory trees.
+		// Avoid .foo, _foo, testdata and other skipped directory trees.
 		name := child.Name()
-		if name == "" || name[0] == '.' || name[0] == '_' || name == "testdata" {
+		if skipScan(name, importpath+"/"+name, ignored) {
 			continue
 		}
 		if strings.HasSuffix(name, ".go") {
@@ -261,18 +498,18 @@ func loadPkg(wg *sync.WaitGroup, root, pkgrelpath string) {
 			wg.Add(1)
 			go func(root, name string) {
 				defer wg.Done()
-				loadPkg(wg, root, name)
+				idx.loadPkg(ctxt, ignored, wg, root, name)
 			}(root, filepath.Join(importpath, name))
 		}
 	}
 	if hasGo {
-		shortName := importPathToName(importpath, "")
-		pkgIndex.Lock()
-		pkgIndex.m[shortName] = append(pkgIndex.m[shortName], pkg{
+		shortName := importPathToName(importpath, "", ctxt)
+		idx.Lock()
+		idx.m[shortName] = append(idx.m[shortName], pkg{
 			importpath: importpath,
 			dir:        dir,
 		})
-		pkgIndex.Unlock()
+		idx.Unlock()
 	}
 
 }
@@ -280,9 +517,9 @@ func loadPkg(wg *sync.WaitGroup, root, pkgrelpath string) {
 // loadExports returns a list exports for a package.
 var loadExports = loadExportsGoPath
 
-func loadExportsGoPath(dir string) map[string]bool {
+func loadExportsGoPath(dir string, ctxt *build.Context) map[string]bool {
 	exports := make(map[string]bool)
-	buildPkg, err := build.ImportDir(dir, 0)
+	buildPkg, err := ctxt.ImportDir(dir, 0)
 	if err != nil {
 		if strings.Contains(err.Error(), "no buildable Go source files in") {
 			return nil
@@ -308,17 +545,18 @@ func loadExportsGoPath(dir string) map[string]bool {
 	return exports
 }
 
-// findImport searches for a package with the given symbols.
+// findImport searches for a package with the given symbols, ranking the
+// candidates as opt, which may be nil, says.
 // If no package is found, findImport returns "".
 // Declared as a variable rather than a function so goimports can be easily
 // extended by adding a file with an init function.
 var findImport = findImportGoPath
 
-func findImportGoPath(pkgName string, symbols map[string]bool, filename string) (string, bool, error) {
+func findImportGoPath(pkgName string, symbols map[string]bool, filename string, opt *Options) (string, bool, error) {
 	// Fast path for the standard library.
 	// In the common case we hopefully never have to scan the GOPATH, which can
 	// be slow with moving disks.
-	if pkg, rename, ok := findImportStdlib(pkgName, symbols); ok {
+	if pkg, rename, ok := findImportStdlib(pkgName, symbols); ok && !opt.demoted(pkg) {
 		return pkg, rename, nil
 	}
 
@@ -327,56 +565,115 @@ func findImportGoPath(pkgName string, symbols map[string]bool, filename string)
 	// in the current Go file.  Return rename=true when the other Go files
 	// use a renamed package that's also used in the current file.
 
-	pkgIndexOnce.Do(loadPkgIndex)
+	ctxt := opt.context()
+	idx := indexFor(ctxt)
 
 	// Collect exports for packages with matching names.
+	type candidate struct {
+		importpath string // devendorized import path
+		vendor     int    // length of the path holding its vendor tree, or -1
+		match      bool   // whether it exports all the symbols
+		dep        bool   // whether the project lists it as a dependency
+		demoted    bool   // whether Options.Demote lists it
+	}
 	var (
-		wg       sync.WaitGroup
-		mu       sync.Mutex
-		shortest string
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		candidates []candidate
 	)
-	pkgIndex.Lock()
-	for _, pkg := range pkgIndex.m[pkgName] {
+	idx.Lock()
+	for _, pkg := range idx.m[pkgName] {
 		if !canUse(filename, pkg.dir) {
 			continue
 		}
 		wg.Add(1)
 		go func(importpath, dir string) {
 			defer wg.Done()
-			exports := loadExports(dir)
+			exports := loadExports(dir, ctxt)
 			if exports == nil {
 				return
 			}
-			// If it doesn't have the right symbols, stop.
+			c := candidate{vendor: -1, match: true}
 			for symbol := range symbols {
 				if !exports[symbol] {
-					return
+					c.match = false
+					break
 				}
 			}
 
 			// Devendorize for use in import statement.
 			if i := strings.LastIndex(importpath, "/vendor/"); i >= 0 {
 				importpath = importpath[i+len("/vendor/"):]
+				c.vendor = i
 			} else if strings.HasPrefix(importpath, "vendor/") {
 				importpath = importpath[len("vendor/"):]
+				c.vendor = 0
 			}
+			c.importpath = importpath
+			c.demoted = opt.demoted(importpath)
 
-			// Save as the answer.
-			// If there are multiple candidates, the shortest wins,
-			// to prefer "bytes" over "github.com/foo/bytes".
 			mu.Lock()
-			if shortest == "" || len(importpath) < len(shortest) || len(importpath) == len(shortest) && importpath < shortest {
-				shortest = importpath
-			}
+			candidates = append(candidates, c)
 			mu.Unlock()
 		}(pkg.importpath, pkg.dir)
 	}
-	pkgIndex.Unlock()
+	idx.Unlock()
 	wg.Wait()
 
-	return shortest, false, nil
+	// The vendor trees that canUse lets the file see are all in directories
+	// above it, so the longer the path holding one, the nearer it is.
+	// As for the go command, a package in the nearest tree shadows any other
+	// with the same import path, even if only the other has the symbols.
+	nearest := make(map[string]int)
+	for _, c := range candidates {
+		if v, ok := nearest[c.importpath]; !ok || c.vendor > v {
+			nearest[c.importpath] = c.vendor
+		}
+	}
+
+	// Choose the answer.
+	// Demoted packages lose to all others. Then vendored packages win, the
+	// nearest first, since they are the ones the project has chosen, and then
+	// those that the manifest of the project lists as its dependencies.
+	// Otherwise, if there are multiple candidates, the shortest wins, to
+	// prefer "bytes" over "github.com/foo/bytes".
+	better := func(c, best candidate) bool {
+		switch {
+		case c.demoted != best.demoted:
+			return !c.demoted
+		case c.vendor != best.vendor:
+			return c.vendor > best.vendor
+		case c.dep != best.dep:
+			return c.dep
+		case len(c.importpath) != len(best.importpath):
+			return len(c.importpath) < len(best.importpath)
+		}
+		return c.importpath < best.importpath
+	}
+	var deps depSet
+	if len(candidates) > 1 {
+		deps = projectDeps(filename)
+	}
+	var best candidate
+	for _, c := range candidates {
+		if !c.match || c.vendor != nearest[c.importpath] {
+			continue
+		}
+		c.dep = deps.has(c.importpath)
+		if best.importpath == "" || better(c, best) {
+			best = c
+		}
+	}
+
+	// The index is keyed by the names declared by the package clauses, so
+	// name the import if that is not the name a reader would assume.
+	rename := best.importpath != "" && importPathToNameBasic(best.importpath, "") != pkgName
+	return best.importpath, rename, nil
 }
 
+// canUse reports whether the package in dir may be imported by the file
+// filename, by the rules of the go command for vendor and internal
+// directories: one may only be imported from the tree rooted at its parent.
 func canUse(filename, dir string) bool {
 	dirSlash := filepath.ToSlash(dir)
 	if !strings.Contains(dirSlash, "/vendor/") && !strings.Contains(dirSlash, "/internal/") && !strings.HasSuffix(dirSlash, "/internal") {
@@ -11,7 +11,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -752,6 +755,66 @@ func main() { fmt.Println() }
 `,
 	},
 
+	// The cgo preamble of import "C" stays with it.
+	{
+		name: "cgo preamble",
+		in: `package main
+
+import (
+	"os"
+	"fmt"
+	// #include <stdio.h>
+	"C"
+	"bytes"
+)
+
+var _, _ = fmt.Print, bytes.NewBuffer
+
+var _ = C.int(0)
+`,
+		out: `package main
+
+import (
+	"fmt"
+	// #include <stdio.h>
+	"C"
+	"bytes"
+)
+
+var _, _ = fmt.Print, bytes.NewBuffer
+
+var _ = C.int(0)
+`,
+	},
+	{
+		name: "cgo preamble after removed import",
+		in: `package main
+
+import (
+	"os"
+
+	// #include <stdio.h>
+	"C"
+)
+
+var _ = C.int(0)
+
+var _ = fmt.Print
+`,
+		out: `package main
+
+import (
+	// #include <stdio.h>
+	"C"
+)
+import "fmt"
+
+var _ = C.int(0)
+
+var _ = fmt.Print
+`,
+	},
+
 	// FormatOnly
 	{
 		name:       "format only",
@@ -796,7 +859,7 @@ func TestFixImports(t *testing.T) {
 	defer func() {
 		findImport = old
 	}()
-	findImport = func(pkgName string, symbols map[string]bool, filename string) (string, bool, error) {
+	findImport = func(pkgName string, symbols map[string]bool, filename string, opt *Options) (string, bool, error) {
 		return simplePkgs[pkgName], pkgName == "str", nil
 	}
 
@@ -823,189 +886,1342 @@ func TestFixImports(t *testing.T) {
 	}
 }
 
-// Test for correctly identifying the name of a vendored package when it
-// differs from its directory name. In this test, the import line
-// "mypkg.com/mypkg.v1" would be removed if goimports wasn't able to detect
-// that the package name is "mypkg".
-func TestFixImportsVendorPackage(t *testing.T) {
-	// Skip this test on go versions with no vendor support.
-	if _, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/vendor")); err != nil {
-		t.Skip(err)
-	}
+func TestLocalPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		in     string
+		out    string
+	}{
+		{
+			name:   "one_prefix",
+			prefix: "foo.com/",
+			in: `package main
 
-	newGoPath, err := ioutil.TempDir("", "vendortest")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(newGoPath)
+import (
+	"foo.com/bar"
+	"fmt"
+	"golang.org/x/net/context"
+	"strings"
+)
 
-	vendoredPath := newGoPath + "/src/mypkg.com/outpkg/vendor/mypkg.com/mypkg.v1"
-	if err := os.MkdirAll(vendoredPath, 0755); err != nil {
-		t.Fatal(err)
-	}
+var _, _, _, _ = bar.X, fmt.Print, context.Background, strings.Repeat
+`,
+			out: `package main
 
-	pkgIndexOnce = &sync.Once{}
-	oldGOPATH := build.Default.GOPATH
-	build.Default.GOPATH = newGoPath
-	defer func() {
-		build.Default.GOPATH = oldGOPATH
-	}()
+import (
+	"fmt"
+	"strings"
 
-	if err := ioutil.WriteFile(vendoredPath+"/f.go", []byte("package mypkg\nvar Foo = 123\n"), 0666); err != nil {
-		t.Fatal(err)
-	}
+	"golang.org/x/net/context"
 
-	input := `package p
+	"foo.com/bar"
+)
 
-import (
-	"fmt"
+var _, _, _, _ = bar.X, fmt.Print, context.Background, strings.Repeat
+`,
+		},
+		{
+			name:   "two_prefixes",
+			prefix: "foo.com/,example.org/team",
+			in: `package main
 
-	"mypkg.com/mypkg.v1"
+import (
+	"example.org/team/util"
+	"foo.com/bar"
+	"example.org/other/pkg"
 )
 
-var (
-	_ = fmt.Print
-	_ = mypkg.Foo
+var _, _, _ = util.X, bar.X, pkg.X
+`,
+			out: `package main
+
+import (
+	"example.org/other/pkg"
+
+	"example.org/team/util"
+	"foo.com/bar"
 )
-`
-	buf, err := Process(newGoPath+"/src/mypkg.com/outpkg/toformat.go", []byte(input), &Options{})
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got := string(buf); got != input {
-		t.Fatalf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, input)
-	}
-}
 
-func TestFindImportGoPath(t *testing.T) {
-	goroot, err := ioutil.TempDir("", "goimports-")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(goroot)
+var _, _, _ = util.X, bar.X, pkg.X
+`,
+		},
+		{
+			name:   "added",
+			prefix: "foo.com/",
+			in: `package main
 
-	pkgIndexOnce = &sync.Once{}
+import "fmt"
 
-	origStdlib := stdlib
-	defer func() {
-		stdlib = origStdlib
-	}()
-	stdlib = nil
+var _, _ = bar.X, fmt.Print
+`,
+			out: `package main
 
-	// Test against imaginary bits/bytes package in std lib
-	bytesDir := filepath.Join(goroot, "src", "pkg", "bits", "bytes")
-	for _, tag := range build.Default.ReleaseTags {
-		// Go 1.4 rearranged the GOROOT tree to remove the "pkg" path component.
-		if tag == "go1.4" {
-			bytesDir = filepath.Join(goroot, "src", "bits", "bytes")
-		}
-	}
-	if err := os.MkdirAll(bytesDir, 0755); err != nil {
-		t.Fatal(err)
-	}
-	bytesSrcPath := filepath.Join(bytesDir, "bytes.go")
-	bytesPkgPath := "bits/bytes"
-	bytesSrc := []byte(`package bytes
+import (
+	"fmt"
 
-type Buffer2 struct {}
-`)
-	if err := ioutil.WriteFile(bytesSrcPath, bytesSrc, 0775); err != nil {
-		t.Fatal(err)
+	"foo.com/bar"
+)
+
+var _, _ = bar.X, fmt.Print
+`,
+		},
 	}
-	oldGOROOT := build.Default.GOROOT
-	oldGOPATH := build.Default.GOPATH
-	build.Default.GOROOT = goroot
-	build.Default.GOPATH = ""
+
+	old := findImport
 	defer func() {
-		build.Default.GOROOT = oldGOROOT
-		build.Default.GOPATH = oldGOPATH
+		findImport = old
 	}()
-
-	got, rename, err := findImportGoPath("bytes", map[string]bool{"Buffer2": true}, "x.go")
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got != bytesPkgPath || rename {
-		t.Errorf(`findImportGoPath("bytes", Buffer2 ...)=%q, %t, want "%s", false`, got, rename, bytesPkgPath)
+	findImport = func(pkgName string, symbols map[string]bool, filename string, opt *Options) (string, bool, error) {
+		if pkgName == "bar" {
+			return "foo.com/bar", false, nil
+		}
+		return "", false, nil
 	}
 
-	got, rename, err = findImportGoPath("bytes", map[string]bool{"Missing": true}, "x.go")
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got != "" || rename {
-		t.Errorf(`findImportGoPath("bytes", Missing ...)=%q, %t, want "", false`, got, rename)
+	for _, tt := range tests {
+		options := &Options{
+			TabWidth:    8,
+			TabIndent:   true,
+			Comments:    true,
+			LocalPrefix: tt.prefix,
+		}
+		buf, err := Process(tt.name+".go", []byte(tt.in), options)
+		if err != nil {
+			t.Errorf("error on %q: %v", tt.name, err)
+			continue
+		}
+		if got := string(buf); got != tt.out {
+			t.Errorf("results diff on %q\nGOT:\n%s\nWANT:\n%s\n", tt.name, got, tt.out)
+		}
 	}
 }
 
-func TestFindImportInternal(t *testing.T) {
-	pkgIndexOnce = &sync.Once{}
-	oldGOPATH := build.Default.GOPATH
-	build.Default.GOPATH = ""
-	defer func() {
-		build.Default.GOPATH = oldGOPATH
-	}()
+func TestGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []string
+		in     string
+		out    string
+	}{
+		{
+			name:   "order",
+			groups: []string{"std", "*", "example.com/*", "k8s.io/*"},
+			in: `package main
 
-	// Check for src/internal/race, not just src/internal,
-	// so that we can run this test also against go1.5
-	// (which doesn't contain that file).
-	_, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/internal/race"))
-	if err != nil {
-		t.Skip(err)
-	}
+import (
+	"example.com/app/util"
+	"fmt"
+	"github.com/pkg/errors"
+	"k8s.io/client"
+	"os"
+)
 
-	got, rename, err := findImportGoPath("race", map[string]bool{"Acquire": true}, filepath.Join(runtime.GOROOT(), "src/math/x.go"))
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got != "internal/race" || rename {
-		t.Errorf(`findImportGoPath("race", Acquire ...)=%q, %t, want "internal/race", false`, got, rename)
-	}
+var _, _, _, _, _ = util.X, fmt.Print, errors.New, client.X, os.Exit
+`,
+			out: `package main
 
-	// should not be able to use internal from outside that tree
-	got, rename, err = findImportGoPath("race", map[string]bool{"Acquire": true}, filepath.Join(runtime.GOROOT(), "x.go"))
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got != "" || rename {
-		t.Errorf(`findImportGoPath("race", Acquire ...)=%q, %t, want "", false`, got, rename)
-	}
-}
+import (
+	"fmt"
+	"os"
 
-func TestFindImportVendor(t *testing.T) {
-	pkgIndexOnce = &sync.Once{}
-	oldGOPATH := build.Default.GOPATH
-	build.Default.GOPATH = ""
-	defer func() {
-		build.Default.GOPATH = oldGOPATH
-	}()
+	"github.com/pkg/errors"
 
-	_, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/vendor"))
-	if err != nil {
-		t.Skip(err)
-	}
+	"example.com/app/util"
 
-	got, rename, err := findImportGoPath("hpack", map[string]bool{"HuffmanDecode": true}, filepath.Join(runtime.GOROOT(), "src/math/x.go"))
-	if err != nil {
-		t.Fatal(err)
-	}
-	want := "golang.org/x/net/http2/hpack"
-	// Pre-1.7, we temporarily had this package under "internal" - adjust want accordingly.
-	_, err = os.Stat(filepath.Join(runtime.GOROOT(), "src/vendor", want))
-	if err != nil {
-		want = filepath.Join("internal", want)
-	}
-	if got != want || rename {
-		t.Errorf(`findImportGoPath("hpack", HuffmanDecode ...)=%q, %t, want %q, false`, got, rename, want)
-	}
+	"k8s.io/client"
+)
 
-	// should not be able to use vendor from outside that tree
-	got, rename, err = findImportGoPath("hpack", map[string]bool{"HuffmanDecode": true}, filepath.Join(runtime.GOROOT(), "x.go"))
-	if err != nil {
-		t.Fatal(err)
-	}
-	if got != "" || rename {
-		t.Errorf(`findImportGoPath("hpack", HuffmanDecode ...)=%q, %t, want "", false`, got, rename)
+var _, _, _, _, _ = util.X, fmt.Print, errors.New, client.X, os.Exit
+`,
+		},
+		{
+			name:   "merge",
+			groups: []string{"std", "example.com", "*"},
+			in: `package main
+
+import (
+	"example.com/app/util"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"example.com/app/db"
+	"os"
+)
+
+var _, _, _, _, _ = util.X, fmt.Print, errors.New, db.X, os.Exit
+`,
+			out: `package main
+
+import (
+	"fmt"
+	"os"
+
+	"example.com/app/db"
+	"example.com/app/util"
+
+	"github.com/pkg/errors"
+)
+
+var _, _, _, _, _ = util.X, fmt.Print, errors.New, db.X, os.Exit
+`,
+		},
+		{
+			name:   "comment",
+			groups: []string{"std", "*"},
+			in: `package main
+
+import (
+	"github.com/pkg/errors"
+	"fmt"
+
+	// Drivers
+	_ "github.com/lib/pq"
+	"os"
+)
+
+var _, _, _ = fmt.Print, errors.New, os.Exit
+`,
+			out: `package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	// Drivers
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+var _, _, _ = fmt.Print, errors.New, os.Exit
+`,
+		},
+		{
+			name:   "unmatched",
+			groups: []string{"example.com/", "std"},
+			in: `package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"example.com/app/util"
+)
+
+var _, _, _ = util.X, fmt.Print, errors.New
+`,
+			out: `package main
+
+import (
+	"example.com/app/util"
+
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var _, _, _ = util.X, fmt.Print, errors.New
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		options := &Options{
+			TabWidth:  8,
+			TabIndent: true,
+			Comments:  true,
+			Groups:    tt.groups,
+		}
+		buf, err := Process(tt.name+".go", []byte(tt.in), options)
+		if err != nil {
+			t.Errorf("error on %q: %v", tt.name, err)
+			continue
+		}
+		if got := string(buf); got != tt.out {
+			t.Errorf("results diff on %q\nGOT:\n%s\nWANT:\n%s\n", tt.name, got, tt.out)
+		}
+	}
+}
+
+// Test that import "C" is not moved away from its cgo preamble, nor a blank
+// line put between them, when the groups order it after other imports.
+func TestGroupsCgo(t *testing.T) {
+	input := `package main
+
+import (
+	"fmt"
+	// #include <stdio.h>
+	"C"
+	"github.com/pkg/errors"
+)
+
+var _, _ = fmt.Print, errors.New
+
+var _ = C.int(0)
+`
+	want := `package main
+
+import (
+	"fmt"
+	// #include <stdio.h>
+	"C"
+
+	"github.com/pkg/errors"
+)
+
+var _, _ = fmt.Print, errors.New
+
+var _ = C.int(0)
+`
+	options := &Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Groups:    []string{"*", "std"},
+	}
+	buf, err := Process("cgo.go", []byte(input), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); got != want {
+		t.Errorf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, want)
+	}
+}
+
+func TestPrefer(t *testing.T) {
+	old := findImport
+	defer func() {
+		findImport = old
+	}()
+	findImport = func(pkgName string, symbols map[string]bool, filename string, opt *Options) (string, bool, error) {
+		return pkgName, false, nil
+	}
+
+	input := `package main
+
+var _, _, _ = errors.New, log.Print, fmt.Print
+`
+	want := `package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var _, _, _ = errors.New, log.Print, fmt.Print
+`
+	options := &Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Prefer: map[string]string{
+			"errors": "github.com/pkg/errors",
+			"log":    "github.com/sirupsen/logrus",
+		},
+	}
+	buf, err := Process("prefer.go", []byte(input), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); got != want {
+		t.Errorf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, want)
+	}
+}
+
+// Tests that names declared by the other files of the package, on disk or in
+// Options.Overlay, are not taken for packages, unless the build context
+// excludes the files, or they are test files and the file processed is not.
+func TestFixImportsSiblings(t *testing.T) {
+	old := findImport
+	defer func() {
+		findImport = old
+	}()
+	findImport = func(pkgName string, symbols map[string]bool, filename string, opt *Options) (string, bool, error) {
+		return pkgName, false, nil
+	}
+
+	dir, err := ioutil.TempDir("", "siblings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"disk.go":    "package p\n\nvar disk struct{ X int }\n",
+		"edited.go":  "package p\n",
+		"p_test.go":  "package p_test\n\nvar other struct{ X int }\n",
+		"a_test.go":  "package p\n\nvar intest struct{ X int }\n",
+		"ignored.go": "// +build ignore\n\npackage p\n\nvar ignored struct{ X int }\n",
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input := `package p
+
+var _ = []int{disk.X, edited.X, unsaved.X, other.X, intest.X, ignored.X, overlaid.X}
+`
+	options := &Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Overlay: map[string][]byte{
+			filepath.Join(dir, "edited.go"):   []byte("package p\n\nvar edited struct{ X int }\n"),
+			filepath.Join(dir, "unsaved.go"):  []byte("package p\n\ntype unsaved struct{ X int }\n"),
+			filepath.Join(dir, "overlaid.go"): []byte("// +build ignore\n\npackage p\n\nvar overlaid struct{ X int }\n"),
+		},
+	}
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"p.go", []string{"ignored", "intest", "other", "overlaid"}},
+		{"b_test.go", []string{"ignored", "other", "overlaid"}},
+	}
+	for _, tt := range tests {
+		_, changes, err := ProcessChanges(filepath.Join(dir, tt.file), []byte(input), options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(changes.Added, tt.want) {
+			t.Errorf("processing %s added %q, want %q", tt.file, changes.Added, tt.want)
+		}
+	}
+
+	// Source read from standard input is not in the current directory.
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	_, changes, err := ProcessChanges("<standard input>", []byte("package p\n\nvar _ = disk.X\n"), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"disk"}; !reflect.DeepEqual(changes.Added, want) {
+		t.Errorf("processing standard input added %q, want %q", changes.Added, want)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	input := `package main
+
+type T struct{ x int }
+
+func f(s []int) {
+	_ = []T{T{1}, T{2}}
+	_ = map[string]*T{"a": &T{1}}
+	_ = s[1:len(s)]
+	for i, _ := range s {
+		fmt.Println(i)
+	}
+	for _ = range s {
+	}
+}
+`
+	want := `package main
+
+import "fmt"
+
+type T struct{ x int }
+
+func f(s []int) {
+	_ = []T{{1}, {2}}
+	_ = map[string]*T{"a": {1}}
+	_ = s[1:]
+	for i := range s {
+		fmt.Println(i)
+	}
+	for range s {
+	}
+}
+`
+	options := &Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Simplify:  true,
+	}
+	buf, err := Process("simplify.go", []byte(input), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); got != want {
+		t.Errorf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, want)
+	}
+
+	// Without Simplify, only the import is added.
+	options.Simplify = false
+	buf, err = Process("simplify.go", []byte(input), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); !strings.Contains(got, "[]T{T{1}, T{2}}") || !strings.Contains(got, `import "fmt"`) {
+		t.Errorf("results without Simplify:\n%s", got)
+	}
+}
+
+func TestImportsOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "rewrite",
+			in: `package p
+
+import (
+  "os"
+  "fmt"
+)
+
+func f()  {
+    fmt.Println( strings.Repeat("x", 2) )
+}
+`,
+			out: `package p
+
+import (
+	"fmt"
+	"strings"
+)
+
+func f()  {
+    fmt.Println( strings.Repeat("x", 2) )
+}
+`,
+		},
+		{
+			name: "add",
+			in: `package p // comment
+
+func f()  {
+    fmt.Println( 1 )
+}
+`,
+			out: `package p // comment
+
+import "fmt"
+
+func f()  {
+    fmt.Println( 1 )
+}
+`,
+		},
+		{
+			name: "remove",
+			in: `package p
+
+import "os"
+
+func f()  {
+    println( 1 )
+}
+`,
+			out: `package p
+
+func f()  {
+    println( 1 )
+}
+`,
+		},
+		{
+			name: "none",
+			in: `package p
+func f()  {}
+`,
+			out: `package p
+func f()  {}
+`,
+		},
+	}
+
+	options := &Options{
+		TabWidth:    8,
+		TabIndent:   true,
+		Comments:    true,
+		Fragment:    true,
+		ImportsOnly: true,
+	}
+	for _, tt := range tests {
+		buf, err := Process(tt.name+".go", []byte(tt.in), options)
+		if err != nil {
+			t.Errorf("error on %q: %v", tt.name, err)
+			continue
+		}
+		if got := string(buf); got != tt.out {
+			t.Errorf("results diff on %q\nGOT:\n%s\nWANT:\n%s\n", tt.name, got, tt.out)
+		}
+	}
+
+	if _, err := Process("fragment.go", []byte("fmt.Println(1)\n"), options); err == nil {
+		t.Errorf("no error processing a fragment with ImportsOnly")
+	}
+}
+
+func TestProcessChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Changes
+	}{
+		{
+			name: "add",
+			in:   "package p\n\nvar _ = fmt.Print\n",
+			want: Changes{Added: []string{"fmt"}, Removed: []string{}, SrcStart: 9, SrcEnd: 9, Start: 11, End: 23},
+		},
+		{
+			name: "replace",
+			in:   "package p\n\nimport (\n\t\"os\"\n\t\"io\"\n)\n\nvar _, _ = io.EOF, fmt.Print\n",
+			want: Changes{Added: []string{"fmt"}, Removed: []string{"os"}, SrcStart: 11, SrcEnd: 33, Start: 11, End: 34},
+		},
+		{
+			name: "format",
+			in:   "package p\n\nimport \"fmt\"\n\nvar  _ = fmt.Print\n",
+			want: Changes{Added: []string{}, Removed: []string{}, SrcStart: 11, SrcEnd: 23, Start: 11, End: 23},
+		},
+		{
+			name: "fragment",
+			in:   "var _ = fmt.Print\n",
+			want: Changes{Added: []string{"fmt"}, Removed: []string{}, SrcStart: -1, SrcEnd: -1, Start: -1, End: -1},
+		},
+	}
+
+	options := &Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Fragment:  true,
+	}
+	for _, tt := range tests {
+		_, changes, err := ProcessChanges(tt.name+".go", []byte(tt.in), options)
+		if err != nil {
+			t.Errorf("error on %q: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*changes, tt.want) {
+			t.Errorf("changes on %q = %+v, want %+v", tt.name, *changes, tt.want)
+		}
+	}
+}
+
+func TestNoAddsRemoves(t *testing.T) {
+	input := `package p
+
+import "os"
+
+var _ = fmt.Print
+`
+	tests := []struct {
+		name              string
+		noAdds, noRemoves bool
+		want              Changes
+	}{
+		{"neither", false, false, Changes{Added: []string{"fmt"}, Removed: []string{"os"}}},
+		{"noadds", true, false, Changes{Added: []string{}, Removed: []string{"os"}}},
+		{"noremoves", false, true, Changes{Added: []string{"fmt"}, Removed: []string{}}},
+		{"both", true, true, Changes{Added: []string{}, Removed: []string{}}},
+	}
+	for _, tt := range tests {
+		options := &Options{
+			TabWidth:  8,
+			TabIndent: true,
+			Comments:  true,
+			NoAdds:    tt.noAdds,
+			NoRemoves: tt.noRemoves,
+		}
+		_, changes, err := ProcessChanges("noadds.go", []byte(input), options)
+		if err != nil {
+			t.Errorf("error on %q: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(changes.Added, tt.want.Added) || !reflect.DeepEqual(changes.Removed, tt.want.Removed) {
+			t.Errorf("changes on %q added %q and removed %q, want %q and %q", tt.name, changes.Added, changes.Removed, tt.want.Added, tt.want.Removed)
+		}
+	}
+}
+
+// Test for correctly identifying the name of a vendored package when it
+// differs from its directory name. In this test, the import line
+// "mypkg.com/mypkg.v1" would be removed if goimports wasn't able to detect
+// that the package name is "mypkg".
+func TestFixImportsVendorPackage(t *testing.T) {
+	// Skip this test on go versions with no vendor support.
+	if _, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/vendor")); err != nil {
+		t.Skip(err)
+	}
+
+	newGoPath, err := ioutil.TempDir("", "vendortest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(newGoPath)
+
+	vendoredPath := newGoPath + "/src/mypkg.com/outpkg/vendor/mypkg.com/mypkg.v1"
+	if err := os.MkdirAll(vendoredPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = newGoPath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	if err := ioutil.WriteFile(vendoredPath+"/f.go", []byte("package mypkg\nvar Foo = 123\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `package p
+
+import (
+	"fmt"
+
+	"mypkg.com/mypkg.v1"
+)
+
+var (
+	_ = fmt.Print
+	_ = mypkg.Foo
+)
+`
+	buf, err := Process(newGoPath+"/src/mypkg.com/outpkg/toformat.go", []byte(input), &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); got != input {
+		t.Fatalf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, input)
+	}
+}
+
+func TestImportPathToNameBasic(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"fmt", "fmt"},
+		{"net/http", "http"},
+		{"gopkg.in/yaml.v2", "yaml"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+		{"github.com/foo/bar-baz", "bar"},
+	}
+	for _, tt := range tests {
+		if got := importPathToNameBasic(tt.path, ""); got != tt.want {
+			t.Errorf("importPathToNameBasic(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// Test that packages are found by the names their package clauses declare,
+// and imported with those names where they are not those a reader would
+// assume from the import paths.
+func TestFixImportsRenamed(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "renamed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/gopkg.in/yaml.v2/yaml.go":               "package yaml\nfunc Unmarshal() {}\n",
+		"src/github.com/mattn/go-sqlite3/sqlite3.go": "package sqlite3\nfunc Open() {}\n",
+		"src/github.com/foo/baz/baz.go":              "package qux\nvar X = 1\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	input := `package app
+
+var _, _, _ = yaml.Unmarshal, sqlite3.Open, qux.X
+`
+	want := `package app
+
+import (
+	qux "github.com/foo/baz"
+	"github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v2"
+)
+
+var _, _, _ = yaml.Unmarshal, sqlite3.Open, qux.X
+`
+	buf, err := Process(filepath.Join(gopath, "src/app/x.go"), []byte(input), &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); got != want {
+		t.Errorf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, want)
+	}
+}
+
+func TestFindImportGoPath(t *testing.T) {
+	goroot, err := ioutil.TempDir("", "goimports-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(goroot)
+
+	pkgIndexOnce = &sync.Once{}
+
+	origStdlib := stdlib
+	defer func() {
+		stdlib = origStdlib
+	}()
+	stdlib = nil
+
+	// Test against imaginary bits/bytes package in std lib
+	bytesDir := filepath.Join(goroot, "src", "pkg", "bits", "bytes")
+	for _, tag := range build.Default.ReleaseTags {
+		// Go 1.4 rearranged the GOROOT tree to remove the "pkg" path component.
+		if tag == "go1.4" {
+			bytesDir = filepath.Join(goroot, "src", "bits", "bytes")
+		}
+	}
+	if err := os.MkdirAll(bytesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	bytesSrcPath := filepath.Join(bytesDir, "bytes.go")
+	bytesPkgPath := "bits/bytes"
+	bytesSrc := []byte(`package bytes
+
+type Buffer2 struct {}
+`)
+	if err := ioutil.WriteFile(bytesSrcPath, bytesSrc, 0775); err != nil {
+		t.Fatal(err)
+	}
+	oldGOROOT := build.Default.GOROOT
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOROOT = goroot
+	build.Default.GOPATH = ""
+	defer func() {
+		build.Default.GOROOT = oldGOROOT
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	got, rename, err := findImportGoPath("bytes", map[string]bool{"Buffer2": true}, "x.go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != bytesPkgPath || rename {
+		t.Errorf(`findImportGoPath("bytes", Buffer2 ...)=%q, %t, want "%s", false`, got, rename, bytesPkgPath)
+	}
+
+	got, rename, err = findImportGoPath("bytes", map[string]bool{"Missing": true}, "x.go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" || rename {
+		t.Errorf(`findImportGoPath("bytes", Missing ...)=%q, %t, want "", false`, got, rename)
+	}
+}
+
+// Test that a candidate is only chosen if it exports all the symbols the
+// code refers to, however it would rank otherwise.
+func TestFindImportSymbols(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/x.io/bar/bar.go":                 "package bar\nvar Baz, qux = 1, 2\n",
+		"src/github.com/long/bar/bar.go":      "package bar\nvar Baz = 1\n",
+		"src/github.com/long/bar/qux.go":      "package bar\nfunc Qux() {}\n",
+		"src/github.com/long/bar/bar_test.go": "package bar\nvar Test = 1\n",
+		"src/github.com/long/bar/method.go":   "package bar\ntype T int\nfunc (T) Method() {}\n",
+		"src/github.com/long/bar/ignored.go":  "// +build ignore\n\npackage bar\nvar Ignored = 1\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	tests := []struct {
+		symbols []string
+		want    string
+	}{
+		{[]string{"Baz"}, "x.io/bar"},
+		{[]string{"Qux"}, "github.com/long/bar"},
+		{[]string{"Baz", "Qux"}, "github.com/long/bar"},
+		{[]string{"Baz", "T"}, "github.com/long/bar"},
+		// Neither unexported names, methods, nor the declarations of
+		// test files or of files excluded by build constraints count.
+		{[]string{"qux"}, ""},
+		{[]string{"Method"}, ""},
+		{[]string{"Test"}, ""},
+		{[]string{"Ignored"}, ""},
+	}
+	for _, tt := range tests {
+		symbols := make(map[string]bool)
+		for _, sym := range tt.symbols {
+			symbols[sym] = true
+		}
+		got, _, err := findImportGoPath("bar", symbols, filepath.Join(gopath, "src/app/x.go"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("findImportGoPath(\"bar\", %v ...) = %q, want %q", tt.symbols, got, tt.want)
+		}
+	}
+}
+
+// Test that demoted packages are chosen only if no others will do,
+// even over the standard library.
+func TestFindImportDemote(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/context")); err != nil {
+		t.Skip(err)
+	}
+
+	gopath, err := ioutil.TempDir("", "demote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/x.io/bar/bar.go":                     "package bar\nvar Baz, Qux = 1, 2\n",
+		"src/github.com/long/bar/bar.go":          "package bar\nvar Baz = 1\n",
+		"src/golang.org/x/net/context/context.go": "package context\nfunc Background() {}\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	tests := []struct {
+		pkgName string
+		symbol  string
+		demote  []string
+		want    string
+	}{
+		{"bar", "Baz", nil, "x.io/bar"},
+		{"bar", "Baz", []string{"x.io"}, "github.com/long/bar"},
+		{"bar", "Baz", []string{"x.io/"}, "github.com/long/bar"},
+		{"bar", "Baz", []string{"x.io/b"}, "x.io/bar"},
+		{"bar", "Qux", []string{"x.io"}, "x.io/bar"},
+		{"context", "Background", nil, "context"},
+		{"context", "Background", []string{"context"}, "golang.org/x/net/context"},
+		{"context", "Background", []string{"golang.org/x/net"}, "context"},
+	}
+	for _, tt := range tests {
+		opt := &Options{Demote: tt.demote}
+		got, _, err := findImportGoPath(tt.pkgName, map[string]bool{tt.symbol: true}, filepath.Join(gopath, "src/app/x.go"), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("findImportGoPath(%q, %s ...) demoting %q = %q, want %q", tt.pkgName, tt.symbol, tt.demote, got, tt.want)
+		}
+	}
+}
+
+func TestFindImportInternal(t *testing.T) {
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = ""
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	// Check for src/internal/race, not just src/internal,
+	// so that we can run this test also against go1.5
+	// (which doesn't contain that file).
+	_, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/internal/race"))
+	if err != nil {
+		t.Skip(err)
+	}
+
+	got, rename, err := findImportGoPath("race", map[string]bool{"Acquire": true}, filepath.Join(runtime.GOROOT(), "src/math/x.go"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "internal/race" || rename {
+		t.Errorf(`findImportGoPath("race", Acquire ...)=%q, %t, want "internal/race", false`, got, rename)
+	}
+
+	// should not be able to use internal from outside that tree
+	got, rename, err = findImportGoPath("race", map[string]bool{"Acquire": true}, filepath.Join(runtime.GOROOT(), "x.go"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" || rename {
+		t.Errorf(`findImportGoPath("race", Acquire ...)=%q, %t, want "", false`, got, rename)
+	}
+}
+
+// Tests that internal packages in GOPATH are only found from the trees
+// rooted at the parents of their internal directories.
+func TestFindImportInternalGoPath(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "internal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/x.io/lib/internal/a/a.go":             "package a\nvar A = 1\n",
+		"src/x.io/lib/sub/internal/b/b.go":         "package b\nvar B = 1\n",
+		"src/x.io/lib/internal/c/internal/d/d.go":  "package d\nvar D = 1\n",
+		"src/internal/e/e.go":                      "package e\nvar E = 1\n",
+		"src/proj/vendor/y.io/dep/internal/f/f.go": "package f\nvar F = 1\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	tests := []struct {
+		file    string
+		pkgName string
+		want    string
+	}{
+		{"src/x.io/lib/x.go", "a", "x.io/lib/internal/a"},
+		{"src/x.io/lib/sub/deep/x.go", "a", "x.io/lib/internal/a"},
+		{"src/x.io/lib/internal/a/x.go", "a", "x.io/lib/internal/a"},
+		{"src/x.io/other/x.go", "a", ""},
+		{"src/x.io/libx/x.go", "a", ""},
+		{"src/x.io/lib/sub/x.go", "b", "x.io/lib/sub/internal/b"},
+		{"src/x.io/lib/x.go", "b", ""},
+		{"src/x.io/lib/internal/c/x.go", "d", "x.io/lib/internal/c/internal/d"},
+		{"src/x.io/lib/internal/x.go", "d", ""},
+		{"src/x.io/lib/x.go", "d", ""},
+		{"src/app/x.go", "e", "internal/e"},
+		{"src/proj/x.go", "f", ""},
+	}
+	for _, tt := range tests {
+		symbol := strings.ToUpper(tt.pkgName)
+		got, _, err := findImportGoPath(tt.pkgName, map[string]bool{symbol: true}, filepath.Join(gopath, tt.file), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("findImportGoPath(%q, %s ...) from %s = %q, want %q", tt.pkgName, symbol, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestFindImportVendor(t *testing.T) {
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = ""
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	_, err := os.Stat(filepath.Join(runtime.GOROOT(), "src/vendor"))
+	if err != nil {
+		t.Skip(err)
+	}
+
+	got, rename, err := findImportGoPath("hpack", map[string]bool{"HuffmanDecode": true}, filepath.Join(runtime.GOROOT(), "src/math/x.go"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "golang.org/x/net/http2/hpack"
+	// Pre-1.7, we temporarily had this package under "internal" - adjust want accordingly.
+	_, err = os.Stat(filepath.Join(runtime.GOROOT(), "src/vendor", want))
+	if err != nil {
+		want = filepath.Join("internal", want)
+	}
+	if got != want || rename {
+		t.Errorf(`findImportGoPath("hpack", HuffmanDecode ...)=%q, %t, want %q, false`, got, rename, want)
+	}
+
+	// should not be able to use vendor from outside that tree
+	got, rename, err = findImportGoPath("hpack", map[string]bool{"HuffmanDecode": true}, filepath.Join(runtime.GOROOT(), "x.go"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" || rename {
+		t.Errorf(`findImportGoPath("hpack", HuffmanDecode ...)=%q, %t, want "", false`, got, rename)
+	}
+}
+
+// Test that packages in the vendor trees visible from the file win over
+// others, the nearest tree first, and shadow the packages in GOPATH with the
+// same import paths.
+func TestFindImportVendorPriority(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "vendorpriority")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/example.com/bar/bar.go":                                 "package bar\nvar Baz = 1\n",
+		"src/github.com/upstream/bar/bar.go":                         "package bar\nvar Baz, Qux = 1, 2\n",
+		"src/proj.com/app/vendor/github.com/upstream/bar/bar.go":     "package bar\nvar Baz = 1\n",
+		"src/proj.com/app/sub/vendor/github.com/fork/bar/bar.go":     "package bar\nvar Baz = 1\n",
+		"src/proj.com/app/sub/vendor/github.com/upstream/bar/bar.go": "package bar\nvar Baz, Qux = 1, 2\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	tests := []struct {
+		file   string
+		symbol string
+		want   string
+	}{
+		// Outside the project, the shortest path wins.
+		{"src/other/x.go", "Baz", "example.com/bar"},
+		{"src/other/x.go", "Qux", "github.com/upstream/bar"},
+		// The vendored package wins over a shorter path, and shadows
+		// the one in GOPATH, although only that one has Qux.
+		{"src/proj.com/app/x.go", "Baz", "github.com/upstream/bar"},
+		{"src/proj.com/app/x.go", "Qux", ""},
+		// The nearer vendor tree wins, and its package shadows the one
+		// in the tree above.
+		{"src/proj.com/app/sub/x.go", "Baz", "github.com/fork/bar"},
+		{"src/proj.com/app/sub/x.go", "Qux", "github.com/upstream/bar"},
+	}
+	for _, tt := range tests {
+		got, rename, err := findImportGoPath("bar", map[string]bool{tt.symbol: true}, filepath.Join(gopath, tt.file), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want || rename {
+			t.Errorf("findImportGoPath(\"bar\", %s ...) from %s = %q, %t, want %q, false", tt.symbol, tt.file, got, rename, tt.want)
+		}
+	}
+}
+
+// Test that packages that the manifest of the project lists as its
+// dependencies win over others.
+func TestFindImportProjectDeps(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "projectdeps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/x.io/bar/bar.go":                     "package bar\nvar Baz = 1\n",
+		"src/github.com/upstream/bar/bar.go":      "package bar\nvar Baz = 1\n",
+		"src/github.com/fork/bar/bar.go":          "package bar\nvar Baz = 1\n",
+		"src/mod.com/m/go.mod":                    "module mod.com/m\n\nrequire (\n\tgithub.com/upstream/bar v1.0.0 // indirect\n)\n",
+		"src/godep.com/app/Godeps/Godeps.json":    `{"ImportPath": "godep.com/app", "Deps": [{"ImportPath": "github.com/fork/bar", "Rev": "abc"}]}`,
+		"src/govendor.com/app/vendor/vendor.json": `{"package": [{"path": "github.com/upstream", "revision": "abc"}]}`,
+		"src/self.com/m/go.mod":                   "module github.com/upstream\n",
+		"src/replace.com/m/go.mod":                "module replace.com/m\n\nreplace (\n\tgithub.com/upstream/bar v1.0.0 => github.com/fork/bar v1.1.0\n\tx.io/bar => ../bar\n)\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgIndexOnce = &sync.Once{}
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"src/other/x.go", "x.io/bar"},
+		{"src/mod.com/m/sub/x.go", "github.com/upstream/bar"},
+		{"src/godep.com/app/x.go", "github.com/fork/bar"},
+		{"src/govendor.com/app/x.go", "github.com/upstream/bar"},
+		{"src/self.com/m/x.go", "github.com/upstream/bar"},
+		{"src/replace.com/m/x.go", "github.com/fork/bar"},
+	}
+	for _, tt := range tests {
+		got, _, err := findImportGoPath("bar", map[string]bool{"Baz": true}, filepath.Join(gopath, tt.file), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("findImportGoPath(\"bar\", Baz ...) from %s = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+// Tests that the package scan skips the trees that .goimportsignore lists,
+// and those of other languages and build systems.
+func TestLoadPkgIndexIgnore(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "ignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/.goimportsignore":             "# Generated code.\nx.io/gen\n\ny.io/\n",
+		"src/x.io/gen/bar/bar.go":          "package bar\n",
+		"src/x.io/generated/bar/bar.go":    "package bar\n",
+		"src/y.io/bar/bar.go":              "package bar\n",
+		"src/node_modules/bar/bar.go":      "package bar\n",
+		"src/web/node_modules/bar/bar.go":  "package bar\n",
+		"src/bazel-out/bar/bar.go":         "package bar\n",
+		"src/.git/bar/bar.go":              "package bar\n",
+		"src/z.io/.cache/bar/bar.go":       "package bar\n",
+		"src/z.io/bar/testdata/bar/bar.go": "package bar\n",
+		"src/z.io/bar/bar.go":              "package bar\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldGOPATH := build.Default.GOPATH
+	build.Default.GOPATH = gopath
+	defer func() {
+		build.Default.GOPATH = oldGOPATH
+	}()
+	pkgIndexOnce = &sync.Once{}
+	pkgIndexOnce.Do(loadPkgIndex)
+
+	var got []string
+	for _, pkg := range pkgIndex.m["bar"] {
+		got = append(got, pkg.importpath)
+	}
+	sort.Strings(got)
+	want := []string{"x.io/generated/bar", "z.io/bar"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed packages named bar = %q, want %q", got, want)
+	}
+}
+
+// Tests that Options.Context, rather than build.Default, decides which
+// packages there are and what they export.
+func TestProcessContext(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+
+	files := map[string]string{
+		"src/x.io/bar/bar.go":    "// +build tagged\n\npackage bar\n\nvar Baz = 1\n",
+		"src/x.io/go-qux/qux.go": "package quux\n\nvar Qux = 1\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(gopath, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	ctxt.BuildTags = []string{"tagged"}
+
+	input := `package p
+
+var _, _ = bar.Baz, quux.Qux
+`
+	want := `package p
+
+import (
+	"x.io/bar"
+	quux "x.io/go-qux"
+)
+
+var _, _ = bar.Baz, quux.Qux
+`
+	options := &Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Context:   &ctxt,
+	}
+	buf, err := Process(filepath.Join(gopath, "src/app/x.go"), []byte(input), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf); got != want {
+		t.Errorf("results differ\nGOT:\n%s\nWANT:\n%s\n", got, want)
 	}
 }
 
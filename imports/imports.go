@@ -13,6 +13,7 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/format"
 	"go/parser"
 	"go/printer"
@@ -35,6 +36,77 @@ type Options struct {
 	TabWidth  int  // Tab width (8 if nil *Options provided)
 
 	FormatOnly bool // Disable the insertion and deletion of imports
+	Simplify   bool // Simplify code, as gofmt -s does
+
+	NoAdds    bool // Do not add missing imports
+	NoRemoves bool // Do not remove unused imports
+
+	// Context is the build context in which imports are looked up, its
+	// GOROOT, GOPATH and build tags deciding which packages there are and
+	// what they export. If nil, build.Default is used.
+	Context *build.Context
+
+	// ImportsOnly leaves the source outside the import declarations as it
+	// is, unformatted and unsimplified, for minimal changes. It requires a
+	// complete source file.
+	ImportsOnly bool
+
+	// LocalPrefix is a comma-separated list of import path prefixes.
+	// Imports with one of them are sorted into a group of their own,
+	// after third-party imports.
+	LocalPrefix string
+
+	// Groups, if set, replaces the default grouping of imports, LocalPrefix
+	// included, by groups in the order of the patterns that match them:
+	// "std" matches the standard library, "*" any import, and any other
+	// pattern, with an optional "*" after it, the imports with it as a
+	// prefix. Of the patterns that match an import, the longest prefix
+	// wins, then "std", then "*". Imports that none match go last.
+	// Blank lines between runs of imports are removed, unless a comment
+	// separates them, so that the runs are merged and split into groups.
+	Groups []string
+
+	// Prefer maps package names, as the code refers to them, to the
+	// import paths to add for them, in place of any found by searching,
+	// so that names such as "errors" or "log" always resolve to the
+	// packages a project has chosen. The import is named by the key if
+	// the package declares a different name.
+	Prefer map[string]string
+
+	// Demote lists import paths whose packages, and those below them, are
+	// chosen only if no other package of the name has the symbols the code
+	// refers to, whatever their ranking otherwise, as for deprecated
+	// packages such as "golang.org/x/net/context".
+	Demote []string
+
+	// Overlay maps absolute file names to contents that differ from those
+	// on disk, such as those of unsaved editor buffers. The names declared
+	// by the other files of the package, read from Overlay in preference to
+	// the disk, are not taken for references to packages.
+	Overlay map[string][]byte
+}
+
+// context returns the build context of opt, which may be nil.
+func (opt *Options) context() *build.Context {
+	if opt == nil || opt.Context == nil {
+		return &build.Default
+	}
+	return opt.Context
+}
+
+// demoted reports whether Demote lists importPath or a path above it.
+// A nil opt demotes nothing.
+func (opt *Options) demoted(importPath string) bool {
+	if opt == nil {
+		return false
+	}
+	for _, p := range opt.Demote {
+		p = strings.TrimSuffix(p, "/")
+		if importPath == p || strings.HasPrefix(importPath, p+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 // Process formats and adjusts imports for the provided file.
@@ -44,6 +116,26 @@ type Options struct {
 // so it is important that filename be accurate.
 // To process data ``as if'' it were in filename, pass the data as a non-nil src.
 func Process(filename string, src []byte, opt *Options) ([]byte, error) {
+	out, _, err := ProcessChanges(filename, src, opt)
+	return out, err
+}
+
+// Changes describes the changes Process made to the imports of a file.
+type Changes struct {
+	Added   []string // the import paths added, sorted; never nil
+	Removed []string // the import paths removed, sorted; never nil
+
+	// SrcStart and SrcEnd are the byte offsets in the input of the start of
+	// its first import declaration and of the end of its last, and Start and
+	// End those in the output. If there are none, both are of the end of the
+	// package clause. All are -1 for a fragment of a source file.
+	SrcStart, SrcEnd int
+	Start, End       int
+}
+
+// ProcessChanges is like Process, but also describes the changes it made
+// to the imports.
+func ProcessChanges(filename string, src []byte, opt *Options) ([]byte, *Changes, error) {
 	if opt == nil {
 		opt = &Options{Comments: true, TabIndent: true, TabWidth: 8}
 	}
@@ -51,17 +143,27 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 	fileSet := token.NewFileSet()
 	file, adjust, err := parse(fileSet, filename, src, opt)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if opt.ImportsOnly && adjust != nil {
+		return nil, nil, fmt.Errorf("%s: ImportsOnly requires a complete source file", filename)
 	}
 
+	// Empty lists rather than nil ones encode as JSON arrays.
+	changes := &Changes{Added: []string{}, Removed: []string{}}
 	if !opt.FormatOnly {
-		_, err = fixImports(fileSet, file, filename)
+		added, removed, err := fixImports(fileSet, file, filename, opt)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		changes.Added = append(changes.Added, added...)
+		changes.Removed = append(changes.Removed, removed...)
 	}
 
-	sortImports(fileSet, file)
+	sortImports(opt, fileSet, file)
+	if opt.Simplify {
+		simplify(file)
+	}
 	imps := astutil.Imports(fileSet, file)
 
 	var spacesBefore []string // import paths we need spaces before
@@ -73,8 +175,9 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 		lastGroup := -1
 		for _, importSpec := range impSection {
 			importPath, _ := strconv.Unquote(importSpec.Path.Value)
-			groupNum := importGroup(importPath)
-			if groupNum != lastGroup && lastGroup != -1 {
+			groupNum := importGroup(opt, importPath)
+			// A blank line before "C" would detach its cgo preamble.
+			if groupNum != lastGroup && lastGroup != -1 && importPath != "C" {
 				spacesBefore = append(spacesBefore, importPath)
 			}
 			lastGroup = groupNum
@@ -91,7 +194,7 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 	var buf bytes.Buffer
 	err = printConfig.Fprint(&buf, fileSet, file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	out := buf.Bytes()
 	if adjust != nil {
@@ -102,10 +205,97 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 	}
 
 	out, err = format.Source(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opt.ImportsOnly {
+		out, err = spliceImports(filename, src, out)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	changes.SrcStart, changes.SrcEnd = declsRange(filename, src)
+	changes.Start, changes.End = declsRange(filename, out)
+	return out, changes, nil
+}
+
+// declsRange returns the offsets of the start of the first import declaration
+// of the source file src and of the end of the last, as importsRange does, or
+// -1 twice if it is not a complete source file.
+func declsRange(filename string, src []byte) (start, end int) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly)
+	if err != nil {
+		return -1, -1
+	}
+	return importsRange(fset, f)
+}
+
+// spliceImports returns src with its import declarations replaced by those
+// of out, its processed form.
+func spliceImports(filename string, src, out []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	srcFile, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	start, end := importsRange(fset, srcFile)
+	outFile, err := parser.ParseFile(fset, filename, out, parser.ImportsOnly)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	outStart, outEnd := importsRange(fset, outFile)
+	decls := out[outStart:outEnd]
+
+	var b bytes.Buffer
+	switch {
+	case start == end && outStart == outEnd:
+		return src, nil
+	case start == end:
+		// Add the declarations on the lines after the package clause.
+		eol := bytes.IndexByte(src[end:], '\n')
+		if eol < 0 {
+			return nil, fmt.Errorf("%s: no line after the package clause", filename)
+		}
+		end += eol + 1
+		b.Write(src[:end])
+		b.WriteByte('\n')
+		b.Write(decls)
+		b.WriteByte('\n')
+	case outStart == outEnd:
+		// Remove the declarations, with the blank lines after them.
+		b.Write(src[:start])
+		for end < len(src) && (src[end] == ' ' || src[end] == '\t' || src[end] == '\n' || src[end] == '\r') {
+			end++
+		}
+	default:
+		b.Write(src[:start])
+		b.Write(decls)
+	}
+	b.Write(src[end:])
+	return b.Bytes(), nil
+}
+
+// importsRange returns the offsets in its source of the start of the first
+// import declaration of f and the end of the last, or, if it has none, the
+// end of its package clause twice.
+func importsRange(fset *token.FileSet, f *ast.File) (start, end int) {
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	start, end = -1, offset(f.Name.End())
+	for _, decl := range f.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			break
+		}
+		if start < 0 {
+			start = offset(d.Pos())
+		}
+		end = offset(d.End())
+	}
+	if start < 0 {
+		start = end
+	}
+	return start, end
 }
 
 // parse parses src, which was read from filename,
@@ -15,7 +15,9 @@ import (
 
 // sortImports sorts runs of consecutive import lines in import blocks in f.
 // It also removes duplicate imports when it is possible to do so without data loss.
-func sortImports(fset *token.FileSet, f *ast.File) {
+// The imports are ordered by their groups, as opt says; with Options.Groups,
+// runs separated only by blank lines are first merged, to be split by group.
+func sortImports(opt *Options, fset *token.FileSet, f *ast.File) {
 	for i, d := range f.Decls {
 		d, ok := d.(*ast.GenDecl)
 		if !ok || d.Tok != token.IMPORT {
@@ -34,17 +36,24 @@ func sortImports(fset *token.FileSet, f *ast.File) {
 			continue
 		}
 
+		if len(opt.Groups) > 0 {
+			mergeRuns(fset, f, d)
+		}
+
 		// Identify and sort runs of specs on successive lines.
+		// An import of "C" is a run of its own, so that it stays
+		// after the comment preceding it, which is its cgo preamble.
 		i := 0
 		specs := d.Specs[:0]
 		for j, s := range d.Specs {
-			if j > i && fset.Position(s.Pos()).Line > 1+fset.Position(d.Specs[j-1].End()).Line {
+			if j > i && (fset.Position(s.Pos()).Line > 1+fset.Position(d.Specs[j-1].End()).Line ||
+				importPath(s) == "C" || importPath(d.Specs[j-1]) == "C") {
 				// j begins a new run.  End this one.
-				specs = append(specs, sortSpecs(fset, f, d.Specs[i:j])...)
+				specs = append(specs, sortSpecs(opt, fset, f, d.Specs[i:j])...)
 				i = j
 			}
 		}
-		specs = append(specs, sortSpecs(fset, f, d.Specs[i:])...)
+		specs = append(specs, sortSpecs(opt, fset, f, d.Specs[i:])...)
 		d.Specs = specs
 
 		// Deduping can leave a blank line before the rparen; clean that up.
@@ -58,6 +67,34 @@ func sortImports(fset *token.FileSet, f *ast.File) {
 	}
 }
 
+// mergeRuns moves the runs of specs of the import declaration d that are
+// separated only by blank lines onto successive lines, so that they are
+// sorted as one.
+func mergeRuns(fset *token.FileSet, f *ast.File, d *ast.GenDecl) {
+	for j := 1; j < len(d.Specs); j++ {
+		prev := fset.Position(d.Specs[j-1].End()).Line
+		next := fset.Position(d.Specs[j].Pos()).Line
+		if next <= prev+1 {
+			continue
+		}
+		between := false
+		for _, g := range f.Comments {
+			if line := fset.Position(g.Pos()).Line; line > prev && line < next {
+				between = true
+				break
+			}
+		}
+		if between {
+			// A comment heads the run; leave it apart.
+			continue
+		}
+		file := fset.File(d.Specs[j].Pos())
+		for i := prev + 1; i < next; i++ {
+			file.MergeLine(prev + 1)
+		}
+	}
+}
+
 func importPath(s ast.Spec) string {
 	t, err := strconv.Unquote(s.(*ast.ImportSpec).Path.Value)
 	if err == nil {
@@ -95,7 +132,7 @@ type posSpan struct {
 	End   token.Pos
 }
 
-func sortSpecs(fset *token.FileSet, f *ast.File, specs []ast.Spec) []ast.Spec {
+func sortSpecs(opt *Options, fset *token.FileSet, f *ast.File, specs []ast.Spec) []ast.Spec {
 	// Can't short-circuit here even if specs are already sorted,
 	// since they might yet need deduplication.
 	// A lone import, however, may be safely ignored.
@@ -144,7 +181,7 @@ func sortSpecs(fset *token.FileSet, f *ast.File, specs []ast.Spec) []ast.Spec {
 	// Reassign the import paths to have the same position sequence.
 	// Reassign each comment to abut the end of its spec.
 	// Sort the comments by new position.
-	sort.Sort(byImportSpec(specs))
+	sort.Sort(byImportSpec{opt, specs})
 
 	// Dedup. Thanks to our sorting, we can just consider
 	// adjacent pairs of imports.
@@ -179,16 +216,19 @@ func sortSpecs(fset *token.FileSet, f *ast.File, specs []ast.Spec) []ast.Spec {
 	return specs
 }
 
-type byImportSpec []ast.Spec // slice of *ast.ImportSpec
+type byImportSpec struct {
+	opt   *Options
+	specs []ast.Spec // slice of *ast.ImportSpec
+}
 
-func (x byImportSpec) Len() int      { return len(x) }
-func (x byImportSpec) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x byImportSpec) Len() int      { return len(x.specs) }
+func (x byImportSpec) Swap(i, j int) { x.specs[i], x.specs[j] = x.specs[j], x.specs[i] }
 func (x byImportSpec) Less(i, j int) bool {
-	ipath := importPath(x[i])
-	jpath := importPath(x[j])
+	ipath := importPath(x.specs[i])
+	jpath := importPath(x.specs[j])
 
-	igroup := importGroup(ipath)
-	jgroup := importGroup(jpath)
+	igroup := importGroup(x.opt, ipath)
+	jgroup := importGroup(x.opt, jpath)
 	if igroup != jgroup {
 		return igroup < jgroup
 	}
@@ -196,13 +236,13 @@ func (x byImportSpec) Less(i, j int) bool {
 	if ipath != jpath {
 		return ipath < jpath
 	}
-	iname := importName(x[i])
-	jname := importName(x[j])
+	iname := importName(x.specs[i])
+	jname := importName(x.specs[j])
 
 	if iname != jname {
 		return iname < jname
 	}
-	return importComment(x[i]) < importComment(x[j])
+	return importComment(x.specs[i]) < importComment(x.specs[j])
 }
 
 type byCommentPos []*ast.CommentGroup
@@ -3,7 +3,8 @@
 // license that can be found in the LICENSE file.
 
 // Package playground registers HTTP handlers at "/compile" and "/share" that
-// proxy requests to the golang.org playground service.
+// proxy requests to the golang.org playground service, or to another service
+// set with BaseURL. Requests from the pages of other sites are refused.
 // This package may be used unaltered on App Engine.
 package playground // import "golang.org/x/tools/playground"
 
@@ -13,9 +14,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 )
 
-const baseURL = "https://golang.org"
+// BaseURL is the URL of the playground service to which requests are
+// proxied. It may be set, before the first request, to the URL of another
+// deployment of the playground.
+var BaseURL = "https://golang.org"
 
 func init() {
 	http.HandleFunc("/compile", bounce)
@@ -23,6 +28,10 @@ func init() {
 }
 
 func bounce(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
 	b := new(bytes.Buffer)
 	if err := passThru(b, r); err != nil {
 		http.Error(w, "Server error.", http.StatusInternalServerError)
@@ -37,7 +46,7 @@ func passThru(w io.Writer, req *http.Request) error {
 		return errors.New("Forbidden")
 	}
 	defer req.Body.Close()
-	url := baseURL + req.URL.Path
+	url := BaseURL + req.URL.Path
 	r, err := client(req).Post(url, req.Header.Get("Content-type"), req.Body)
 	if err != nil {
 		return fmt.Errorf("making POST request: %v", err)
@@ -49,6 +58,19 @@ func passThru(w io.Writer, req *http.Request) error {
 	return nil
 }
 
+// sameOrigin reports whether r comes from a page of the server it is sent
+// to, or from no page at all. Browsers send the Origin header with the
+// requests that pages of other sites make, which must not run programs or
+// share code in the name of the users of the server.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
 var onAppengine = false // will be overriden by appengine.go and appenginevm.go
 
 func allowShare(r *http.Request) bool {
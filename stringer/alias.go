@@ -0,0 +1,88 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the alias comments, which give the
+// constants other names that the generated code accepts when it reads them,
+// and of the -fold flag, which makes Parse ignore case.
+
+package stringer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// AliasMarker begins a comment on a constant, or above it, listing other
+// names by which Parse and the methods that unmarshal values accept it, as in
+//
+//	Monday Day = iota //stringer:alias Mon
+const AliasMarker = "//stringer:alias"
+
+// aliases returns the names listed by the alias comments of the constants
+// declared by vspec, in doc, its doc comment, or on its line. They must
+// declare one constant, or it would not be clear which the names are for.
+func (f *File) aliases(vspec *ast.ValueSpec, doc *ast.CommentGroup) []string {
+	var names []string
+	for _, group := range []*ast.CommentGroup{doc, vspec.Comment} {
+		if group == nil {
+			continue
+		}
+		for _, c := range group.List {
+			rest := strings.TrimPrefix(c.Text, AliasMarker)
+			if rest == c.Text || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				continue
+			}
+			if len(vspec.Names) > 1 {
+				failAt(FileError, f.pkg.fset.Position(c.Pos()), "%s is on a declaration of several constants", AliasMarker)
+			}
+			names = append(names, strings.Fields(rest)...)
+		}
+	}
+	return names
+}
+
+// checkNames verifies that no name or alias of a constant is that of a
+// constant with a different value, ignoring case with -fold, so that reading
+// a name gives one value.
+func (g *generator) checkNames(typeName string, values []Value) {
+	type use struct {
+		v    *Value
+		name string
+	}
+	seen := make(map[string]use)
+	for i := range values {
+		v := &values[i]
+		for _, name := range append([]string{v.name}, v.aliases...) {
+			key := name
+			if g.fold {
+				key = strings.ToLower(name)
+			}
+			if u, ok := seen[key]; ok && u.v.str != v.str {
+				if u.name != name {
+					failf("-fold: %s and %s of type %s have the names %q and %q, differing only in case", u.v.originalName, v.originalName, typeName, u.name, name)
+				}
+				failf("%s and %s of type %s both have the name %q", u.v.originalName, v.originalName, typeName, name)
+			}
+			seen[key] = use{v, name}
+		}
+	}
+}
+
+// hasAliases reports whether any of the values has an alias.
+func hasAliases(values []Value) bool {
+	for i := range values {
+		if len(values[i].aliases) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Argument to format is the type name.
+const foldLookup = `	for name, i := range _%[1]s_value {
+		if strings.EqualFold(name, s) {
+			return i, nil
+		}
+	}
+`
@@ -0,0 +1,127 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -blocks flag, which splits the
+// tables of names by the const blocks declaring the constants, and of the
+// -group flag, which adds a method naming the block of a value.
+
+package stringer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// GroupMarker begins a comment above a const block naming the group of its
+// constants, which the Group method returns, as in
+//
+//	//stringer:group client
+//	const (
+//		BadRequest Status = 400 + iota
+//		...
+//	)
+const GroupMarker = "//stringer:group"
+
+// groupName returns the name given by the group comment in the doc comment of
+// a const block, or "" if there is none.
+func groupName(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, c := range doc.List {
+		rest := strings.TrimPrefix(c.Text, GroupMarker)
+		if rest != c.Text && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// splitBlocks splits the runs where consecutive values are declared in
+// different const blocks, so that each run is of one block.
+func splitBlocks(runs [][]Value) [][]Value {
+	var split [][]Value
+	for _, run := range runs {
+		start := 0
+		for i := 1; i < len(run); i++ {
+			if run[i].block != run[i-1].block {
+				split = append(split, run[start:i])
+				start = i
+			}
+		}
+		split = append(split, run[start:])
+	}
+	return split
+}
+
+// declareBlockNames declares the names of the runs, which splitBlocks has
+// made each of one block, in one string and one array of offsets for each
+// block, numbered in the order of their least values, and returns the tables
+// locating them.
+func (g *generator) declareBlockNames(runs [][]Value, typeName string, prefixes *prefixTable) []*nameTable {
+	blocks := make(map[token.Pos]*sharedNames)
+	var order []*sharedNames
+	tables := make([]*nameTable, len(runs))
+	for i, run := range runs {
+		b := blocks[run[0].block]
+		if b == nil {
+			b = &sharedNames{
+				name:    fmt.Sprintf("_%s_block%d_name", typeName, len(order)),
+				index:   fmt.Sprintf("_%s_block%d_index", typeName, len(order)),
+				offsets: []int{0},
+			}
+			blocks[run[0].block] = b
+			order = append(order, b)
+		}
+		tables[i] = b.add(run, prefixes)
+	}
+	for _, b := range order {
+		b.declare(g)
+	}
+	g.Printf("\n")
+	if prefixes != nil {
+		prefixes.declareRuns(g, runs, typeName)
+	}
+	return tables
+}
+
+// buildGroup generates the Group method, which returns the name of the group
+// of the block declaring the value, or the first such for a value declared
+// in several. The values are listed in declaration order.
+func (g *generator) buildGroup(declared []Value, typeName string) {
+	var groups []string
+	cases := make(map[string][]string)
+	for _, v := range declared {
+		if v.group == "" {
+			continue
+		}
+		if cases[v.group] == nil {
+			groups = append(groups, v.group)
+		}
+		cases[v.group] = append(cases[v.group], v.str)
+	}
+	g.Printf(groupMethod, typeName, GroupMarker)
+	if len(groups) > 0 {
+		g.Printf("\tswitch i {\n")
+		for _, group := range groups {
+			g.Printf("\tcase %s:\n", strings.Join(cases[group], ", "))
+			g.Printf("\t\treturn %q\n", group)
+		}
+		g.Printf("\t}\n")
+	}
+	g.Printf("\treturn \"\"\n")
+	g.Printf("}\n")
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: the group comment
+const groupMethod = `
+// Group returns the name of the group of the %[1]s constants with the value
+// of i, given by the %[2]s comment on the const block
+// declaring them, or "" if it has none or i is not the value of a constant.
+func (i %[1]s) Group() string {
+`
@@ -0,0 +1,113 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of packages that use cgo, whose constants
+// may be defined by C, as in Sig = C.SIGINT.
+
+package stringer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// importsC reports whether the file imports "C".
+func importsC(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && path == "C" {
+			return true
+		}
+	}
+	return false
+}
+
+// translateCgo replaces the syntax of those of the files, with the given
+// names, that import "C" with that of their translation by cgo, in which C
+// constants have values, and returns the syntax of all the files to check,
+// with that of the file cgo writes declaring the C names. If cgo fails, for
+// want of a C compiler, say, the files are left alone and the error is
+// recorded, to be reported if a constant is left without a value.
+func (g *generator) translateCgo(fs *token.FileSet, files []*File, names []string) []*ast.File {
+	var astFiles []*ast.File
+	var cgoFiles []*File
+	var cgoNames []string
+	for i, file := range files {
+		astFiles = append(astFiles, file.file)
+		if importsC(file.file) {
+			cgoFiles = append(cgoFiles, file)
+			cgoNames = append(cgoNames, names[i])
+		}
+	}
+	if len(cgoFiles) == 0 {
+		return astFiles
+	}
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		g.pkg.errors = append(g.pkg.errors, err)
+		return astFiles
+	}
+	defer os.RemoveAll(dir)
+	if err := g.runCgo(dir, cgoNames); err != nil {
+		g.pkg.errors = append(g.pkg.errors, err)
+		return astFiles
+	}
+	// The translated files have line directives giving the positions in the
+	// original ones.
+	translated := make([]*ast.File, len(cgoFiles))
+	for i, name := range cgoNames {
+		name = filepath.Join(dir, strings.TrimSuffix(filepath.Base(name), ".go")+".cgo1.go")
+		if translated[i], err = parser.ParseFile(fs, name, nil, parser.ParseComments); err != nil {
+			g.pkg.errors = append(g.pkg.errors, err)
+			return astFiles
+		}
+	}
+	typesFile, err := parser.ParseFile(fs, filepath.Join(dir, "_cgo_gotypes.go"), nil, 0)
+	if err != nil {
+		g.pkg.errors = append(g.pkg.errors, err)
+		return astFiles
+	}
+	for i, file := range cgoFiles {
+		file.file = translated[i]
+	}
+	astFiles = astFiles[:0]
+	for _, file := range files {
+		astFiles = append(astFiles, file.file)
+	}
+	return append(astFiles, typesFile)
+}
+
+// runCgo runs cgo on the named files, with the flags of the package in their
+// directory, writing the translations into dir.
+func (g *generator) runCgo(dir string, names []string) error {
+	directory := filepath.Dir(names[0])
+	ctxt := g.buildContext()
+	args := []string{"tool", "cgo", "-objdir", dir, "--"}
+	// The error, if any, is for files we are not concerned with.
+	if pkg, _ := ctxt.ImportDir(directory, 0); pkg != nil {
+		args = append(args, pkg.CgoCPPFLAGS...)
+		args = append(args, pkg.CgoCFLAGS...)
+	}
+	for _, name := range names {
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return err
+		}
+		args = append(args, abs)
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = directory
+	cmd.Env = append(os.Environ(), "GOOS="+ctxt.GOOS, "GOARCH="+ctxt.GOARCH)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cgo: %s\n%s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -char flag, which prints values of
+// rune and byte types as quoted characters.
+
+package stringer
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// checkChar verifies that the named type, whose values are like v, holds
+// characters: its underlying type must be rune or byte.
+func (g *generator) checkChar(typeName string, v *Value) {
+	if g.flags {
+		failf("-char does not apply with -flags")
+	}
+	if v.isFloat || !(v.signed && v.bits == 32 || !v.signed && v.bits == 8) {
+		failf("-char does not apply to %s, whose underlying type is not rune or byte", typeName)
+	}
+}
+
+// quoteChars names each of the values by the character it holds, quoted as
+// in a Go rune literal, such as '+'. Values that are not characters, such as
+// an EOF of -1, keep their names.
+func quoteChars(values []Value) {
+	for i := range values {
+		v := &values[i]
+		r := rune(v.value)
+		if v.signed {
+			r = rune(int64(v.value))
+		}
+		if utf8.ValidRune(r) {
+			v.name = strconv.QuoteRune(r)
+		}
+	}
+}
+
+// quoteChar returns an expression for the character held by the value of
+// expr, quoted as in a Go rune literal.
+func (g *generator) quoteChar(expr string) string {
+	g.addImport("strconv")
+	return "strconv.QuoteRune(rune(" + expr + "))"
+}
@@ -0,0 +1,33 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -error flag, which makes the type
+// implement the error interface, as suits a type whose constants are error
+// codes, and of -errorprefix and -erroronly, which refine it. -erroronly
+// renames the String method as -method does.
+
+package stringer
+
+import "fmt"
+
+// buildError generates the Error method, which returns what String returns,
+// after the name of the type with -errorprefix.
+func (g *generator) buildError(typeName string) {
+	result := "i.String()"
+	if g.errorPrefix {
+		result = fmt.Sprintf("%q + i.String()", typeName+": ")
+	}
+	g.Printf(errorMethod, typeName, result)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: expression for the result
+const errorMethod = `
+// Error implements the error interface, so that the constants of %[1]s can
+// be returned as errors.
+func (i %[1]s) Error() string {
+	return %[2]s
+}
+`
@@ -0,0 +1,116 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the errors that stop the code from being generated.
+
+package stringer
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+)
+
+// An ErrorKind classifies an Error, so that a program can react to some
+// failures without matching the text of the message.
+type ErrorKind int
+
+const (
+	OtherError   ErrorKind = iota // Any failure not listed below, such as options that do not apply.
+	FileError                     // A package or file could not be read, parsed or type-checked.
+	TypeNotFound                  // The package declares no type of the name.
+	NoValues                      // The type has no constants.
+)
+
+var errorKindNames = []string{
+	OtherError:   "other",
+	FileError:    "file",
+	TypeNotFound: "typenotfound",
+	NoValues:     "novalues",
+}
+
+// String returns the name of the kind, as in "novalues".
+func (k ErrorKind) String() string {
+	if k < 0 || int(k) >= len(errorKindNames) {
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+	return errorKindNames[k]
+}
+
+// An Error is returned by the functions of the package when the code cannot
+// be generated.
+type Error struct {
+	Kind ErrorKind
+	Pos  token.Position // The place in the source at fault; its Filename alone may be set, or nothing.
+	Msg  string
+}
+
+// Error returns the message, which, as it was printed by stringer before
+// errors had a position, may or may not include the position.
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// failure is the value with which the generator panics to stop at an error,
+// which the exported functions recover and return.
+type failure struct {
+	err error
+}
+
+// fail stops the generator with the error.
+func fail(err *Error) {
+	panic(failure{err})
+}
+
+// failf stops the generator with an error of kind OtherError, formatted as by
+// fmt.Sprintf.
+func failf(format string, args ...interface{}) {
+	fail(&Error{Msg: fmt.Sprintf(format, args...)})
+}
+
+// failAt stops the generator with an error of the kind, at the position,
+// formatted as by fmt.Sprintf.
+func failAt(kind ErrorKind, pos token.Position, format string, args ...interface{}) {
+	fail(&Error{Kind: kind, Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// failChecking stops the generator with the first type error of the package.
+func (pkg *Package) failChecking() {
+	err := pkg.errors[0]
+	var pos token.Position
+	if terr, ok := err.(types.Error); ok {
+		pos = terr.Fset.Position(terr.Pos)
+	}
+	failAt(FileError, pos, "checking package: %s", err)
+}
+
+// errorPos returns the position of the first of the errors from parsing the
+// named file, or the file alone if it could not be read.
+func errorPos(name string, err error) token.Position {
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		return list[0].Pos
+	}
+	return token.Position{Filename: name}
+}
+
+// typePos returns the position of the declaration of the named type.
+func (pkg *Package) typePos(typeName string) token.Position {
+	obj := pkg.typesPkg.Scope().Lookup(typeName)
+	if obj == nil {
+		return token.Position{}
+	}
+	return pkg.fset.Position(obj.Pos())
+}
+
+// catch recovers from a panic by failf, setting *err to its error.
+func catch(err *error) {
+	if r := recover(); r != nil {
+		f, ok := r.(failure)
+		if !ok {
+			panic(r)
+		}
+		*err = f.err
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -exhaustive flag, which reports
+// switch statements that do not handle every constant of a type.
+
+package stringer
+
+import (
+	"fmt"
+	"go/ast"
+	exact "go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// constant is a constant of the type whose switch statements are checked.
+type constant struct {
+	name  string
+	value exact.Value
+}
+
+// IncompleteSwitches returns a report for each switch statement in the
+// package on a value of the named type that has no default case and does not
+// handle every value of the constants of the type. Constants with the same
+// value are handled together and reported by the first name.
+func (pkg *Package) IncompleteSwitches(typeName string) ([]string, error) {
+	obj, _ := pkg.typesPkg.Scope().Lookup(typeName).(*types.TypeName)
+	if obj == nil {
+		return nil, &Error{Kind: TypeNotFound, Msg: fmt.Sprintf("no type %s in package %s", typeName, pkg.name)}
+	}
+	typ := obj.Type()
+	consts := pkg.constants(typ)
+	if len(consts) == 0 {
+		return nil, &Error{Kind: NoValues, Pos: pkg.fset.Position(obj.Pos()), Msg: fmt.Sprintf("no values defined for type %s", typeName)}
+	}
+	var reports []string
+	for _, file := range pkg.files {
+		if file.file == nil {
+			continue
+		}
+		ast.Inspect(file.file, func(node ast.Node) bool {
+			sw, ok := node.(*ast.SwitchStmt)
+			if !ok || sw.Tag == nil || !types.Identical(pkg.exprs[sw.Tag].Type, typ) {
+				return true
+			}
+			if missing := pkg.missingCases(sw, consts); len(missing) > 0 {
+				reports = append(reports, fmt.Sprintf("%s: switch on %s has no default and misses %s",
+					pkg.fset.Position(sw.Pos()), typeName, strings.Join(missing, ", ")))
+			}
+			return true
+		})
+	}
+	return reports, nil
+}
+
+// constants returns the constants of the type, in the order they are
+// declared, omitting any whose value is that of an earlier one.
+func (pkg *Package) constants(typ types.Type) []constant {
+	var consts []constant
+	for _, file := range pkg.files {
+		if file.file == nil {
+			continue
+		}
+		for _, decl := range file.file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					obj, ok := pkg.defs[name].(*types.Const)
+					if !ok || !types.Identical(obj.Type(), typ) || covered(consts, obj.Val()) {
+						continue
+					}
+					consts = append(consts, constant{name.Name, obj.Val()})
+				}
+			}
+		}
+	}
+	return consts
+}
+
+// missingCases returns the names of the constants whose values are not those
+// of any case of the switch statement, or nil if it has a default case.
+func (pkg *Package) missingCases(sw *ast.SwitchStmt, consts []constant) []string {
+	var values []constant
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*ast.CaseClause) // Guaranteed to succeed as this is an expression switch.
+		if clause.List == nil {
+			return nil
+		}
+		for _, expr := range clause.List {
+			if value := pkg.exprs[expr].Value; value != nil {
+				values = append(values, constant{value: value})
+			}
+		}
+	}
+	var missing []string
+	for _, c := range consts {
+		if !covered(values, c.value) {
+			missing = append(missing, c.name)
+		}
+	}
+	return missing
+}
+
+// covered reports whether one of the constants has the value.
+func covered(consts []constant, value exact.Value) bool {
+	for _, c := range consts {
+		if exact.Compare(c.value, token.EQL, value) {
+			return true
+		}
+	}
+	return false
+}
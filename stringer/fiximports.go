@@ -0,0 +1,93 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the computing of the import block of the generated
+// files, so that every combination of modes compiles whatever packages each
+// uses.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// printImports prints the import declaration of the generated code body: the
+// packages recorded by addImport, sorted, with those of the standard library
+// in a group before the others. A recorded package that the code does not
+// refer to is dropped if its name is known, as for the standard library and
+// the package of the types; the others are kept. No package is imported that
+// was not recorded, so -plugin and -template code must record the packages
+// it uses.
+func (g *generator) printImports(body []byte) {
+	used := usedNames(body)
+	var std, other []string
+	for p := range g.imports {
+		switch {
+		case isStandard(p):
+			if used == nil || used[path.Base(p)] {
+				std = append(std, p)
+			}
+		case p == g.pkg.path:
+			if used == nil || used[g.pkg.name] {
+				other = append(other, p)
+			}
+		default:
+			other = append(other, p)
+		}
+	}
+	if len(std)+len(other) == 0 {
+		return
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	g.Printf("import (\n")
+	for _, p := range std {
+		g.Printf("\t%q\n", p)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		g.Printf("\n")
+	}
+	for _, p := range other {
+		g.Printf("\t%q\n", p)
+	}
+	g.Printf(")\n")
+}
+
+// usedNames returns the names that the code body, the declarations of a file
+// without its package clause, qualifies identifiers with, which are those of
+// the packages it refers to. It returns nil if the code does not parse, in
+// which case the compiler will report the error.
+func usedNames(body []byte) map[string]bool {
+	src := append([]byte("package p\n"), body...)
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			// A package name is not resolved by the parser.
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				names[id.Name] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// isStandard reports whether the import path is that of a package of the
+// standard library, whose first element, unlike a domain name, has no dot.
+func isStandard(importPath string) bool {
+	first := importPath
+	if i := strings.Index(importPath, "/"); i >= 0 {
+		first = importPath[:i]
+	}
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,45 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.5
+
+// This file contains the handling of constants of floating-point types.
+
+package stringer
+
+import (
+	"go/ast"
+	exact "go/constant"
+	"go/types"
+	"math"
+	"strconv"
+)
+
+// floatValue returns the Value for the constant with the given name and
+// value, declared in vspec, whose underlying type is the floating-point type t.
+// Such values have no runs, so String is always generated using a map.
+func (f *File) floatValue(name string, t *types.Basic, value exact.Value, vspec *ast.ValueSpec) Value {
+	bits := bitSize(t)
+	// The type checker has already rounded the value to the type.
+	x, _ := exact.Float64Val(value)
+	return Value{
+		originalName: name,
+		name:         f.printedName(name, vspec),
+		value:        floatKey(x),
+		bits:         bits,
+		str:          strconv.FormatFloat(x, 'g', -1, int(bits)),
+		isFloat:      true,
+	}
+}
+
+// floatKey returns an integer that sorts among those of other floating-point
+// numbers as x does among them: the bits of x, with those of negative numbers
+// inverted so they sort below the positive ones, which have the top bit set.
+func floatKey(x float64) uint64 {
+	b := math.Float64bits(x)
+	if b>>63 != 0 {
+		return ^b
+	}
+	return b | 1<<63
+}
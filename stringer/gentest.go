@@ -0,0 +1,157 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -gentest flag, or the Test option,
+// which writes a test of the generated code beside it.
+
+package stringer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// newTest returns a generator to accumulate the test file for the code g
+// generates. It shares the header and the settings on which the fallback
+// string depends.
+func (g *generator) newTest() *generator {
+	t := &generator{pkg: g.pkg, header: g.header, invalid: g.invalid, char: g.char, noFmt: g.noFmt}
+	t.addImport("testing")
+	return t
+}
+
+// buildTest generates the test for the named type, which checks that the
+// name of each of the declared constants is parsed as a value with the same
+// name, and that values with no name print as the fallback. The values are
+// also given sorted, in runs.
+func (g *generator) buildTest(declared []Value, runs [][]Value, typeName string) {
+	t := g.test
+	names := make([]string, len(declared))
+	for i, v := range declared {
+		names[i] = v.originalName
+	}
+	parse := funcName("Parse", typeName)
+	t.Printf(testFunc, typeName, strings.ToUpper(typeName[:1])+typeName[1:], parse, strings.Join(names, ", "))
+	if invalid := invalidValues(runs, g.flags); len(invalid) > 0 {
+		first := &runs[0][0]
+		verb, conv := first.format()
+		if g.invalid == "panic" {
+			t.Printf(testPanic, typeName, strings.Join(invalid, ", "), verb, conv)
+		} else {
+			t.Printf(testFallback, typeName, strings.Join(invalid, ", "), t.invalidString(typeName, "v", first), verb, conv)
+		}
+	}
+	t.Printf("}\n")
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: type name with an upper-case initial
+//	[3]: name of the Parse function
+//	[4]: names of the constants
+const testFunc = `
+func Test%[2]sString(t *testing.T) {
+	for _, v := range []%[1]s{%[4]s} {
+		s := v.String()
+		p, err := %[3]s(s)
+		if err != nil {
+			t.Errorf("%[3]s(%%q): %%v", s, err)
+		} else if p.String() != s {
+			t.Errorf("%[3]s(%%q).String() = %%q", s, p.String())
+		}
+	}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: values with no name
+//	[3]: expression for the fallback string of v
+//	[4]: verb with which to print the value
+//	[5]: conversion that keeps fmt from calling String
+const testFallback = `	for _, v := range []%[1]s{%[2]s} {
+		if got, want := v.String(), %[3]s; got != want {
+			t.Errorf("%[1]s(%[4]s).String() = %%q, want %%q", %[5]s(v), got, want)
+		}
+	}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: values with no name
+//	[3]: verb with which to print the value
+//	[4]: conversion that keeps fmt from calling String
+const testPanic = `	for _, v := range []%[1]s{%[2]s} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%[1]s(%[3]s).String() did not panic", %[4]s(v))
+				}
+			}()
+			_ = v.String()
+		}()
+	}
+`
+
+// invalidValues returns literals for values with no name: those just below
+// and above the values, given in runs, and the first in a gap between them,
+// if the type can hold them. For flags, it returns the least bit that is not
+// set in any of the values, if there is one.
+func invalidValues(runs [][]Value, flags bool) []string {
+	first := runs[0][0]
+	last := runs[len(runs)-1]
+	if first.isFloat {
+		var invalid []string
+		if f, err := strconv.ParseFloat(first.str, 64); err == nil && f-1 != f {
+			invalid = append(invalid, strconv.FormatFloat(f-1, 'g', -1, 64))
+		}
+		if f, err := strconv.ParseFloat(last[len(last)-1].str, 64); err == nil && f+1 != f {
+			invalid = append(invalid, strconv.FormatFloat(f+1, 'g', -1, 64))
+		}
+		return invalid
+	}
+	if flags {
+		used := make(map[uint64]bool)
+		for _, values := range runs {
+			for _, v := range values {
+				used[v.value] = true
+			}
+		}
+		bits := first.bits
+		if first.signed {
+			// The sign bit cannot be written as a positive constant.
+			bits--
+		}
+		for b := uint(0); b < bits; b++ {
+			if !used[1<<b] {
+				return []string{strconv.FormatUint(1<<b, 10)}
+			}
+		}
+		return nil
+	}
+	greatest := last[len(last)-1].value
+	var invalid []string
+	if first.signed {
+		least := int64(-1) << (first.bits - 1)
+		if int64(first.value) != least {
+			invalid = append(invalid, strconv.FormatInt(int64(first.value)-1, 10))
+		}
+		if int64(greatest) != ^least {
+			invalid = append(invalid, strconv.FormatInt(int64(greatest)+1, 10))
+		}
+		if len(runs) > 1 {
+			invalid = append(invalid, strconv.FormatInt(int64(runs[0][len(runs[0])-1].value)+1, 10))
+		}
+		return invalid
+	}
+	if first.value != 0 {
+		invalid = append(invalid, strconv.FormatUint(first.value-1, 10))
+	}
+	if greatest != ^uint64(0)>>(64-first.bits) {
+		invalid = append(invalid, strconv.FormatUint(greatest+1, 10))
+	}
+	if len(runs) > 1 {
+		invalid = append(invalid, strconv.FormatUint(runs[0][len(runs[0])-1].value+1, 10))
+	}
+	return invalid
+}
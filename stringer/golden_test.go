@@ -0,0 +1,3207 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains simple golden tests for various examples.
+// Besides validating the results when the implementation changes,
+// it provides a way to look at the generated code without having
+// to execute the print statements in one's head.
+
+package stringer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Golden represents a test case.
+type Golden struct {
+	Options
+	name   string
+	input  string // input; the package clause is provided when running the test.
+	output string // exected output.
+}
+
+var golden = []Golden{
+	{name: "day", input: day_in, output: day_out},
+	{name: "offset", input: offset_in, output: offset_out},
+	{name: "duplast", Options: Options{Duplicates: "last"}, input: offset_in, output: duplast_out},
+	{name: "valueof", Options: Options{ValueOf: true}, input: offset_in, output: offset_out + valueof_out},
+	{name: "dupjoin", Options: Options{Duplicates: "join"}, input: offset_in, output: dupjoin_out},
+	{name: "gap", input: gap_in, output: gap_out},
+	{name: "num", input: num_in, output: num_out},
+	{name: "unum", input: unum_in, output: unum_out},
+	{name: "min", Options: Options{IsValid: true, Ordinal: true}, input: min_in, output: min_out},
+	{name: "max", Options: Options{IsValid: true, Ordinal: true}, input: max_in, output: max_out},
+	{name: "extremes", Options: Options{IsValid: true, Ordinal: true}, input: extremes_in, output: extremes_out},
+	{name: "bits", Options: Options{IsValid: true}, input: bits_in, output: bits_out},
+	{name: "prime", input: prime_in, output: prime_out},
+	{name: "prefix", Options: Options{TrimPrefix: "Type"}, input: prefix_in, output: prefix_out},
+	{name: "prefixoffset", Options: Options{TrimPrefix: "Size"}, input: prefixoffset_in, output: prefixoffset_out},
+	{name: "prefixgap", Options: Options{TrimPrefix: "Gap"}, input: prefixgap_in, output: prefixgap_out},
+	{name: "suffix", Options: Options{TrimSuffix: "Color"}, input: suffix_in, output: suffix_out},
+	{name: "linecomment", Options: Options{LineComment: true}, input: linecomment_in, output: linecomment_out},
+	{name: "snake", Options: Options{TrimPrefix: "Opt", Transform: TransformStyle("snake")}, input: transform_in, output: snake_out},
+	{name: "title", Options: Options{TrimPrefix: "Opt", Transform: TransformStyle("title")}, input: transform_in, output: title_out},
+	{name: "parse", Options: Options{Parse: true}, input: parse_in, output: offset_out + parse_out},
+	{name: "parsegap", Options: Options{Parse: true}, input: gap_in, output: gap_out + parsegap_out},
+	{name: "parsemap", Options: Options{Parse: true}, input: prime_in, output: prime_out + parsemap_out},
+	{name: "fold", Options: Options{Parse: true, Fold: true}, input: fold_in, output: fold_out},
+	{name: "error", Options: Options{Error: true, ErrorPrefix: true}, input: day_in, output: day_out + error_out},
+	{name: "erroronly", Options: Options{ErrorOnly: true, Text: true}, input: day_in, output: erroronly_out},
+	{name: "blocks", Options: Options{Blocks: true, Group: true}, input: blocks_in, output: blocks_out},
+	{name: "text", Options: Options{Text: true}, input: day_in, output: day_out + text_out},
+	{name: "method", Options: Options{Method: "Label", Ptr: true, Text: true}, input: day_in, output: method_out},
+	{name: "texterror", Options: Options{Text: true, TextInvalid: "error"}, input: day_in, output: day_out + isvalid_out + texterror_out},
+	{name: "textname", Options: Options{Text: true, TextInvalid: "name:Unknown"}, input: day_in, output: day_out + isvalid_out + textname_out},
+	{name: "gob", Options: Options{Gob: true}, input: day_in, output: day_out + isvalid_out + gob_out},
+	{name: "yaml", Options: Options{YAML: true}, input: day_in, output: day_out + isvalid_out + yaml_out},
+	{name: "binary", Options: Options{Binary: true}, input: day_in, output: day_out + isvalid_out + binary_out},
+	{name: "binarybyte", Options: Options{Binary: true}, input: unum2_in, output: unum2_out + isvalidoffset_out + binarybyte_out},
+	{name: "binaryfloat", Options: Options{Binary: true, NoFmt: true}, input: float_in, output: nofmtfloat_out + binaryfloat_out},
+	{name: "proto", Options: Options{Proto: true}, input: proto_in, output: proto_out},
+	{name: "json", Options: Options{JSON: true}, input: unum_in, output: unum_out + json_out},
+	{name: "sql", Options: Options{SQL: true}, input: offset_in, output: offset_out + sql_out},
+	{name: "values", Options: Options{Values: true}, input: unum_in, output: unum_out + values_out},
+	{name: "bounds", Options: Options{Bounds: true}, input: day_in, output: day_out + bounds_out},
+	{name: "boundsgap", Options: Options{Bounds: true}, input: gap_in, output: gap_out + boundsgap_out},
+	{name: "boundsnum", Options: Options{Bounds: true}, input: num_in, output: num_out + boundsnum_out},
+	{name: "description", Options: Options{Description: true}, input: description_in, output: description_out},
+	{name: "localize", Options: Options{Localize: true}, input: day_in, output: day_out + localize_out},
+	{name: "ordinal", Options: Options{Ordinal: true}, input: gap_in, output: gap_out + ordinal_out},
+	{name: "ordinalnum", Options: Options{Ordinal: true}, input: num_in, output: num_out + ordinalnum_out},
+	{name: "ordinalmap", Options: Options{Ordinal: true}, input: ordinalmap_in, output: ordinalmap_out},
+	{name: "iter", Options: Options{Iter: true}, input: gap_in, output: gap_out + iter_out},
+	{name: "strings", Options: Options{Strings: true}, input: unum_in, output: unum_out + strings_out},
+	{name: "isvalid", Options: Options{IsValid: true}, input: day_in, output: day_out + isvalid_out},
+	{name: "slog", Options: Options{Slog: true}, input: day_in, output: day_out + isvalid_out + slog_out},
+	{name: "isvalidoffset", Options: Options{IsValid: true}, input: unum2_in, output: unum2_out + isvalidoffset_out},
+	{name: "isvalidgap", Options: Options{IsValid: true}, input: gap_in, output: gap_out + isvalidgap_out},
+	{name: "char", Options: Options{Char: "fallback"}, input: char_in, output: char_out},
+	{name: "charall", Options: Options{Char: "all"}, input: char_in, output: charall_out},
+	{name: "ptr", Options: Options{Ptr: true, IsValid: true}, input: day_in, output: ptr_out},
+	{name: "isvalidmap", Options: Options{IsValid: true}, input: prime_in, output: prime_out + isvalidmap_out},
+	{name: "flags", Options: Options{Flags: true, IsValid: true}, input: flags_in, output: flags_out},
+	{name: "flagszero", Options: Options{Flags: true}, input: flagszero_in, output: flagszero_out},
+	{name: "invalidformat", Options: Options{Invalid: "unknown num %v"}, input: num_in, output: invalidformat_out},
+	{name: "invalidplain", Options: Options{Invalid: "?"}, input: day_in, output: invalidplain_out},
+	{name: "invalidempty", Options: Options{Invalid: "empty"}, input: gap_in, output: invalidempty_out},
+	{name: "invalidpanic", Options: Options{Invalid: "panic"}, input: prime_in, output: invalidpanic_out},
+	{name: "flagsempty", Options: Options{Flags: true, Invalid: "empty"}, input: flags_in, output: flagsempty_out},
+	{name: "flagspanic", Options: Options{Flags: true, Invalid: "panic"}, input: flags_in, output: flagspanic_out},
+	{name: "gostring", Options: Options{GoString: true}, input: gap_in, output: gap_out + isvalidgap_out + gostring_out},
+	{name: "format", Options: Options{Format: true}, input: gap_in, output: gap_out + isvalidgap_out + gostring_out + format_out},
+	{name: "gostringprefix", Options: Options{TrimPrefix: "Type", GoString: true}, input: prefix_in, output: prefix_out + gostringprefix_out},
+	{name: "sparse", Options: Options{TrimPrefix: "S", Parse: true, GoString: true}, input: sparse_in, output: sparse_out},
+	{name: "threshold", Options: Options{Threshold: 2}, input: gap_in, output: threshold_out},
+	{name: "lookupswitch", Options: Options{Lookup: "switch"}, input: prime_in, output: lookupswitch_out},
+	{name: "lookupmap", Options: Options{Lookup: "map"}, input: day_in, output: lookupmap_out},
+	{name: "nofmt", Options: Options{NoFmt: true, Parse: true, Text: true, GoString: true}, input: unum_in, output: nofmt_out},
+	{name: "nofmtpanic", Options: Options{NoFmt: true, Invalid: "panic"}, input: num_in, output: nofmtpanic_out},
+	{name: "nofmtfloat", Options: Options{NoFmt: true}, input: float_in, output: nofmtfloat_out},
+	{name: "binarysearch", Options: Options{Lookup: "binarysearch", IsValid: true}, input: sparse_in, output: binarysearch_out},
+	{name: "float", Options: Options{Parse: true}, input: float_in, output: float_out},
+	{name: "string", Options: Options{Parse: true, Values: true, Strings: true}, input: string_in, output: string_out},
+	{name: "gostringstring", Options: Options{GoString: true}, input: string_in, output: gostringstring_stringout + gostringstring_out},
+	{name: "prefixes", Options: Options{Prefixes: true}, input: prefixes_in, output: prefixes_out},
+	{name: "prefixesruns", Options: Options{Prefixes: true, Parse: true}, input: prefixesruns_in, output: prefixesruns_out},
+	{name: "prefixesnone", Options: Options{Prefixes: true}, input: day_in, output: day_out},
+}
+
+// Each example starts with "type XXX [u]int", with a single space separating them.
+
+// Simple test: enumeration of type int starting at 0.
+const day_in = `type Day int
+const (
+	Monday Day = iota
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+	Sunday
+)
+`
+
+const day_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50}
+
+func (i Day) String() string {
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return fmt.Sprintf("Day(%d)", i)
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+`
+
+// Enumeration with an offset.
+// Also includes a duplicate.
+const offset_in = `type Number int
+const (
+	_ Number = iota
+	One
+	Two
+	Three
+	AnotherOne = One  // Duplicate; note that AnotherOne doesn't appear below.
+)
+`
+
+const offset_out = `
+const _Number_name = "OneTwoThree"
+
+var _Number_index = [...]uint8{0, 3, 6, 11}
+
+func (i Number) String() string {
+	i -= 1
+	if i < 0 || i >= Number(len(_Number_index)-1) {
+		return fmt.Sprintf("Number(%d)", i+1)
+	}
+	return _Number_name[_Number_index[i]:_Number_index[i+1]]
+}
+`
+
+// The last of the duplicates is printed.
+const duplast_out = `
+const _Number_name = "AnotherOneTwoThree"
+
+var _Number_index = [...]uint8{0, 10, 13, 18}
+
+func (i Number) String() string {
+	i -= 1
+	if i < 0 || i >= Number(len(_Number_index)-1) {
+		return fmt.Sprintf("Number(%d)", i+1)
+	}
+	return _Number_name[_Number_index[i]:_Number_index[i+1]]
+}
+`
+
+// The alias is looked up by its own name.
+const valueof_out = `
+var _Number_valueOf = map[string]Number{
+	_Number_name[0:3]:  1,
+	_Number_name[3:6]:  2,
+	_Number_name[6:11]: 3,
+	"AnotherOne":       1,
+}
+
+// NumberValueOf returns the Number constant with the given name, either
+// that which String returns or, for an alias of another constant, its own,
+// and whether there is one.
+func NumberValueOf(name string) (Number, bool) {
+	i, ok := _Number_valueOf[name]
+	return i, ok
+}
+`
+
+// The names of the duplicates are joined.
+const dupjoin_out = `
+const _Number_name = "One/AnotherOneTwoThree"
+
+var _Number_index = [...]uint8{0, 14, 17, 22}
+
+func (i Number) String() string {
+	i -= 1
+	if i < 0 || i >= Number(len(_Number_index)-1) {
+		return fmt.Sprintf("Number(%d)", i+1)
+	}
+	return _Number_name[_Number_index[i]:_Number_index[i+1]]
+}
+`
+
+// Gaps and an offset.
+const gap_in = `type Gap int
+const (
+	Two Gap = 2
+	Three Gap = 3
+	Five Gap = 5
+	Six Gap = 6
+	Seven Gap = 7
+	Eight Gap = 8
+	Nine Gap = 9
+	Eleven Gap = 11
+)
+`
+
+const gap_out = `
+const (
+	_Gap_name_0 = "TwoThree"
+	_Gap_name_1 = "FiveSixSevenEightNine"
+	_Gap_name_2 = "Eleven"
+)
+
+var (
+	_Gap_index_0 = [...]uint8{0, 3, 8}
+	_Gap_index_1 = [...]uint8{0, 4, 7, 12, 17, 21}
+	_Gap_index_2 = [...]uint8{0, 6}
+)
+
+func (i Gap) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Gap_name_0[_Gap_index_0[i]:_Gap_index_0[i+1]]
+	case 5 <= i && i <= 9:
+		i -= 5
+		return _Gap_name_1[_Gap_index_1[i]:_Gap_index_1[i+1]]
+	case i == 11:
+		return _Gap_name_2
+	default:
+		return fmt.Sprintf("Gap(%d)", i)
+	}
+}
+`
+
+// Signed integers spanning zero.
+const num_in = `type Num int
+const (
+	m_2 Num = -2 + iota
+	m_1
+	m0
+	m1
+	m2
+)
+`
+
+const num_out = `
+const _Num_name = "m_2m_1m0m1m2"
+
+var _Num_index = [...]uint8{0, 3, 6, 8, 10, 12}
+
+func (i Num) String() string {
+	n := uint64(i) + 2
+	if n >= uint64(len(_Num_index)-1) {
+		return fmt.Sprintf("Num(%d)", i)
+	}
+	return _Num_name[_Num_index[n]:_Num_index[n+1]]
+}
+`
+
+// Unsigned integers spanning zero.
+const unum_in = `type Unum uint
+const (
+	m_2 Unum = iota + 253
+	m_1
+)
+
+const (
+	m0 Unum = iota
+	m1
+	m2
+)
+`
+
+const unum_out = `
+const (
+	_Unum_name_0 = "m0m1m2"
+	_Unum_name_1 = "m_2m_1"
+)
+
+var (
+	_Unum_index_0 = [...]uint8{0, 2, 4, 6}
+	_Unum_index_1 = [...]uint8{0, 3, 6}
+)
+
+func (i Unum) String() string {
+	switch {
+	case 0 <= i && i <= 2:
+		return _Unum_name_0[_Unum_index_0[i]:_Unum_index_0[i+1]]
+	case 253 <= i && i <= 254:
+		i -= 253
+		return _Unum_name_1[_Unum_index_1[i]:_Unum_index_1[i+1]]
+	default:
+		return fmt.Sprintf("Unum(%d)", i)
+	}
+}
+`
+
+// Signed integers starting at math.MinInt64. The position in the run is
+// found without subtracting the constant, whose negation overflows.
+const min_in = `type Low int64
+const (
+	Lowest Low = -9223372036854775808 + iota
+	Lower
+	Low3
+)
+`
+
+const min_out = `
+const _Low_name = "LowestLowerLow3"
+
+var _Low_index = [...]uint8{0, 6, 11, 15}
+
+func (i Low) String() string {
+	n := uint64(i) + 9223372036854775808
+	if n >= uint64(len(_Low_index)-1) {
+		return fmt.Sprintf("Low(%d)", i)
+	}
+	return _Low_name[_Low_index[n]:_Low_index[n+1]]
+}
+
+// IsValid reports whether i is the value of one of the Low constants.
+func (i Low) IsValid() bool {
+	return uint64(i)+9223372036854775808 < uint64(len(_Low_index)-1)
+}
+
+// Ordinal returns the position of i, from 0, among the values of the Low
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+func (i Low) Ordinal() int {
+	switch {
+	case -9223372036854775808 <= i && i <= -9223372036854775806:
+		return int(uint64(i) + 9223372036854775808)
+	}
+	return -1
+}
+`
+
+// Signed integers ending at math.MaxInt64.
+const max_in = `type Top int64
+const (
+	Higher Top = 9223372036854775806 + iota
+	Highest
+)
+`
+
+const max_out = `
+const _Top_name = "HigherHighest"
+
+var _Top_index = [...]uint8{0, 6, 13}
+
+func (i Top) String() string {
+	i -= 9223372036854775806
+	if i < 0 || i >= Top(len(_Top_index)-1) {
+		return fmt.Sprintf("Top(%d)", i+9223372036854775806)
+	}
+	return _Top_name[_Top_index[i]:_Top_index[i+1]]
+}
+
+// IsValid reports whether i is the value of one of the Top constants.
+func (i Top) IsValid() bool {
+	i -= 9223372036854775806
+	return 0 <= i && i < Top(len(_Top_index)-1)
+}
+
+// Ordinal returns the position of i, from 0, among the values of the Top
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+func (i Top) Ordinal() int {
+	switch {
+	case 9223372036854775806 <= i && i <= 9223372036854775807:
+		return int(i - 9223372036854775806)
+	}
+	return -1
+}
+`
+
+// Runs at both ends of int64.
+const extremes_in = `type Ext int64
+const (
+	Min Ext = -9223372036854775808 + iota
+	MinPlus1
+)
+
+const (
+	MaxMinus1 Ext = 9223372036854775806 + iota
+	Max
+)
+`
+
+const extremes_out = `
+const (
+	_Ext_name_0 = "MinMinPlus1"
+	_Ext_name_1 = "MaxMinus1Max"
+)
+
+var (
+	_Ext_index_0 = [...]uint8{0, 3, 11}
+	_Ext_index_1 = [...]uint8{0, 9, 12}
+)
+
+func (i Ext) String() string {
+	switch {
+	case -9223372036854775808 <= i && i <= -9223372036854775807:
+		n := uint64(i) + 9223372036854775808
+		return _Ext_name_0[_Ext_index_0[n]:_Ext_index_0[n+1]]
+	case 9223372036854775806 <= i && i <= 9223372036854775807:
+		i -= 9223372036854775806
+		return _Ext_name_1[_Ext_index_1[i]:_Ext_index_1[i+1]]
+	default:
+		return fmt.Sprintf("Ext(%d)", i)
+	}
+}
+
+// IsValid reports whether i is the value of one of the Ext constants.
+func (i Ext) IsValid() bool {
+	return -9223372036854775808 <= i && i <= -9223372036854775807 ||
+		9223372036854775806 <= i && i <= 9223372036854775807
+}
+
+// Ordinal returns the position of i, from 0, among the values of the Ext
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+func (i Ext) Ordinal() int {
+	switch {
+	case -9223372036854775808 <= i && i <= -9223372036854775807:
+		return int(uint64(i) + 9223372036854775808)
+	case 9223372036854775806 <= i && i <= 9223372036854775807:
+		return int(i-9223372036854775806) + 2
+	}
+	return -1
+}
+`
+
+// Enough gaps to trigger a map implementation of the method.
+// Also includes a duplicate to test that it doesn't cause problems
+const prime_in = `type Prime int
+const (
+	p2 Prime = 2
+	p3 Prime = 3
+	p5 Prime = 5
+	p7 Prime = 7
+	p77 Prime = 7 // Duplicate; note that p77 doesn't appear below.
+	p11 Prime = 11
+	p13 Prime = 13
+	p17 Prime = 17
+	p19 Prime = 19
+	p23 Prime = 23
+	p29 Prime = 29
+	p37 Prime = 31
+	p41 Prime = 41
+	p43 Prime = 43
+)
+`
+
+const prime_out = `
+const _Prime_name = "p2p3p5p7p11p13p17p19p23p29p37p41p43"
+
+var _Prime_map = map[Prime]string{
+	2:  _Prime_name[0:2],
+	3:  _Prime_name[2:4],
+	5:  _Prime_name[4:6],
+	7:  _Prime_name[6:8],
+	11: _Prime_name[8:11],
+	13: _Prime_name[11:14],
+	17: _Prime_name[14:17],
+	19: _Prime_name[17:20],
+	23: _Prime_name[20:23],
+	29: _Prime_name[23:26],
+	31: _Prime_name[26:29],
+	41: _Prime_name[29:32],
+	43: _Prime_name[32:35],
+}
+
+func (i Prime) String() string {
+	if str, ok := _Prime_map[i]; ok {
+		return str
+	}
+	return fmt.Sprintf("Prime(%d)", i)
+}
+`
+
+// Trimmed prefix.
+const prefix_in = `type Type int
+const (
+	TypeInt Type = iota
+	TypeString
+	TypeFloat
+	TypeRune
+	TypeByte
+	TypeStruct
+	TypeSlice
+)
+`
+
+const prefix_out = `
+const _Type_name = "IntStringFloatRuneByteStructSlice"
+
+var _Type_index = [...]uint8{0, 3, 9, 14, 18, 22, 28, 33}
+
+func (i Type) String() string {
+	if i < 0 || i >= Type(len(_Type_index)-1) {
+		return fmt.Sprintf("Type(%d)", i)
+	}
+	return _Type_name[_Type_index[i]:_Type_index[i+1]]
+}
+`
+
+// Powers of two are found by the number of the bit set.
+const bits_in = `type Mode int8
+const (
+	Read Mode = 1 << iota
+	Write
+	_
+	Exec
+	Append Mode = 1 << 6
+)
+`
+
+const bits_out = `
+const _Mode_name = "ReadWriteExecAppend"
+
+const _Mode_mask = 0x4b
+
+var _Mode_index = [...]uint8{0, 4, 9, 9, 13, 13, 13, 19}
+
+func (i Mode) String() string {
+	if i&(i-1) != 0 || i&_Mode_mask == 0 {
+		return fmt.Sprintf("Mode(%d)", i)
+	}
+	n := bits.TrailingZeros64(uint64(i))
+	return _Mode_name[_Mode_index[n]:_Mode_index[n+1]]
+}
+
+// IsValid reports whether i is the value of one of the Mode constants.
+func (i Mode) IsValid() bool {
+	return i&(i-1) == 0 && i&_Mode_mask != 0
+}
+`
+
+// Trimmed suffix. Names lacking the suffix are left alone.
+const suffix_in = `type Color int
+const (
+	RedColor Color = iota
+	GreenColor
+	BlueColor
+	Black
+)
+`
+
+const suffix_out = `
+const _Color_name = "RedGreenBlueBlack"
+
+var _Color_index = [...]uint8{0, 3, 8, 12, 17}
+
+func (i Color) String() string {
+	if i < 0 || i >= Color(len(_Color_index)-1) {
+		return fmt.Sprintf("Color(%d)", i)
+	}
+	return _Color_name[_Color_index[i]:_Color_index[i+1]]
+}
+`
+
+// Trimmed prefix with an offset. Names lacking the prefix are left alone.
+const prefixoffset_in = `type Size int
+const (
+	SizeSmall Size = iota + 1
+	SizeMedium
+	Large
+)
+`
+
+const prefixoffset_out = `
+const _Size_name = "SmallMediumLarge"
+
+var _Size_index = [...]uint8{0, 5, 11, 16}
+
+func (i Size) String() string {
+	i -= 1
+	if i < 0 || i >= Size(len(_Size_index)-1) {
+		return fmt.Sprintf("Size(%d)", i+1)
+	}
+	return _Size_name[_Size_index[i]:_Size_index[i+1]]
+}
+`
+
+// Trimmed prefix with gaps.
+const prefixgap_in = `type Gap int
+const (
+	GapTwo Gap = 2
+	GapThree Gap = 3
+	GapFive Gap = 5
+	GapSix Gap = 6
+	GapEleven Gap = 11
+)
+`
+
+const prefixgap_out = `
+const (
+	_Gap_name_0 = "TwoThree"
+	_Gap_name_1 = "FiveSix"
+	_Gap_name_2 = "Eleven"
+)
+
+var (
+	_Gap_index_0 = [...]uint8{0, 3, 8}
+	_Gap_index_1 = [...]uint8{0, 4, 7}
+	_Gap_index_2 = [...]uint8{0, 6}
+)
+
+func (i Gap) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Gap_name_0[_Gap_index_0[i]:_Gap_index_0[i+1]]
+	case 5 <= i && i <= 6:
+		i -= 5
+		return _Gap_name_1[_Gap_index_1[i]:_Gap_index_1[i+1]]
+	case i == 11:
+		return _Gap_name_2
+	default:
+		return fmt.Sprintf("Gap(%d)", i)
+	}
+}
+`
+
+// Line comments as printed names.
+const linecomment_in = `type Status int
+const (
+	StatusOK Status = iota // OK
+	StatusNotFound // Not Found
+	StatusTeapot
+	// Doc comments are not line comments.
+	StatusGone // Gone
+)
+`
+
+const linecomment_out = `
+const _Status_name = "OKNot FoundStatusTeapotGone"
+
+var _Status_index = [...]uint8{0, 2, 11, 23, 27}
+
+func (i Status) String() string {
+	if i < 0 || i >= Status(len(_Status_index)-1) {
+		return fmt.Sprintf("Status(%d)", i)
+	}
+	return _Status_name[_Status_index[i]:_Status_index[i+1]]
+}
+`
+
+// Transformed names, after trimming the prefix.
+const transform_in = `type Opt int
+const (
+	OptMaxRetries Opt = iota
+	OptHTTPProxy
+	OptUserID
+)
+`
+
+const snake_out = `
+const _Opt_name = "max_retrieshttp_proxyuser_id"
+
+var _Opt_index = [...]uint8{0, 11, 21, 28}
+
+func (i Opt) String() string {
+	if i < 0 || i >= Opt(len(_Opt_index)-1) {
+		return fmt.Sprintf("Opt(%d)", i)
+	}
+	return _Opt_name[_Opt_index[i]:_Opt_index[i+1]]
+}
+`
+
+const title_out = `
+const _Opt_name = "Max RetriesHTTP ProxyUser ID"
+
+var _Opt_index = [...]uint8{0, 11, 21, 28}
+
+func (i Opt) String() string {
+	if i < 0 || i >= Opt(len(_Opt_index)-1) {
+		return fmt.Sprintf("Opt(%d)", i)
+	}
+	return _Opt_name[_Opt_index[i]:_Opt_index[i+1]]
+}
+`
+
+// Parse functions, appended to the String methods of earlier examples.
+// The reference to the function yet to be generated must not stop stringer.
+const parse_in = offset_in + `
+var one, _ = ParseNumber("One")
+`
+
+const parse_out = `
+var _Number_value = map[string]Number{
+	_Number_name[0:3]:  1,
+	_Number_name[3:6]:  2,
+	_Number_name[6:11]: 3,
+}
+
+// ParseNumber returns the Number whose String method returns s.
+func ParseNumber(s string) (Number, error) {
+	if i, ok := _Number_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Number %q", s)
+}
+`
+
+// Aliases, including one of a duplicate value, in doc and line comments.
+const fold_in = `type Day int
+
+const (
+	Monday Day = iota //stringer:alias Mon
+	Tuesday           //stringer:alias Tue Tues
+	// Wednesday is midweek.
+	//stringer:alias Wed
+	Wednesday
+	Midweek = Wednesday //stringer:alias mid
+)
+`
+
+const fold_out = `
+const _Day_name = "MondayTuesdayWednesday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22}
+
+func (i Day) String() string {
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return fmt.Sprintf("Day(%d)", i)
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	"Mon":            0,
+	"Tue":            1,
+	"Tues":           1,
+	"Wed":            2,
+	"mid":            2,
+}
+
+// ParseDay returns the Day whose String method returns s, or that has the alias s, in any case.
+func ParseDay(s string) (Day, error) {
+	if i, ok := _Day_value[s]; ok {
+		return i, nil
+	}
+	for name, i := range _Day_value {
+		if strings.EqualFold(name, s) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid Day %q", s)
+}
+`
+
+const error_out = `
+// Error implements the error interface, so that the constants of Day can
+// be returned as errors.
+func (i Day) Error() string {
+	return "Day: " + i.String()
+}
+`
+
+const erroronly_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50}
+
+func (i Day) Error() string {
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return fmt.Sprintf("Day(%d)", i)
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Day) MarshalText() ([]byte, error) {
+	return []byte(i.Error()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Day) UnmarshalText(text []byte) error {
+	v, ok := _Day_value[string(text)]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Codes in two groups, the first split into two runs, and a block without a
+// group whose run would join one of the first group but for -blocks.
+const blocks_in = `type Status int
+
+// Client errors.
+//
+//stringer:group client
+const (
+	BadRequest   Status = 400
+	Unauthorized Status = 401
+	NotFound     Status = 404
+)
+
+//stringer:group server
+const (
+	Internal    Status = 500
+	Unavailable Status = 503
+)
+
+const Teapot Status = 402
+`
+
+const blocks_out = `
+
+const _Status_block0_name = "BadRequestUnauthorizedNotFound"
+
+var _Status_block0_index = [...]uint8{0, 10, 22, 30}
+
+const _Status_block1_name = "Teapot"
+
+var _Status_block1_index = [...]uint8{0, 6}
+
+const _Status_block2_name = "InternalUnavailable"
+
+var _Status_block2_index = [...]uint8{0, 8, 19}
+
+func (i Status) String() string {
+	switch {
+	case 400 <= i && i <= 401:
+		i -= 400
+		return _Status_block0_name[_Status_block0_index[i]:_Status_block0_index[i+1]]
+	case i == 402:
+		return _Status_block1_name[0:6]
+	case i == 404:
+		return _Status_block0_name[22:30]
+	case i == 500:
+		return _Status_block2_name[0:8]
+	case i == 503:
+		return _Status_block2_name[8:19]
+	default:
+		return fmt.Sprintf("Status(%d)", i)
+	}
+}
+
+// Group returns the name of the group of the Status constants with the value
+// of i, given by the //stringer:group comment on the const block
+// declaring them, or "" if it has none or i is not the value of a constant.
+func (i Status) Group() string {
+	switch i {
+	case 400, 401, 404:
+		return "client"
+	case 500, 503:
+		return "server"
+	}
+	return ""
+}
+`
+
+const parsegap_out = `
+var _Gap_value = map[string]Gap{
+	_Gap_name_0[0:3]:   2,
+	_Gap_name_0[3:8]:   3,
+	_Gap_name_1[0:4]:   5,
+	_Gap_name_1[4:7]:   6,
+	_Gap_name_1[7:12]:  7,
+	_Gap_name_1[12:17]: 8,
+	_Gap_name_1[17:21]: 9,
+	_Gap_name_2[0:6]:   11,
+}
+
+// ParseGap returns the Gap whose String method returns s.
+func ParseGap(s string) (Gap, error) {
+	if i, ok := _Gap_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Gap %q", s)
+}
+`
+
+const parsemap_out = `
+var _Prime_value = map[string]Prime{
+	_Prime_name[0:2]:   2,
+	_Prime_name[2:4]:   3,
+	_Prime_name[4:6]:   5,
+	_Prime_name[6:8]:   7,
+	_Prime_name[8:11]:  11,
+	_Prime_name[11:14]: 13,
+	_Prime_name[14:17]: 17,
+	_Prime_name[17:20]: 19,
+	_Prime_name[20:23]: 23,
+	_Prime_name[23:26]: 29,
+	_Prime_name[26:29]: 31,
+	_Prime_name[29:32]: 41,
+	_Prime_name[32:35]: 43,
+}
+
+// ParsePrime returns the Prime whose String method returns s.
+func ParsePrime(s string) (Prime, error) {
+	if i, ok := _Prime_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Prime %q", s)
+}
+`
+
+// Text marshaling methods.
+const text_out = `
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Day) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Day) UnmarshalText(text []byte) error {
+	v, ok := _Day_value[string(text)]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+const method_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50}
+
+func (p *Day) Label() string {
+	if p == nil {
+		return "<nil>"
+	}
+	i := *p
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return fmt.Sprintf("Day(%d)", i)
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p *Day) MarshalText() ([]byte, error) {
+	if p == nil {
+		return nil, nil
+	}
+	i := *p
+	return []byte(i.Label()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Day) UnmarshalText(text []byte) error {
+	v, ok := _Day_value[string(text)]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+// With -textinvalid=error, MarshalText fails for values with no name.
+const texterror_out = `
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Day) MarshalText() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid Day %d", i)
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Day) UnmarshalText(text []byte) error {
+	v, ok := _Day_value[string(text)]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+// With -textinvalid=name:Unknown, it writes Unknown for them instead.
+const textname_out = `
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Day) MarshalText() ([]byte, error) {
+	if !i.IsValid() {
+		return []byte("Unknown"), nil
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Day) UnmarshalText(text []byte) error {
+	v, ok := _Day_value[string(text)]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", text)
+	}
+	*i = v
+	return nil
+}
+`
+
+// JSON marshaling methods for an unsigned type, which need IsValid.
+const json_out = `
+// IsValid reports whether i is the value of one of the Unum constants.
+func (i Unum) IsValid() bool {
+	return 0 <= i && i <= 2 ||
+		253 <= i && i <= 254
+}
+
+var _Unum_value = map[string]Unum{
+	_Unum_name_0[0:2]: 0,
+	_Unum_name_0[2:4]: 1,
+	_Unum_name_0[4:6]: 2,
+	_Unum_name_1[0:3]: 253,
+	_Unum_name_1[3:6]: 254,
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (i Unum) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts the name
+// of a Unum constant or, for compatibility with numeric encodings, its value.
+// Other names and numbers are rejected with a *json.UnmarshalTypeError.
+func (i *Unum) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if v, ok := _Unum_value[s]; ok {
+			*i = v
+			return nil
+		}
+		return &json.UnmarshalTypeError{Value: fmt.Sprintf("string %q", s), Type: reflect.TypeOf(*i)}
+	}
+	var n uint64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	if v := Unum(n); uint64(v) == n && v.IsValid() {
+		*i = v
+		return nil
+	}
+	return &json.UnmarshalTypeError{Value: "number " + string(data), Type: reflect.TypeOf(*i)}
+}
+`
+
+// SQL methods, which need IsValid.
+const sql_out = `
+// IsValid reports whether i is the value of one of the Number constants.
+func (i Number) IsValid() bool {
+	i -= 1
+	return 0 <= i && i < Number(len(_Number_index)-1)
+}
+
+var _Number_value = map[string]Number{
+	_Number_name[0:3]:  1,
+	_Number_name[3:6]:  2,
+	_Number_name[6:11]: 3,
+}
+
+// Value implements the driver.Valuer interface, storing the name of i.
+func (i Number) Value() (driver.Value, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid Number %d", i)
+	}
+	return i.String(), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting the name of a Number
+// as a string or []byte. A NULL leaves i unchanged.
+func (i *Number) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Number", value)
+	}
+	v, ok := _Number_value[s]
+	if !ok {
+		return fmt.Errorf("invalid Number %q", s)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Values function, in declaration order rather than numeric order.
+const values_out = `
+var _Unum_values = []Unum{m_2, m_1, m0, m1, m2}
+
+// UnumValues returns the values of the Unum constants, in the order they
+// are declared. Constants with the same value as an earlier one are omitted.
+func UnumValues() []Unum {
+	return append([]Unum(nil), _Unum_values...)
+}
+`
+
+// Strings function, aligned with the Values function.
+const strings_out = `
+var _Unum_strings = []string{_Unum_name_1[0:3], _Unum_name_1[3:6], _Unum_name_0[0:2], _Unum_name_0[2:4], _Unum_name_0[4:6]}
+
+// UnumStrings returns the names of the Unum constants, in the order they
+// are declared and so aligned with UnumValues.
+func UnumStrings() []string {
+	return append([]string(nil), _Unum_strings...)
+}
+`
+
+// IsValid methods for each representation.
+// Gob methods use IsValid and the name map.
+const gob_out = `
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// GobEncode implements the gob.GobEncoder interface, encoding i by name so
+// that encoded values survive renumbering of the constants.
+func (i Day) GobEncode() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid Day %d", i)
+	}
+	return []byte(i.String()), nil
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (i *Day) GobDecode(data []byte) error {
+	v, ok := _Day_value[string(data)]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", string(data))
+	}
+	*i = v
+	return nil
+}
+`
+
+// YAML methods use IsValid and the name map.
+const yaml_out = `
+var _Day_value = map[string]Day{
+	_Day_name[0:6]:   0,
+	_Day_name[6:13]:  1,
+	_Day_name[13:22]: 2,
+	_Day_name[22:30]: 3,
+	_Day_name[30:36]: 4,
+	_Day_name[36:44]: 5,
+	_Day_name[44:50]: 6,
+}
+
+// MarshalYAML implements the yaml.Marshaler interface, encoding i by name.
+func (i Day) MarshalYAML() (interface{}, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid Day %d", i)
+	}
+	return i.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, accepting the
+// name of a Day.
+func (i *Day) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, ok := _Day_value[s]
+	if !ok {
+		return fmt.Errorf("invalid Day %q", s)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Bounds of consecutive values.
+const bounds_out = `
+// DayMin and DayMax are the least and greatest values of the Day
+// constants, and DayCount is the number of distinct values.
+const (
+	DayMin   Day = 0
+	DayMax   Day = 6
+	DayCount     = 7
+)
+`
+
+// With gaps, the count is less than the span.
+const boundsgap_out = `
+// GapMin and GapMax are the least and greatest values of the Gap
+// constants, and GapCount is the number of distinct values.
+const (
+	GapMin   Gap = 2
+	GapMax   Gap = 11
+	GapCount     = 8
+)
+`
+
+// Signed bounds.
+const boundsnum_out = `
+// NumMin and NumMax are the least and greatest values of the Num
+// constants, and NumCount is the number of distinct values.
+const (
+	NumMin   Num = -2
+	NumMax   Num = 2
+	NumCount     = 5
+)
+`
+
+// Descriptions come from the doc comments, not the line comments, and
+// aliases do not replace them.
+const description_in = `type Level int
+
+// The levels of logging.
+const (
+	// Debug messages are for developers only.
+	Debug Level = iota
+	Info // Line comments are not descriptions.
+	/* Warn is for problems
+	   that are not errors. */
+	Warn
+	// An error stops the request,
+	// but not the server.
+	Error
+	// Severe is an alias for Error.
+	Severe = Error
+)
+
+// Fatal stops the server.
+const Fatal Level = 7
+`
+
+const description_out = `
+const (
+	_Level_name_0 = "DebugInfoWarnError"
+	_Level_name_1 = "Fatal"
+)
+
+var (
+	_Level_index_0 = [...]uint8{0, 5, 9, 13, 18}
+	_Level_index_1 = [...]uint8{0, 5}
+)
+
+func (i Level) String() string {
+	switch {
+	case 0 <= i && i <= 3:
+		return _Level_name_0[_Level_index_0[i]:_Level_index_0[i+1]]
+	case i == 7:
+		return _Level_name_1
+	default:
+		return fmt.Sprintf("Level(%d)", i)
+	}
+}
+
+var _Level_description = map[Level]string{
+	0: "Debug messages are for developers only.",
+	2: "Warn is for problems that are not errors.",
+	3: "An error stops the request, but not the server.",
+	7: "Fatal stops the server.",
+}
+
+// Description returns the doc comment of the Level constant with the value of
+// i, on one line, or the empty string if there is none.
+func (i Level) Description() string {
+	return _Level_description[i]
+}
+`
+
+// The keys name the constants, and the hook translates them.
+const localize_out = `
+var _Day_keys = map[Day]string{
+	0: "Day.Monday",
+	1: "Day.Tuesday",
+	2: "Day.Wednesday",
+	3: "Day.Thursday",
+	4: "Day.Friday",
+	5: "Day.Saturday",
+	6: "Day.Sunday",
+}
+
+// LocalizeDay, if set, returns the translation into the language lang, a BCP 47
+// tag such as "fr-CA", of the message with the given key, which names a value
+// of Day, and reports whether there is one. It may, for instance, look the
+// key up in a golang.org/x/text/message catalog.
+var LocalizeDay func(lang, key string) (string, bool)
+
+// MessageKey returns the key under which the name of i is translated, which
+// is that of the Day constant qualified by the type, as in Day.Name, or
+// the empty string if i is not the value of a constant.
+func (i Day) MessageKey() string {
+	return _Day_keys[i]
+}
+
+// Localized returns the name of i translated into the language lang by
+// LocalizeDay or, if there is no translation, the one String returns.
+func (i Day) Localized(lang string) string {
+	if key := i.MessageKey(); key != "" && LocalizeDay != nil {
+		if s, ok := LocalizeDay(lang, key); ok {
+			return s
+		}
+	}
+	return i.String()
+}
+`
+
+// Ordinal counts the values of the earlier runs.
+const ordinal_out = `
+// Ordinal returns the position of i, from 0, among the values of the Gap
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+func (i Gap) Ordinal() int {
+	switch {
+	case 2 <= i && i <= 3:
+		return int(i - 2)
+	case 5 <= i && i <= 9:
+		return int(i-5) + 2
+	case i == 11:
+		return 7
+	}
+	return -1
+}
+`
+
+// A negative offset.
+const ordinalnum_out = `
+// Ordinal returns the position of i, from 0, among the values of the Num
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+func (i Num) Ordinal() int {
+	switch {
+	case -2 <= i && i <= 2:
+		return int(uint64(i) + 2)
+	}
+	return -1
+}
+`
+
+// Values declared out of order need a map.
+const ordinalmap_in = `type Code int
+const (
+	Zero Code = 0
+	Ten Code = 10
+	Five Code = 5
+)
+`
+
+const ordinalmap_out = `
+const (
+	_Code_name_0 = "Zero"
+	_Code_name_1 = "Five"
+	_Code_name_2 = "Ten"
+)
+
+var (
+	_Code_index_0 = [...]uint8{0, 4}
+	_Code_index_1 = [...]uint8{0, 4}
+	_Code_index_2 = [...]uint8{0, 3}
+)
+
+func (i Code) String() string {
+	switch {
+	case i == 0:
+		return _Code_name_0
+	case i == 5:
+		return _Code_name_1
+	case i == 10:
+		return _Code_name_2
+	default:
+		return fmt.Sprintf("Code(%d)", i)
+	}
+}
+
+// Ordinal returns the position of i, from 0, among the values of the Code
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+func (i Code) Ordinal() int {
+	if n, ok := _Code_ordinal[i]; ok {
+		return n
+	}
+	return -1
+}
+
+var _Code_ordinal = map[Code]int{
+	0:  0,
+	10: 1,
+	5:  2,
+}
+`
+
+// Iteration skips the gaps.
+const iter_out = `
+var _Gap_sorted = [...]Gap{2, 3, 5, 6, 7, 8, 9, 11}
+
+// FirstGap and LastGap are the least and greatest values of the Gap constants,
+// so that a loop such as
+//
+//	for v, ok := FirstGap, true; ok; v, ok = v.Next() {
+//
+// visits each value once, in increasing order, and no other.
+const (
+	FirstGap Gap = 2
+	LastGap  Gap = 11
+)
+
+// Next returns the least value of the Gap constants that is greater than i,
+// and true; or, if there is none, i and false.
+func (i Gap) Next() (Gap, bool) {
+	n := sort.Search(len(_Gap_sorted), func(j int) bool { return _Gap_sorted[j] > i })
+	if n == len(_Gap_sorted) {
+		return i, false
+	}
+	return _Gap_sorted[n], true
+}
+
+// Prev returns the greatest value of the Gap constants that is less than i,
+// and true; or, if there is none, i and false.
+func (i Gap) Prev() (Gap, bool) {
+	n := sort.Search(len(_Gap_sorted), func(j int) bool { return _Gap_sorted[j] >= i })
+	if n == 0 {
+		return i, false
+	}
+	return _Gap_sorted[n-1], true
+}
+`
+
+// Binary methods use IsValid and a fixed-width big-endian encoding.
+const binary_out = `
+// MarshalBinary implements the encoding.BinaryMarshaler interface, encoding
+// i in 8 bytes, big-endian.
+func (i Day) MarshalBinary() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid Day %d", i)
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(i))
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface,
+// accepting only the values of the Day constants.
+func (i *Day) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errors.New("invalid Day encoding: length is not 8")
+	}
+	x := binary.BigEndian.Uint64(data)
+	v := Day(x)
+	if !v.IsValid() {
+		return fmt.Errorf("invalid Day %d", v)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Eight-bit types encode in a single byte.
+const binarybyte_out = `
+// MarshalBinary implements the encoding.BinaryMarshaler interface, encoding
+// i in 1 bytes, big-endian.
+func (i Unum2) MarshalBinary() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid Unum2 %d", i)
+	}
+	b := []byte{uint8(i)}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface,
+// accepting only the values of the Unum2 constants.
+func (i *Unum2) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("invalid Unum2 encoding: length is not 1")
+	}
+	x := data[0]
+	v := Unum2(x)
+	if !v.IsValid() {
+		return fmt.Errorf("invalid Unum2 %d", v)
+	}
+	*i = v
+	return nil
+}
+`
+
+// Floats encode their IEEE 754 bits.
+const binaryfloat_out = `
+// IsValid reports whether i is the value of one of the Rate constants.
+func (i Rate) IsValid() bool {
+	_, ok := _Rate_map[i]
+	return ok
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, encoding
+// i in 8 bytes, big-endian.
+func (i Rate) MarshalBinary() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, errors.New("invalid Rate " + strconv.FormatFloat(float64(i), 'g', -1, 64))
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(float64(i)))
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface,
+// accepting only the values of the Rate constants.
+func (i *Rate) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errors.New("invalid Rate encoding: length is not 8")
+	}
+	x := binary.BigEndian.Uint64(data)
+	v := Rate(math.Float64frombits(x))
+	if !v.IsValid() {
+		return errors.New("invalid Rate " + strconv.FormatFloat(float64(v), 'g', -1, 64))
+	}
+	*i = v
+	return nil
+}
+`
+
+// Protocol buffer style maps, in declaration order, with an alias.
+const proto_in = `type Code int32
+const (
+	OK Code = iota
+	Canceled
+	Unknown
+	Cancelled = Canceled
+	NotFound Code = 5
+	Internal Code = -1
+)
+`
+
+const proto_out = `
+const (
+	_Code_name_0 = "InternalOKCanceledUnknown"
+	_Code_name_1 = "NotFound"
+)
+
+var (
+	_Code_index_0 = [...]uint8{0, 8, 10, 18, 25}
+	_Code_index_1 = [...]uint8{0, 8}
+)
+
+func (i Code) String() string {
+	switch {
+	case -1 <= i && i <= 2:
+		n := uint64(i) + 1
+		return _Code_name_0[_Code_index_0[n]:_Code_index_0[n+1]]
+	case i == 5:
+		return _Code_name_1
+	default:
+		return fmt.Sprintf("Code(%d)", i)
+	}
+}
+
+var Code_name = map[int32]string{
+	0:  "OK",
+	1:  "Canceled",
+	2:  "Unknown",
+	5:  "NotFound",
+	-1: "Internal",
+}
+
+var Code_value = map[string]int32{
+	"OK":        0,
+	"Canceled":  1,
+	"Unknown":   2,
+	"Cancelled": 1,
+	"NotFound":  5,
+	"Internal":  -1,
+}
+`
+
+const isvalid_out = `
+// IsValid reports whether i is the value of one of the Day constants.
+func (i Day) IsValid() bool {
+	return 0 <= i && i < Day(len(_Day_index)-1)
+}
+`
+
+// With -ptr, the methods take pointers and copy the value.
+const ptr_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50}
+
+func (p *Day) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	i := *p
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return fmt.Sprintf("Day(%d)", i)
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+
+// IsValid reports whether i is the value of one of the Day constants.
+func (p *Day) IsValid() bool {
+	if p == nil {
+		return false
+	}
+	i := *p
+	return 0 <= i && i < Day(len(_Day_index)-1)
+}
+`
+
+const unum2_in = `type Unum2 uint8
+const (
+	Zero Unum2 = iota + 2
+	One
+	Two
+)
+`
+
+const unum2_out = `
+const _Unum2_name = "ZeroOneTwo"
+
+var _Unum2_index = [...]uint8{0, 4, 7, 10}
+
+func (i Unum2) String() string {
+	i -= 2
+	if i >= Unum2(len(_Unum2_index)-1) {
+		return fmt.Sprintf("Unum2(%d)", i+2)
+	}
+	return _Unum2_name[_Unum2_index[i]:_Unum2_index[i+1]]
+}
+`
+
+// The LogValue method for log/slog, which needs IsValid.
+const slog_out = `
+// LogValue implements the slog.LogValuer interface, logging the name of i or,
+// if it has none, a group of what String prints and the number.
+func (i Day) LogValue() slog.Value {
+	if !i.IsValid() {
+		return slog.GroupValue(slog.String("name", i.String()), slog.Int64("code", int64(i)))
+	}
+	return slog.StringValue(i.String())
+}
+`
+
+const isvalidoffset_out = `
+// IsValid reports whether i is the value of one of the Unum2 constants.
+func (i Unum2) IsValid() bool {
+	i -= 2
+	return i < Unum2(len(_Unum2_index)-1)
+}
+`
+
+const isvalidgap_out = `
+// IsValid reports whether i is the value of one of the Gap constants.
+func (i Gap) IsValid() bool {
+	return 2 <= i && i <= 3 ||
+		5 <= i && i <= 9 ||
+		i == 11
+}
+`
+
+const isvalidmap_out = `
+// IsValid reports whether i is the value of one of the Prime constants.
+func (i Prime) IsValid() bool {
+	_, ok := _Prime_map[i]
+	return ok
+}
+`
+
+// Bit flags, with a combination of flags that is ignored.
+const flags_in = `type Perm uint
+const (
+	Exec Perm = 1 << iota
+	Write
+	Read
+	_
+	Sticky
+	ReadWrite = Read | Write
+)
+`
+
+const flags_out = `
+const _Perm_name = "ExecWriteReadSticky"
+
+var _Perm_flags = [...]struct {
+	flag Perm
+	name string
+}{
+	{1, _Perm_name[0:4]},
+	{2, _Perm_name[4:9]},
+	{4, _Perm_name[9:13]},
+	{16, _Perm_name[13:19]},
+}
+
+func (i Perm) String() string {
+	if i == 0 {
+		return "Perm(0)"
+	}
+	var s string
+	for _, f := range _Perm_flags {
+		if i&f.flag != 0 {
+			s += "|" + f.name
+			i &^= f.flag
+		}
+	}
+	if i != 0 {
+		s += "|" + fmt.Sprintf("Perm(%d)", i)
+	}
+	return s[1:]
+}
+
+// IsValid reports whether each bit set in i is one of the Perm constants.
+func (i Perm) IsValid() bool {
+	for _, f := range _Perm_flags {
+		i &^= f.flag
+	}
+	return i == 0
+}
+`
+
+// Bit flags with a name for zero.
+const flagszero_in = `type Mode int
+const (
+	None Mode = 0
+	Append Mode = 1 << iota
+	Create
+)
+`
+
+const flagszero_out = `
+const _Mode_name = "NoneAppendCreate"
+
+var _Mode_flags = [...]struct {
+	flag Mode
+	name string
+}{
+	{2, _Mode_name[4:10]},
+	{4, _Mode_name[10:16]},
+}
+
+func (i Mode) String() string {
+	if i == 0 {
+		return _Mode_name[0:4]
+	}
+	var s string
+	for _, f := range _Mode_flags {
+		if i&f.flag != 0 {
+			s += "|" + f.name
+			i &^= f.flag
+		}
+	}
+	if i != 0 {
+		s += "|" + fmt.Sprintf("Mode(%d)", i)
+	}
+	return s[1:]
+}
+`
+
+// Fallbacks for values with no name. A format is applied to the integer
+// value, which may be printed with %v without calling String.
+const invalidformat_out = `
+const _Num_name = "m_2m_1m0m1m2"
+
+var _Num_index = [...]uint8{0, 3, 6, 8, 10, 12}
+
+func (i Num) String() string {
+	n := uint64(i) + 2
+	if n >= uint64(len(_Num_index)-1) {
+		return fmt.Sprintf("unknown num %v", int64(i))
+	}
+	return _Num_name[_Num_index[n]:_Num_index[n+1]]
+}
+`
+
+const invalidplain_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50}
+
+func (i Day) String() string {
+	if i < 0 || i >= Day(len(_Day_index)-1) {
+		return "?"
+	}
+	return _Day_name[_Day_index[i]:_Day_index[i+1]]
+}
+`
+
+const invalidempty_out = `
+const (
+	_Gap_name_0 = "TwoThree"
+	_Gap_name_1 = "FiveSixSevenEightNine"
+	_Gap_name_2 = "Eleven"
+)
+
+var (
+	_Gap_index_0 = [...]uint8{0, 3, 8}
+	_Gap_index_1 = [...]uint8{0, 4, 7, 12, 17, 21}
+	_Gap_index_2 = [...]uint8{0, 6}
+)
+
+func (i Gap) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Gap_name_0[_Gap_index_0[i]:_Gap_index_0[i+1]]
+	case 5 <= i && i <= 9:
+		i -= 5
+		return _Gap_name_1[_Gap_index_1[i]:_Gap_index_1[i+1]]
+	case i == 11:
+		return _Gap_name_2
+	default:
+		return ""
+	}
+}
+`
+
+const invalidpanic_out = `
+const _Prime_name = "p2p3p5p7p11p13p17p19p23p29p37p41p43"
+
+var _Prime_map = map[Prime]string{
+	2:  _Prime_name[0:2],
+	3:  _Prime_name[2:4],
+	5:  _Prime_name[4:6],
+	7:  _Prime_name[6:8],
+	11: _Prime_name[8:11],
+	13: _Prime_name[11:14],
+	17: _Prime_name[14:17],
+	19: _Prime_name[17:20],
+	23: _Prime_name[20:23],
+	29: _Prime_name[23:26],
+	31: _Prime_name[26:29],
+	41: _Prime_name[29:32],
+	43: _Prime_name[32:35],
+}
+
+func (i Prime) String() string {
+	if str, ok := _Prime_map[i]; ok {
+		return str
+	}
+	panic(fmt.Sprintf("invalid Prime %d", i))
+}
+`
+
+const flagsempty_out = `
+const _Perm_name = "ExecWriteReadSticky"
+
+var _Perm_flags = [...]struct {
+	flag Perm
+	name string
+}{
+	{1, _Perm_name[0:4]},
+	{2, _Perm_name[4:9]},
+	{4, _Perm_name[9:13]},
+	{16, _Perm_name[13:19]},
+}
+
+func (i Perm) String() string {
+	if i == 0 {
+		return ""
+	}
+	var s string
+	for _, f := range _Perm_flags {
+		if i&f.flag != 0 {
+			s += "|" + f.name
+			i &^= f.flag
+		}
+	}
+	if s == "" {
+		return s
+	}
+	return s[1:]
+}
+`
+
+const flagspanic_out = `
+const _Perm_name = "ExecWriteReadSticky"
+
+var _Perm_flags = [...]struct {
+	flag Perm
+	name string
+}{
+	{1, _Perm_name[0:4]},
+	{2, _Perm_name[4:9]},
+	{4, _Perm_name[9:13]},
+	{16, _Perm_name[13:19]},
+}
+
+func (i Perm) String() string {
+	if i == 0 {
+		panic(fmt.Sprintf("invalid Perm %d", i))
+	}
+	var s string
+	for _, f := range _Perm_flags {
+		if i&f.flag != 0 {
+			s += "|" + f.name
+			i &^= f.flag
+		}
+	}
+	if i != 0 {
+		panic(fmt.Sprintf("invalid Perm %d", i))
+	}
+	return s[1:]
+}
+`
+
+// GoString uses String if it prints the names of the constants.
+const gostring_out = `
+// GoString implements the fmt.GoStringer interface, so that %#v prints i as Go syntax.
+func (i Gap) GoString() string {
+	if i.IsValid() {
+		return "test." + i.String()
+	}
+	return fmt.Sprintf("test.Gap(%d)", i)
+}
+`
+
+// The Format method, which needs GoString.
+const format_out = `
+// Format implements the fmt.Formatter interface, so that %v and %s print the
+// name of i, %q the name quoted and %#v what GoString returns, while the
+// other verbs, such as %d and %x, print the number, each with the width,
+// precision and flags given.
+func (i Gap) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, fmt.FormatString(f, 's'), i.GoString())
+	case verb == 'v' || verb == 's' || verb == 'q':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), i.String())
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), int64(i))
+	}
+}
+`
+
+// Otherwise GoString has its own map.
+const gostringprefix_out = `
+var _Type_gonames = map[Type]string{
+	0: "test.TypeInt",
+	1: "test.TypeString",
+	2: "test.TypeFloat",
+	3: "test.TypeRune",
+	4: "test.TypeByte",
+	5: "test.TypeStruct",
+	6: "test.TypeSlice",
+}
+
+// GoString implements the fmt.GoStringer interface, so that %#v prints i as Go syntax.
+func (i Type) GoString() string {
+	if s, ok := _Type_gonames[i]; ok {
+		return s
+	}
+	return fmt.Sprintf("test.Type(%d)", i)
+}
+`
+
+// Sparse signed values, declared out of order, whose maps are sorted by value.
+const sparse_in = `type Sparse int16
+const (
+	S100 Sparse = 100
+	SMinus5 Sparse = -5
+	S30 Sparse = 30
+	SMinus70 Sparse = -70
+	S2 Sparse = 2
+	S1000 Sparse = 1000
+	S40 Sparse = 40
+	S8 Sparse = 8
+	SMinus300 Sparse = -300
+	S12 Sparse = 12
+	S20000 Sparse = 20000
+	S0 Sparse = 0
+)
+`
+
+const sparse_out = `
+const _Sparse_name = "Minus300Minus70Minus5028123040100100020000"
+
+var _Sparse_map = map[Sparse]string{
+	-300:  _Sparse_name[0:8],
+	-70:   _Sparse_name[8:15],
+	-5:    _Sparse_name[15:21],
+	0:     _Sparse_name[21:22],
+	2:     _Sparse_name[22:23],
+	8:     _Sparse_name[23:24],
+	12:    _Sparse_name[24:26],
+	30:    _Sparse_name[26:28],
+	40:    _Sparse_name[28:30],
+	100:   _Sparse_name[30:33],
+	1000:  _Sparse_name[33:37],
+	20000: _Sparse_name[37:42],
+}
+
+func (i Sparse) String() string {
+	if str, ok := _Sparse_map[i]; ok {
+		return str
+	}
+	return fmt.Sprintf("Sparse(%d)", i)
+}
+
+var _Sparse_value = map[string]Sparse{
+	_Sparse_name[0:8]:   -300,
+	_Sparse_name[8:15]:  -70,
+	_Sparse_name[15:21]: -5,
+	_Sparse_name[21:22]: 0,
+	_Sparse_name[22:23]: 2,
+	_Sparse_name[23:24]: 8,
+	_Sparse_name[24:26]: 12,
+	_Sparse_name[26:28]: 30,
+	_Sparse_name[28:30]: 40,
+	_Sparse_name[30:33]: 100,
+	_Sparse_name[33:37]: 1000,
+	_Sparse_name[37:42]: 20000,
+}
+
+// ParseSparse returns the Sparse whose String method returns s.
+func ParseSparse(s string) (Sparse, error) {
+	if i, ok := _Sparse_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Sparse %q", s)
+}
+
+var _Sparse_gonames = map[Sparse]string{
+	-300:  "test.SMinus300",
+	-70:   "test.SMinus70",
+	-5:    "test.SMinus5",
+	0:     "test.S0",
+	2:     "test.S2",
+	8:     "test.S8",
+	12:    "test.S12",
+	30:    "test.S30",
+	40:    "test.S40",
+	100:   "test.S100",
+	1000:  "test.S1000",
+	20000: "test.S20000",
+}
+
+// GoString implements the fmt.GoStringer interface, so that %#v prints i as Go syntax.
+func (i Sparse) GoString() string {
+	if s, ok := _Sparse_gonames[i]; ok {
+		return s
+	}
+	return fmt.Sprintf("test.Sparse(%d)", i)
+}
+`
+
+// With a lower threshold, a few runs are enough for a map.
+const threshold_out = `
+const _Gap_name = "TwoThreeFiveSixSevenEightNineEleven"
+
+var _Gap_map = map[Gap]string{
+	2:  _Gap_name[0:3],
+	3:  _Gap_name[3:8],
+	5:  _Gap_name[8:12],
+	6:  _Gap_name[12:15],
+	7:  _Gap_name[15:20],
+	8:  _Gap_name[20:25],
+	9:  _Gap_name[25:29],
+	11: _Gap_name[29:35],
+}
+
+func (i Gap) String() string {
+	if str, ok := _Gap_map[i]; ok {
+		return str
+	}
+	return fmt.Sprintf("Gap(%d)", i)
+}
+`
+
+// A switch may be chosen for many runs.
+const lookupswitch_out = `
+const (
+	_Prime_name_0  = "p2p3"
+	_Prime_name_1  = "p5"
+	_Prime_name_2  = "p7"
+	_Prime_name_3  = "p11"
+	_Prime_name_4  = "p13"
+	_Prime_name_5  = "p17"
+	_Prime_name_6  = "p19"
+	_Prime_name_7  = "p23"
+	_Prime_name_8  = "p29"
+	_Prime_name_9  = "p37"
+	_Prime_name_10 = "p41"
+	_Prime_name_11 = "p43"
+)
+
+var (
+	_Prime_index_0  = [...]uint8{0, 2, 4}
+	_Prime_index_1  = [...]uint8{0, 2}
+	_Prime_index_2  = [...]uint8{0, 2}
+	_Prime_index_3  = [...]uint8{0, 3}
+	_Prime_index_4  = [...]uint8{0, 3}
+	_Prime_index_5  = [...]uint8{0, 3}
+	_Prime_index_6  = [...]uint8{0, 3}
+	_Prime_index_7  = [...]uint8{0, 3}
+	_Prime_index_8  = [...]uint8{0, 3}
+	_Prime_index_9  = [...]uint8{0, 3}
+	_Prime_index_10 = [...]uint8{0, 3}
+	_Prime_index_11 = [...]uint8{0, 3}
+)
+
+func (i Prime) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Prime_name_0[_Prime_index_0[i]:_Prime_index_0[i+1]]
+	case i == 5:
+		return _Prime_name_1
+	case i == 7:
+		return _Prime_name_2
+	case i == 11:
+		return _Prime_name_3
+	case i == 13:
+		return _Prime_name_4
+	case i == 17:
+		return _Prime_name_5
+	case i == 19:
+		return _Prime_name_6
+	case i == 23:
+		return _Prime_name_7
+	case i == 29:
+		return _Prime_name_8
+	case i == 31:
+		return _Prime_name_9
+	case i == 41:
+		return _Prime_name_10
+	case i == 43:
+		return _Prime_name_11
+	default:
+		return fmt.Sprintf("Prime(%d)", i)
+	}
+}
+`
+
+// A map may be chosen for a single run.
+const lookupmap_out = `
+const _Day_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySunday"
+
+var _Day_map = map[Day]string{
+	0: _Day_name[0:6],
+	1: _Day_name[6:13],
+	2: _Day_name[13:22],
+	3: _Day_name[22:30],
+	4: _Day_name[30:36],
+	5: _Day_name[36:44],
+	6: _Day_name[44:50],
+}
+
+func (i Day) String() string {
+	if str, ok := _Day_map[i]; ok {
+		return str
+	}
+	return fmt.Sprintf("Day(%d)", i)
+}
+`
+
+// With -nofmt, the fallbacks and errors use strconv and errors.
+const nofmt_out = `
+const (
+	_Unum_name_0 = "m0m1m2"
+	_Unum_name_1 = "m_2m_1"
+)
+
+var (
+	_Unum_index_0 = [...]uint8{0, 2, 4, 6}
+	_Unum_index_1 = [...]uint8{0, 3, 6}
+)
+
+func (i Unum) String() string {
+	switch {
+	case 0 <= i && i <= 2:
+		return _Unum_name_0[_Unum_index_0[i]:_Unum_index_0[i+1]]
+	case 253 <= i && i <= 254:
+		i -= 253
+		return _Unum_name_1[_Unum_index_1[i]:_Unum_index_1[i+1]]
+	default:
+		return "Unum(" + strconv.FormatUint(uint64(i), 10) + ")"
+	}
+}
+
+// IsValid reports whether i is the value of one of the Unum constants.
+func (i Unum) IsValid() bool {
+	return 0 <= i && i <= 2 ||
+		253 <= i && i <= 254
+}
+
+var _Unum_value = map[string]Unum{
+	_Unum_name_0[0:2]: 0,
+	_Unum_name_0[2:4]: 1,
+	_Unum_name_0[4:6]: 2,
+	_Unum_name_1[0:3]: 253,
+	_Unum_name_1[3:6]: 254,
+}
+
+// ParseUnum returns the Unum whose String method returns s.
+func ParseUnum(s string) (Unum, error) {
+	if i, ok := _Unum_value[s]; ok {
+		return i, nil
+	}
+	return 0, errors.New("invalid Unum " + strconv.Quote(s))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Unum) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Unum) UnmarshalText(text []byte) error {
+	v, ok := _Unum_value[string(text)]
+	if !ok {
+		return errors.New("invalid Unum " + strconv.Quote(string(text)))
+	}
+	*i = v
+	return nil
+}
+
+// GoString implements the fmt.GoStringer interface, so that %#v prints i as Go syntax.
+func (i Unum) GoString() string {
+	if i.IsValid() {
+		return "test." + i.String()
+	}
+	return "test.Unum(" + strconv.FormatUint(uint64(i), 10) + ")"
+}
+`
+
+const nofmtpanic_out = `
+const _Num_name = "m_2m_1m0m1m2"
+
+var _Num_index = [...]uint8{0, 3, 6, 8, 10, 12}
+
+func (i Num) String() string {
+	n := uint64(i) + 2
+	if n >= uint64(len(_Num_index)-1) {
+		panic("invalid Num " + strconv.FormatInt(int64(i), 10))
+	}
+	return _Num_name[_Num_index[n]:_Num_index[n+1]]
+}
+`
+
+const nofmtfloat_out = `
+const _Rate_name = "BelowTenthPhoneCDDVDHuge"
+
+var _Rate_map = map[Rate]string{
+	-2.5:   _Rate_name[0:5],
+	0.1:    _Rate_name[5:10],
+	8000:   _Rate_name[10:15],
+	44100:  _Rate_name[15:17],
+	48000:  _Rate_name[17:20],
+	1e+100: _Rate_name[20:24],
+}
+
+func (i Rate) String() string {
+	if str, ok := _Rate_map[i]; ok {
+		return str
+	}
+	return "Rate(" + strconv.FormatFloat(float64(i), 'g', -1, 64) + ")"
+}
+`
+
+// The same values, found by binary search.
+const binarysearch_out = `
+const _Sparse_name = "SMinus300SMinus70SMinus5S0S2S8S12S30S40S100S1000S20000"
+
+var _Sparse_index = [...]uint8{0, 9, 17, 24, 26, 28, 30, 33, 36, 39, 43, 48, 54}
+
+var _Sparse_keys = [...]Sparse{-300, -70, -5, 0, 2, 8, 12, 30, 40, 100, 1000, 20000}
+
+// _Sparse_lookup returns the index of i in _Sparse_keys, or -1.
+func _Sparse_lookup(i Sparse) int {
+	// The loop runs a fixed number of times, and its body can be compiled
+	// to a conditional move rather than a branch.
+	j, n := 0, len(_Sparse_keys)
+	for n > 1 {
+		half := n / 2
+		if _Sparse_keys[j+half] <= i {
+			j += half
+		}
+		n -= half
+	}
+	if _Sparse_keys[j] != i {
+		return -1
+	}
+	return j
+}
+
+func (i Sparse) String() string {
+	if j := _Sparse_lookup(i); j >= 0 {
+		return _Sparse_name[_Sparse_index[j]:_Sparse_index[j+1]]
+	}
+	return fmt.Sprintf("Sparse(%d)", i)
+}
+
+// IsValid reports whether i is the value of one of the Sparse constants.
+func (i Sparse) IsValid() bool {
+	return _Sparse_lookup(i) >= 0
+}
+`
+
+// Floating-point constants, sorted by value, always use a map.
+const float_in = `type Rate float64
+const (
+	Phone   Rate = 8000
+	CD      Rate = 44100
+	Tenth   Rate = 0.1
+	Below   Rate = -2.5
+	Huge    Rate = 1e100
+	Compact      = CD
+	DVD     Rate = 48e3
+)
+`
+
+const float_out = `
+const _Rate_name = "BelowTenthPhoneCDDVDHuge"
+
+var _Rate_map = map[Rate]string{
+	-2.5:   _Rate_name[0:5],
+	0.1:    _Rate_name[5:10],
+	8000:   _Rate_name[10:15],
+	44100:  _Rate_name[15:17],
+	48000:  _Rate_name[17:20],
+	1e+100: _Rate_name[20:24],
+}
+
+func (i Rate) String() string {
+	if str, ok := _Rate_map[i]; ok {
+		return str
+	}
+	return fmt.Sprintf("Rate(%g)", i)
+}
+
+var _Rate_value = map[string]Rate{
+	_Rate_name[0:5]:   -2.5,
+	_Rate_name[5:10]:  0.1,
+	_Rate_name[10:15]: 8000,
+	_Rate_name[15:17]: 44100,
+	_Rate_name[17:20]: 48000,
+	_Rate_name[20:24]: 1e+100,
+}
+
+// ParseRate returns the Rate whose String method returns s.
+func ParseRate(s string) (Rate, error) {
+	if i, ok := _Rate_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Rate %q", s)
+}
+`
+
+// A type whose underlying type is string. String returns the value.
+const string_in = `type State string
+const (
+	Active   State = "active"
+	Inactive State = "inactive"
+	Pending  State = "pending \"soon\""
+	Idle     State = "inactive"
+)
+`
+
+const string_out = `
+func (i State) String() string {
+	return string(i)
+}
+
+// IsValid reports whether i is the value of one of the State constants.
+func (i State) IsValid() bool {
+	switch i {
+	case "active", "inactive", "pending \"soon\"":
+		return true
+	}
+	return false
+}
+
+// ParseState returns s as a State if it is the value of one of the constants.
+func ParseState(s string) (State, error) {
+	if i := State(s); i.IsValid() {
+		return i, nil
+	}
+	return "", fmt.Errorf("invalid State %q", s)
+}
+
+var _State_values = []State{Active, Inactive, Pending}
+
+// StateValues returns the values of the State constants, in the order they
+// are declared. Constants with the same value as an earlier one are omitted.
+func StateValues() []State {
+	return append([]State(nil), _State_values...)
+}
+
+var _State_strings = []string{"active", "inactive", "pending \"soon\""}
+
+// StateStrings returns the names of the State constants, in the order they
+// are declared and so aligned with StateValues.
+func StateStrings() []string {
+	return append([]string(nil), _State_strings...)
+}
+`
+
+const gostringstring_stringout = `
+func (i State) String() string {
+	return string(i)
+}
+`
+
+const gostringstring_out = `
+var _State_gonames = map[State]string{
+	"active":           "test.Active",
+	"inactive":         "test.Inactive",
+	"pending \"soon\"": "test.Pending",
+}
+
+// GoString implements the fmt.GoStringer interface, so that %#v prints i as Go syntax.
+func (i State) GoString() string {
+	if s, ok := _State_gonames[i]; ok {
+		return s
+	}
+	return fmt.Sprintf("test.State(%q)", string(i))
+}
+`
+
+// Names sharing long prefixes store each prefix once, apart from the rest.
+// EventLogin shares only "Event", which would save nothing.
+const prefixes_in = `type Event int
+const (
+	EventUserCreated Event = iota
+	EventUserDeleted
+	EventUserRenamed
+	EventOrderPlaced
+	EventOrderShipped
+	EventOrderCancelled
+	EventLogin
+)
+`
+
+const prefixes_out = `
+const _Event_name = "CreatedDeletedRenamedPlacedShippedCancelledEventLogin"
+
+var _Event_index = [...]uint8{0, 7, 14, 21, 27, 34, 43, 53}
+
+var _Event_prefixes = [...]string{"", "EventUser", "EventOrder"}
+
+var _Event_prefix = [...]uint8{1, 1, 1, 2, 2, 2, 0}
+
+func (i Event) String() string {
+	if i < 0 || i >= Event(len(_Event_index)-1) {
+		return fmt.Sprintf("Event(%d)", i)
+	}
+	return _Event_prefixes[_Event_prefix[i]] + _Event_name[_Event_index[i]:_Event_index[i+1]]
+}
+`
+
+// Each run has its own positions in the table of prefixes, and the names
+// found by Parse add the prefixes back.
+const prefixesruns_in = `type Alert int
+const (
+	AlertDiskFull Alert = iota + 1
+	AlertDiskFailing
+	AlertDiskMissing
+	AlertNetworkDown Alert = 10
+	AlertNetworkSlow Alert = 11
+	AlertPowerLost Alert = 20
+)
+`
+
+const prefixesruns_out = `
+const (
+	_Alert_name_0 = "FullFailingMissing"
+	_Alert_name_1 = "DownSlow"
+	_Alert_name_2 = "AlertPowerLost"
+)
+
+var (
+	_Alert_index_0 = [...]uint8{0, 4, 11, 18}
+	_Alert_index_1 = [...]uint8{0, 4, 8}
+	_Alert_index_2 = [...]uint8{0, 14}
+)
+
+var _Alert_prefixes = [...]string{"", "AlertDisk", "AlertNetwork"}
+
+var (
+	_Alert_prefix_0 = [...]uint8{1, 1, 1}
+	_Alert_prefix_1 = [...]uint8{2, 2}
+)
+
+func (i Alert) String() string {
+	switch {
+	case 1 <= i && i <= 3:
+		i -= 1
+		return _Alert_prefixes[_Alert_prefix_0[i]] + _Alert_name_0[_Alert_index_0[i]:_Alert_index_0[i+1]]
+	case 10 <= i && i <= 11:
+		i -= 10
+		return _Alert_prefixes[_Alert_prefix_1[i]] + _Alert_name_1[_Alert_index_1[i]:_Alert_index_1[i+1]]
+	case i == 20:
+		return _Alert_name_2
+	default:
+		return fmt.Sprintf("Alert(%d)", i)
+	}
+}
+
+var _Alert_value = map[string]Alert{
+	_Alert_prefixes[1] + _Alert_name_0[0:4]:   1,
+	_Alert_prefixes[1] + _Alert_name_0[4:11]:  2,
+	_Alert_prefixes[1] + _Alert_name_0[11:18]: 3,
+	_Alert_prefixes[2] + _Alert_name_1[0:4]:   10,
+	_Alert_prefixes[2] + _Alert_name_1[4:8]:   11,
+	_Alert_name_2[0:14]:                       20,
+}
+
+// ParseAlert returns the Alert whose String method returns s.
+func ParseAlert(s string) (Alert, error) {
+	if i, ok := _Alert_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Alert %q", s)
+}
+`
+
+func TestGolden(t *testing.T) {
+	for _, test := range golden {
+		g := test.generate(t)
+		if test.NoFmt && g.imports["fmt"] {
+			t.Errorf("%s: imports fmt", test.name)
+		}
+		got := string(g.format())
+		if got != test.output {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", test.name, got, test.output)
+		}
+	}
+}
+
+// generate runs the generator for the test case.
+func (test Golden) generate(t *testing.T) *generator {
+	g := newGenerator(&test.Options)
+	input := "package test\n" + test.input
+	file := test.name + ".go"
+	g.parsePackage(".", []string{file}, input)
+	// Extract the name and type of the constant from the first line.
+	tokens := strings.SplitN(test.input, " ", 3)
+	if len(tokens) != 3 {
+		t.Fatalf("%s: need type declaration on first line", test.name)
+	}
+	g.generate(tokens[1])
+	return g
+}
+
+// TestMultipleTypes checks that one generator, parsing the package once,
+// emits the methods for several types into a single file.
+func TestMultipleTypes(t *testing.T) {
+	var g generator
+	input := "package test\n" + day_in + prime_in
+	g.parsePackage(".", []string{"multiple.go"}, input)
+	g.generate("Day")
+	g.generate("Prime")
+	got := string(g.format())
+	expect := day_out + prime_out
+	if got != expect {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, expect)
+	}
+}
+
+// TestSharedNames checks that the types generated together store the names
+// that String looks up by runs in one string and one array of offsets,
+// declared at the end of the file.
+func TestSharedNames(t *testing.T) {
+	g := generator{isValid: true, parse: true}
+	input := "package test\n" + day_in + gap_in + num_in
+	g.parsePackage(".", []string{"shared.go"}, input)
+	g.shared = newSharedNames("Day")
+	for _, typeName := range []string{"Day", "Gap", "Num"} {
+		g.generate(typeName)
+	}
+	g.shared.declare(&g)
+	got := string(g.format())
+	if got != shared_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, shared_out)
+	}
+}
+
+// Types whose names are the same, or overlap, store them once.
+func TestSharedDuplicates(t *testing.T) {
+	g := generator{lineComment: true}
+	g.parsePackage(".", []string{"shared.go"}, "package test\n"+shareddup_in)
+	g.shared = newSharedNames("Color")
+	for _, typeName := range []string{"Color", "Filter", "Channel"} {
+		g.generate(typeName)
+	}
+	g.shared.declare(&g)
+	got := string(g.format())
+	if got != shareddup_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, shareddup_out)
+	}
+}
+
+const shareddup_in = `type Color int
+
+const (
+	ColorRed   Color = iota // red
+	ColorGreen              // green
+	ColorBlue               // blue
+)
+
+type Filter int
+
+const (
+	FilterRed   Filter = iota // red
+	FilterGreen               // green
+	FilterBlue                // blue
+	FilterAlpha               // alpha
+)
+
+type Channel int
+
+const (
+	ChannelGreen Channel = iota + 1 // green
+	ChannelBlue                     // blue
+)
+`
+
+const shareddup_out = `
+func (i Color) String() string {
+	if i < 0 || i >= Color(3) {
+		return fmt.Sprintf("Color(%d)", i)
+	}
+	return _Color_shared_name[_Color_shared_index[i]:_Color_shared_index[i+1]]
+}
+
+func (i Filter) String() string {
+	if i < 0 || i >= Filter(4) {
+		return fmt.Sprintf("Filter(%d)", i)
+	}
+	return _Color_shared_name[_Color_shared_index[i]:_Color_shared_index[i+1]]
+}
+
+func (i Channel) String() string {
+	i -= 1
+	if i < 0 || i >= Channel(2) {
+		return fmt.Sprintf("Channel(%d)", i+1)
+	}
+	return _Color_shared_name[_Color_shared_index[int(i)+1]:_Color_shared_index[int(i)+2]]
+}
+
+const _Color_shared_name = "redgreenbluealpha"
+
+var _Color_shared_index = [...]uint8{0, 3, 8, 12, 17}
+`
+
+const shared_out = `
+func (i Day) String() string {
+	if i < 0 || i >= Day(7) {
+		return fmt.Sprintf("Day(%d)", i)
+	}
+	return _Day_shared_name[_Day_shared_index[i]:_Day_shared_index[i+1]]
+}
+
+// IsValid reports whether i is the value of one of the Day constants.
+func (i Day) IsValid() bool {
+	return 0 <= i && i < Day(7)
+}
+
+var _Day_value = map[string]Day{
+	_Day_shared_name[0:6]:   0,
+	_Day_shared_name[6:13]:  1,
+	_Day_shared_name[13:22]: 2,
+	_Day_shared_name[22:30]: 3,
+	_Day_shared_name[30:36]: 4,
+	_Day_shared_name[36:44]: 5,
+	_Day_shared_name[44:50]: 6,
+}
+
+// ParseDay returns the Day whose String method returns s.
+func ParseDay(s string) (Day, error) {
+	if i, ok := _Day_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Day %q", s)
+}
+
+func (i Gap) String() string {
+	switch {
+	case 2 <= i && i <= 3:
+		i -= 2
+		return _Day_shared_name[_Day_shared_index[int(i)+7]:_Day_shared_index[int(i)+8]]
+	case 5 <= i && i <= 9:
+		i -= 5
+		return _Day_shared_name[_Day_shared_index[int(i)+9]:_Day_shared_index[int(i)+10]]
+	case i == 11:
+		return _Day_shared_name[79:85]
+	default:
+		return fmt.Sprintf("Gap(%d)", i)
+	}
+}
+
+// IsValid reports whether i is the value of one of the Gap constants.
+func (i Gap) IsValid() bool {
+	return 2 <= i && i <= 3 ||
+		5 <= i && i <= 9 ||
+		i == 11
+}
+
+var _Gap_value = map[string]Gap{
+	_Day_shared_name[50:53]: 2,
+	_Day_shared_name[53:58]: 3,
+	_Day_shared_name[58:62]: 5,
+	_Day_shared_name[62:65]: 6,
+	_Day_shared_name[65:70]: 7,
+	_Day_shared_name[70:75]: 8,
+	_Day_shared_name[75:79]: 9,
+	_Day_shared_name[79:85]: 11,
+}
+
+// ParseGap returns the Gap whose String method returns s.
+func ParseGap(s string) (Gap, error) {
+	if i, ok := _Gap_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Gap %q", s)
+}
+
+func (i Num) String() string {
+	n := uint64(i) + 2
+	if n >= uint64(5) {
+		return fmt.Sprintf("Num(%d)", i)
+	}
+	return _Day_shared_name[_Day_shared_index[int(n)+15]:_Day_shared_index[int(n)+16]]
+}
+
+// IsValid reports whether i is the value of one of the Num constants.
+func (i Num) IsValid() bool {
+	return uint64(i)+2 < uint64(5)
+}
+
+var _Num_value = map[string]Num{
+	_Day_shared_name[85:88]: -2,
+	_Day_shared_name[88:91]: -1,
+	_Day_shared_name[91:93]: 0,
+	_Day_shared_name[93:95]: 1,
+	_Day_shared_name[95:97]: 2,
+}
+
+// ParseNum returns the Num whose String method returns s.
+func ParseNum(s string) (Num, error) {
+	if i, ok := _Num_value[s]; ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("invalid Num %q", s)
+}
+
+const _Day_shared_name = "MondayTuesdayWednesdayThursdayFridaySaturdaySundayTwoThreeFiveSixSevenEightNineElevenm_2m_1m0m1m2"
+
+var _Day_shared_index = [...]uint8{0, 6, 13, 22, 30, 36, 44, 50, 53, 58, 62, 65, 70, 75, 79, 85, 88, 91, 93, 95, 97}
+`
+
+// writeFiles writes the files, named by their paths relative to it, into a
+// new temporary directory, and returns the directory, which the caller must
+// remove.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "stringer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, text := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0666); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// The constants may be declared in several files, through an alias, or with
+// their type given only by a conversion.
+func TestSeveralFiles(t *testing.T) {
+	files := map[string]string{
+		"day.go": `package test
+type Day int
+type Weekday = Day
+const (
+	Monday Weekday = iota
+	Tuesday
+	Wednesday
+)
+`,
+		"weekend.go": `package test
+const (
+	Thursday, Friday Day = 3, 4
+	Saturday             = Day(5)
+	Sunday               = Saturday + 1
+	Week                 = 7
+)
+`,
+	}
+	dir := writeFiles(t, files)
+	defer os.RemoveAll(dir)
+	var names []string
+	for name := range files {
+		names = append(names, filepath.Join(dir, name))
+	}
+	for _, typeName := range []string{"Day", "Weekday"} {
+		var g generator
+		g.parsePackageFiles(names)
+		g.generate(typeName)
+		got := string(g.format())
+		expect := strings.Replace(day_out, "Day", typeName, -1)
+		if got != expect {
+			t.Errorf("%s: got\n====\n%s====\nexpected\n====%s", typeName, got, expect)
+		}
+	}
+}
+
+// Build tags select the files of a package directory.
+func TestTags(t *testing.T) {
+	files := map[string]string{
+		"day.go": "package test\n" + day_in,
+		"extra.go": `// +build extra
+
+package test
+
+const Holiday Day = 7
+`,
+	}
+	dir := writeFiles(t, files)
+	defer os.RemoveAll(dir)
+	for _, tags := range [][]string{nil, {"extra"}} {
+		g := generator{tags: tags}
+		g.parsePackageDir(dir)
+		g.generate("Day")
+		got := string(g.format())
+		if strings.Contains(got, "Holiday") != (tags != nil) {
+			t.Errorf("tags %q: got\n====\n%s====", tags, got)
+		}
+	}
+}
+
+// The target platform selects the files of a package directory, by their
+// suffixes and build constraints.
+func TestPlatform(t *testing.T) {
+	files := map[string]string{
+		"day.go":         "package test\n" + day_in,
+		"extra_linux.go": "package test\n\nconst Holiday Day = 7\n",
+		"extra_arm64.go": "// +build windows\n\npackage test\n\nconst Holiday Day = 7\n",
+	}
+	dir := writeFiles(t, files)
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		goos, goarch string
+		holiday      bool
+	}{
+		{"linux", "amd64", true},
+		{"darwin", "arm64", false},
+		{"windows", "arm64", true},
+		{"windows", "", false},
+	} {
+		g := generator{goos: test.goos, goarch: test.goarch}
+		g.parsePackageDir(dir)
+		g.generate("Day")
+		got := string(g.format())
+		if strings.Contains(got, "Holiday") != test.holiday {
+			t.Errorf("%s/%s: got\n====\n%s====", test.goos, test.goarch, got)
+		}
+	}
+}
+
+// The schema lists the names of integer types and the values of string ones.
+func TestSchema(t *testing.T) {
+	g := generator{trimPrefix: "Type"}
+	g.parsePackage(".", []string{"schema.go"}, "package test\n"+prefix_in+string_in)
+	g.schema = newSchema()
+	g.generate("Type")
+	g.generate("State")
+	got, err := json.MarshalIndent(g.schema, "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != schema_out {
+		t.Errorf("got\n====\n%s\n====\nexpected\n====\n%s", got, schema_out)
+	}
+}
+
+const schema_out = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"$defs": {
+		"State": {
+			"type": "string",
+			"enum": [
+				"active",
+				"inactive",
+				"pending \"soon\""
+			]
+		},
+		"Type": {
+			"type": "string",
+			"enum": [
+				"Int",
+				"String",
+				"Float",
+				"Rune",
+				"Byte",
+				"Struct",
+				"Slice"
+			]
+		}
+	}
+}`
+
+// Switch statements on Day must handle every day or have a default.
+const exhaustive_in = day_in + `
+const Dimanche = Sunday
+
+func weekend(d Day) bool {
+	switch d {
+	case Saturday, Dimanche:
+		return true
+	case Monday, Tuesday, Wednesday, Thursday, Friday:
+	}
+	switch d {
+	case Saturday:
+		return true
+	default:
+	}
+	switch d {
+	case Monday, Tuesday, Day(2), 3:
+		return false
+	}
+	switch int(d) {
+	case 0:
+	}
+	return false
+}
+`
+
+func TestExhaustive(t *testing.T) {
+	var g generator
+	g.parsePackage(".", []string{"exhaustive.go"}, "package test\n"+exhaustive_in)
+	got, err := g.pkg.IncompleteSwitches("Day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"exhaustive.go:26:2: switch on Day has no default and misses Friday, Saturday, Sunday",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("got %q; expected %q", got, expect)
+	}
+}
+
+// A template replaces the built-in generator.
+func TestTemplate(t *testing.T) {
+	tmpl := `{{import "log"}}
+func (i {{.TypeName}}) String() string {
+	switch i {
+{{range .Values}}	case {{.OriginalName}}:
+		return {{quote .Name}}
+{{end}}	}
+	log.Printf("bad {{.TypeName}} %d", i)
+	return ""
+}
+
+// {{len .Runs}} runs in package {{.Package}}
+`
+	dir := writeFiles(t, map[string]string{"string.tmpl": tmpl})
+	defer os.RemoveAll(dir)
+	parsed, err := ParseTemplate(filepath.Join(dir, "string.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := generator{trimPrefix: "Gap", template: parsed}
+	g.parsePackage(".", []string{"gap.go"}, "package test\n"+prefixgap_in)
+	g.generate("Gap")
+	if !g.imports["log"] {
+		t.Errorf("log not imported")
+	}
+	got := string(g.format())
+	if got != template_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, template_out)
+	}
+}
+
+const template_out = `
+func (i Gap) String() string {
+	switch i {
+	case GapTwo:
+		return "Two"
+	case GapThree:
+		return "Three"
+	case GapFive:
+		return "Five"
+	case GapSix:
+		return "Six"
+	case GapEleven:
+		return "Eleven"
+	}
+	log.Printf("bad Gap %d", i)
+	return ""
+}
+
+// 3 runs in package test
+`
+
+// The output must not depend on map iteration order or the like.
+func TestDeterministic(t *testing.T) {
+	for _, test := range golden {
+		first := string(test.generate(t).format())
+		for i := 1; i < 10; i++ {
+			if got := string(test.generate(t).format()); got != first {
+				t.Fatalf("%s: run %d differs:\n====\n%s====\nfirst\n====\n%s", test.name, i, got, first)
+			}
+		}
+	}
+}
+
+// The test written with -gentest parses each name and prints the values just
+// outside the range and in a gap.
+func TestGenTest(t *testing.T) {
+	g := generator{parse: true}
+	g.parsePackage(".", []string{"gap.go"}, "package test\n"+gap_in)
+	g.test = g.newTest()
+	g.generate("Gap")
+	if !g.test.imports["testing"] {
+		t.Errorf("testing not imported")
+	}
+	got := string(g.test.format())
+	if got != gentest_out {
+		t.Errorf("got\n====\n%s====\nexpected\n====%s", got, gentest_out)
+	}
+}
+
+// The generated file imports the recorded packages its code uses, with those
+// of the standard library apart.
+func TestImports(t *testing.T) {
+	g := generator{}
+	g.parsePackage(".", []string{"day.go"}, "package test\n"+day_in)
+	g.addImport("sort")
+	g.addImport("strings")
+	g.addImport("example.com/day")
+	g.Printf("\nvar _ = strings.ToUpper\n\nvar _ = day.Monday\n\nvar _ = fmt.Sprint\n")
+	g.printHeader([]string{"-type", "Day"})
+	got := string(g.format())
+	want := "package test\n\nimport (\n\t\"strings\"\n\n\t\"example.com/day\"\n)\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("got\n====\n%s====\nexpected imports\n====\n%s", got, want)
+	}
+}
+
+const gentest_out = `
+func TestGapString(t *testing.T) {
+	for _, v := range []Gap{Two, Three, Five, Six, Seven, Eight, Nine, Eleven} {
+		s := v.String()
+		p, err := ParseGap(s)
+		if err != nil {
+			t.Errorf("ParseGap(%q): %v", s, err)
+		} else if p.String() != s {
+			t.Errorf("ParseGap(%q).String() = %q", s, p.String())
+		}
+	}
+	for _, v := range []Gap{1, 12, 4} {
+		if got, want := v.String(), fmt.Sprintf("Gap(%d)", v); got != want {
+			t.Errorf("Gap(%d).String() = %q, want %q", int64(v), got, want)
+		}
+	}
+}
+`
+
+const char_in = `type Token rune
+const (
+	EOF Token = -1
+	Mul Token = '*'
+	Add Token = '+'
+	Sub Token = '-'
+)
+`
+
+const char_out = `
+const (
+	_Token_name_0 = "EOF"
+	_Token_name_1 = "MulAdd"
+	_Token_name_2 = "Sub"
+)
+
+var (
+	_Token_index_0 = [...]uint8{0, 3}
+	_Token_index_1 = [...]uint8{0, 3, 6}
+	_Token_index_2 = [...]uint8{0, 3}
+)
+
+func (i Token) String() string {
+	switch {
+	case i == -1:
+		return _Token_name_0
+	case 42 <= i && i <= 43:
+		i -= 42
+		return _Token_name_1[_Token_index_1[i]:_Token_index_1[i+1]]
+	case i == 45:
+		return _Token_name_2
+	default:
+		return strconv.QuoteRune(rune(i))
+	}
+}
+`
+
+// Constants that are not characters keep their names.
+const charall_out = `
+const (
+	_Token_name_0 = "EOF"
+	_Token_name_1 = "'*''+'"
+	_Token_name_2 = "'-'"
+)
+
+var (
+	_Token_index_0 = [...]uint8{0, 3}
+	_Token_index_1 = [...]uint8{0, 3, 6}
+	_Token_index_2 = [...]uint8{0, 3}
+)
+
+func (i Token) String() string {
+	switch {
+	case i == -1:
+		return _Token_name_0
+	case 42 <= i && i <= 43:
+		i -= 42
+		return _Token_name_1[_Token_index_1[i]:_Token_index_1[i+1]]
+	case i == 45:
+		return _Token_name_2
+	default:
+		return strconv.QuoteRune(rune(i))
+	}
+}
+`
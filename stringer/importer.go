@@ -0,0 +1,112 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the importer through which the type checker finds the
+// packages imported by the package, so that its constants may be declared in
+// terms of theirs, as in A Kind = other.Base + iota.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// exportImporter imports packages from the export data that the go command
+// builds for them. Unlike importer.Default, which finds only the packages
+// compiled into GOROOT, it finds those of modules and of GOPATH too. The go
+// command is run once, on the first import, for all the imports of the
+// package and their dependencies.
+type exportImporter struct {
+	dir     string   // The directory in which to run the go command.
+	imports []string // The import paths of the package.
+	args    []string // Flags of go list selecting the files, such as -tags.
+	env     []string // Environment of the go command.
+	fset    *token.FileSet
+	gc      types.Importer // The importer of the export data, once listed.
+}
+
+// newImporter returns the importer for the package in the directory whose
+// files are given, in the build context of the generator.
+func (g *generator) newImporter(dir string, fs *token.FileSet, astFiles []*ast.File) *exportImporter {
+	ctxt := g.buildContext()
+	imp := &exportImporter{
+		dir:  dir,
+		env:  append(os.Environ(), "GOOS="+ctxt.GOOS, "GOARCH="+ctxt.GOARCH),
+		fset: fs,
+	}
+	if len(ctxt.BuildTags) > 0 {
+		imp.args = []string{"-tags", strings.Join(ctxt.BuildTags, ",")}
+	}
+	seen := make(map[string]bool)
+	for _, file := range astFiles {
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			// C and unsafe are not packages the go command can build.
+			if err != nil || path == "C" || path == "unsafe" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			imp.imports = append(imp.imports, path)
+		}
+	}
+	return imp
+}
+
+// Import implements types.Importer. If the go command cannot list the
+// packages, it falls back to importer.Default.
+func (imp *exportImporter) Import(path string) (*types.Package, error) {
+	if imp.gc == nil {
+		exports, err := imp.list()
+		if err != nil {
+			imp.gc = importer.Default()
+		} else {
+			imp.gc = importer.ForCompiler(imp.fset, "gc", func(path string) (io.ReadCloser, error) {
+				file := exports[path]
+				if file == "" {
+					return nil, fmt.Errorf("no export data for %s", path)
+				}
+				return os.Open(file)
+			})
+		}
+	}
+	return imp.gc.Import(path)
+}
+
+// list runs go list on the imports of the package and returns the name of
+// the export data file of each of them and their dependencies. A package
+// that fails to build has none.
+func (imp *exportImporter) list() (map[string]string, error) {
+	exports := make(map[string]string)
+	if len(imp.imports) == 0 {
+		// With no packages named, go list would list the one in imp.dir.
+		return exports, nil
+	}
+	args := []string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}"}
+	args = append(args, imp.args...)
+	cmd := exec.Command("go", append(args, imp.imports...)...)
+	cmd.Dir = imp.dir
+	cmd.Env = imp.env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %s\n%s", err, stderr.Bytes())
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if i := strings.IndexByte(line, '\t'); i >= 0 {
+			exports[line[:i]] = line[i+1:]
+		}
+	}
+	return exports, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -method flag, which gives the
+// String method another name.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+)
+
+// stringMethod returns the name of the method generated as String.
+func (g *generator) stringMethod() string {
+	switch {
+	case g.errorOnly:
+		return "Error"
+	case g.method != "":
+		return g.method
+	}
+	return "String"
+}
+
+// renameString renames the String method of the named type, in the code
+// generated for it from offset start in the buffer, to name, and the calls
+// of it by the other methods of the type on their receivers likewise.
+func (g *generator) renameString(typeName string, start int, name string) {
+	const header = "package p\n"
+	src := append([]byte(header), g.buf.Bytes()[start:]...)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		log.Printf("warning: internal error: invalid Go generated: %s", err)
+		return
+	}
+	// The offsets of the identifiers String to replace, in order.
+	var offsets []int
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil {
+			continue
+		}
+		recv := fn.Recv.List[0]
+		if id, ok := recv.Type.(*ast.Ident); !ok || id.Name != typeName {
+			continue
+		}
+		switch fn.Name.Name {
+		case "String":
+			offsets = append(offsets, fset.Position(fn.Name.Pos()).Offset)
+		case name:
+			failf("cannot name the String method of %s %s, the name of another method generated for it", typeName, name)
+		}
+		if len(recv.Names) == 0 {
+			continue
+		}
+		recvName := recv.Names[0].Name
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "String" {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == recvName {
+					offsets = append(offsets, fset.Position(sel.Sel.Pos()).Offset)
+				}
+			}
+			return true
+		})
+	}
+	var out []byte
+	last := len(header)
+	for _, offset := range offsets {
+		out = append(out, src[last:offset]...)
+		out = append(out, name...)
+		last = offset + len("String")
+	}
+	out = append(out, src[last:]...)
+	g.buf.Truncate(start)
+	g.buf.Write(out)
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the functions that find the types of a package to
+// generate code for.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Name returns the name of the package.
+func (pkg *Package) Name() string {
+	return pkg.name
+}
+
+// Declared returns those of the named types that the package declares.
+func (pkg *Package) Declared(typeNames []string) []string {
+	var names []string
+	for _, name := range typeNames {
+		if _, ok := pkg.typesPkg.Scope().Lookup(name).(*types.TypeName); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Marker is the comment that marks the types to be processed by the -all flag
+// of the stringer command.
+const Marker = "//stringer:generate"
+
+// Marked returns the names of the types in the package whose declarations are
+// marked with Marker, in the order they are declared.
+func (pkg *Package) Marked() []string {
+	var names []string
+	for _, file := range pkg.files {
+		for _, decl := range file.file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				doc := spec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+				if hasMarker(doc) {
+					names = append(names, spec.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// hasMarker reports whether one of the lines of the comment is Marker.
+func hasMarker(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == Marker {
+			return true
+		}
+	}
+	return false
+}
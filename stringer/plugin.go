@@ -0,0 +1,228 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the emitters that generate the code for a type beyond
+// String, and the handling of the -plugin flag, which runs other programs as
+// emitters.
+
+package stringer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"strconv"
+)
+
+// typeValues describes the constants of a type to the emitters.
+type typeValues struct {
+	name     string            // The name of the type.
+	all      []Value           // The constants in declaration order.
+	declared []Value           // The same, without the duplicated values.
+	runs     [][]Value         // The values sorted into runs of consecutive values.
+	names    map[uint64]string // Expressions for the names, indexed by value.
+	lookup   string            // How String finds the names.
+}
+
+// An emitter generates code for a type once its String method is generated.
+type emitter struct {
+	enabled func(g *generator) bool
+	emit    func(g *generator, t *typeValues)
+}
+
+// emitters lists the emitters in the order in which their code appears,
+// ending with the programs named by the -plugin flag.
+var emitters = []emitter{
+	{
+		// The value map serves the methods that look up names.
+		enabled: func(g *generator) bool { return g.parse || g.text || g.json || g.sql || g.gob || g.yaml },
+		emit:    func(g *generator, t *typeValues) { g.buildValueMap(t.runs, t.all, t.name, t.names) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.parse },
+		emit: func(g *generator, t *typeValues) {
+			fold, doc := "", ""
+			if hasAliases(t.all) {
+				doc = ", or that has the alias s"
+			}
+			if g.fold {
+				g.addImport("strings")
+				fold = fmt.Sprintf(foldLookup, t.name)
+				doc += ", in any case"
+			}
+			g.Printf(parseFunc, t.name, funcName("Parse", t.name), g.qualified(t.name), g.invalidError(t.name, "s"), fold, doc)
+		},
+	},
+	{
+		enabled: func(g *generator) bool { return g.text },
+		emit:    func(g *generator, t *typeValues) { g.buildText(t.name, &t.declared[0]) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.json },
+		emit:    func(g *generator, t *typeValues) { g.buildJSON(t.runs, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.sql },
+		emit: func(g *generator, t *typeValues) {
+			g.addImport("database/sql/driver")
+			g.addImport("fmt")
+			g.Printf(sqlMethods, t.name)
+		},
+	},
+	{
+		enabled: func(g *generator) bool { return g.gob },
+		emit: func(g *generator, t *typeValues) {
+			g.Printf(gobMethods, t.name, g.invalidValueError(t.name, "i", &t.declared[0]), g.invalidError(t.name, "string(data)"))
+		},
+	},
+	{
+		enabled: func(g *generator) bool { return g.binary },
+		emit:    func(g *generator, t *typeValues) { g.buildBinary(&t.declared[0], t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.yaml },
+		emit: func(g *generator, t *typeValues) {
+			g.Printf(yamlMethods, t.name, g.invalidValueError(t.name, "i", &t.declared[0]), g.invalidError(t.name, "s"))
+		},
+	},
+	{
+		enabled: func(g *generator) bool { return g.goString },
+		emit:    func(g *generator, t *typeValues) { g.buildGoString(t.declared, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.formatter },
+		emit:    func(g *generator, t *typeValues) { g.buildFormat(t.name, &t.declared[0]) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.slog },
+		emit:    func(g *generator, t *typeValues) { g.buildSlog(t.name, &t.declared[0]) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.errorMethod && !g.errorOnly },
+		emit:    func(g *generator, t *typeValues) { g.buildError(t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.group },
+		emit:    func(g *generator, t *typeValues) { g.buildGroup(t.declared, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.valueOf },
+		emit:    func(g *generator, t *typeValues) { g.buildValueOf(t.all, t.name, t.names) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.values },
+		emit:    func(g *generator, t *typeValues) { g.buildValues(t.declared, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.bounds },
+		emit:    func(g *generator, t *typeValues) { g.buildBounds(t.runs, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.description },
+		emit:    func(g *generator, t *typeValues) { g.buildDescription(t.all, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.localize },
+		emit:    func(g *generator, t *typeValues) { g.buildLocalize(t.declared, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.ordinal },
+		emit: func(g *generator, t *typeValues) {
+			g.buildOrdinal(t.declared, t.runs, t.name, t.lookup == "switch" && !g.flags)
+		},
+	},
+	{
+		enabled: func(g *generator) bool { return g.iter },
+		emit:    func(g *generator, t *typeValues) { g.buildIter(t.runs, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.strings },
+		emit:    func(g *generator, t *typeValues) { g.buildStrings(t.declared, t.name, t.names) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.proto },
+		emit:    func(g *generator, t *typeValues) { g.buildProto(t.all, t.name) },
+	},
+	{
+		// The test and schema are written to files of their own.
+		enabled: func(g *generator) bool { return g.test != nil },
+		emit:    func(g *generator, t *typeValues) { g.buildTest(t.declared, t.runs, t.name) },
+	},
+	{
+		enabled: func(g *generator) bool { return g.schema != nil },
+		emit:    func(g *generator, t *typeValues) { g.addSchema(t.name, t.declared) },
+	},
+	{
+		enabled: func(g *generator) bool { return len(g.plugins) > 0 },
+		emit: func(g *generator, t *typeValues) {
+			for _, plugin := range g.plugins {
+				g.runPlugin(plugin, t)
+			}
+		},
+	},
+}
+
+// pluginInput is the description of a type given to a plugin, as JSON on its
+// standard input.
+type pluginInput struct {
+	Package   string           `json:"package"`
+	Type      string           `json:"type"`
+	Constants []pluginConstant `json:"constants"`
+}
+
+// pluginConstant describes one of the constants of the type.
+type pluginConstant struct {
+	Name   string `json:"name"`          // The name of the constant.
+	String string `json:"string"`        // The name String prints for it.
+	Value  string `json:"value"`         // Its value, as a Go literal.
+	Doc    string `json:"doc,omitempty"` // Its doc comment, on one line.
+}
+
+// runPlugin runs the named plugin program on the description of the type and
+// adds the code it prints to the output. The code is a series of Go
+// declarations, which may begin with imports.
+func (g *generator) runPlugin(plugin string, t *typeValues) {
+	in := pluginInput{Package: g.pkg.name, Type: t.name}
+	for _, v := range t.all {
+		in.Constants = append(in.Constants, pluginConstant{v.originalName, v.name, v.str, v.doc})
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		failf("plugin %s: %s", plugin, err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(plugin)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		failf("plugin %s: %s\n%s", plugin, err, stderr.Bytes())
+	}
+	const header = "package p\n"
+	src := append([]byte(header), out...)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, plugin, src, parser.ImportsOnly)
+	if err != nil {
+		failf("plugin %s: invalid Go printed: %s", plugin, err)
+	}
+	// Move the imports into the header, and the rest after the code so far.
+	start := len(header)
+	for _, spec := range file.Imports {
+		if spec.Name != nil {
+			failf("plugin %s: import of %s must not be named", plugin, spec.Path.Value)
+		}
+		path, _ := strconv.Unquote(spec.Path.Value)
+		g.addImport(path)
+	}
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			start = fset.Position(decl.End()).Offset
+		}
+	}
+	fmt.Fprintf(&g.buf, "\n%s", src[start:])
+}
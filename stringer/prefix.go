@@ -0,0 +1,161 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -prefixes flag, which stores the
+// prefixes shared by the names of the constants once each, rather than in
+// every name.
+
+package stringer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// prefixTable holds the prefixes shared by the names of a type's constants.
+// The names are stored without them, and String adds them back.
+type prefixTable struct {
+	prefixes []string       // The prefixes, of which the first is empty.
+	index    map[string]int // The position in prefixes of the prefix of each name.
+}
+
+// sharedPrefixes returns the table of the prefixes that the names of the
+// values share, or nil if storing them once would not make the names shorter.
+// The prefixes end at the start of a word, as found by wordStarts. Each name
+// gets the longest that another name shares.
+func sharedPrefixes(runs [][]Value) *prefixTable {
+	count := make(map[string]int)
+	seen := make(map[string]bool)
+	var names []string
+	for _, run := range runs {
+		for i := range run {
+			name := run[i].name
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+			for _, n := range wordStarts(name) {
+				count[name[:n]]++
+			}
+		}
+	}
+	chosen := make(map[string]string)
+	users := make(map[string]int)
+	for _, name := range names {
+		starts := wordStarts(name)
+		for i := len(starts) - 1; i >= 0; i-- {
+			if prefix := name[:starts[i]]; count[prefix] > 1 {
+				chosen[name] = prefix
+				users[prefix]++
+				break
+			}
+		}
+	}
+	t := &prefixTable{
+		prefixes: []string{""},
+		index:    make(map[string]int),
+	}
+	saved := 0
+	for _, name := range names {
+		prefix := chosen[name]
+		if users[prefix] < 2 {
+			// Another name chose a longer prefix, leaving this one alone.
+			continue
+		}
+		k, ok := t.position(prefix)
+		if !ok {
+			k = len(t.prefixes)
+			t.prefixes = append(t.prefixes, prefix)
+			saved -= len(prefix)
+		}
+		t.index[name] = k
+		saved += len(prefix)
+	}
+	// Each value also needs an entry in the table of positions.
+	if saved <= len(names) {
+		return nil
+	}
+	return t
+}
+
+// position returns the position of the prefix in the table, if it is there.
+func (t *prefixTable) position(prefix string) (int, bool) {
+	for k, p := range t.prefixes {
+		if p == prefix {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// split returns the position in the table of the prefix of the name, and the
+// rest of the name. A nil table holds only the empty prefix.
+func (t *prefixTable) split(name string) (int, string) {
+	if t == nil {
+		return 0, name
+	}
+	k := t.index[name]
+	return k, name[len(t.prefixes[k]):]
+}
+
+// wordStarts returns the offsets in the name, other than its first, at which
+// a word begins: after a space or punctuation, or at an upper case letter
+// following one in lower case or a digit.
+func wordStarts(name string) []int {
+	var starts []int
+	var prev rune
+	for i, r := range name {
+		switch {
+		case i == 0:
+		case unicode.IsSpace(prev) || unicode.IsPunct(prev):
+			if !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+				starts = append(starts, i)
+			}
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			starts = append(starts, i)
+		}
+		prev = r
+	}
+	return starts
+}
+
+// declare declares the table of prefixes for the type.
+func (t *prefixTable) declare(g *generator, typeName string) {
+	quoted := make([]string, len(t.prefixes))
+	for i, p := range t.prefixes {
+		quoted[i] = fmt.Sprintf("%q", p)
+	}
+	g.Printf("var _%s_prefixes = [...]string{%s}\n\n", typeName, strings.Join(quoted, ", "))
+}
+
+// positions returns the declaration of the positions in the table of the
+// prefixes of the names in the run, for the caller to add "var".
+func (t *prefixTable) positions(run []Value, typeName, suffix string) string {
+	ks := make([]string, len(run))
+	for i := range run {
+		k, _ := t.split(run[i].name)
+		ks[i] = fmt.Sprint(k)
+	}
+	return fmt.Sprintf("_%s_prefix%s = [...]uint%d{%s}", typeName, suffix, usize(len(t.prefixes)), strings.Join(ks, ", "))
+}
+
+// declareRuns declares the table of prefixes for the type, and the positions
+// in it of the names in the runs: as a run with no suffix if there is one, and
+// otherwise for each run of several values, with the suffix of its number.
+func (t *prefixTable) declareRuns(g *generator, runs [][]Value, typeName string) {
+	t.declare(g, typeName)
+	if len(runs) == 1 {
+		g.Printf("var %s\n", t.positions(runs[0], typeName, ""))
+		return
+	}
+	g.Printf("var (")
+	for i, run := range runs {
+		if len(run) > 1 {
+			g.Printf("\t%s\n", t.positions(run, typeName, fmt.Sprintf("_%d", i)))
+		}
+	}
+	g.Printf(")\n\n")
+}
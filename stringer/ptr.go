@@ -0,0 +1,142 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -ptr flag, which gives the generated
+// methods pointer receivers.
+
+package stringer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+)
+
+// pointerReceivers rewrites the methods of the named type with value
+// receivers, in the code generated for it from offset start in the buffer, to
+// have pointer receivers. For a nil receiver a method returns zero values, or
+// "<nil>" from String, Error and LogValue and "nil" from GoString, and Format
+// prints "<nil>"; otherwise it works on a copy of the value, named as the receiver
+// was, so that the body is unchanged.
+func (g *generator) pointerReceivers(typeName string, start int) {
+	const header = "package p\n"
+	src := append([]byte(header), g.buf.Bytes()[start:]...)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		log.Printf("warning: internal error: invalid Go generated: %s", err)
+		return
+	}
+	// Each edit replaces the text between two offsets in src; they come in
+	// order.
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List[0].Names) == 0 {
+			continue
+		}
+		recv := fn.Recv.List[0]
+		if id, ok := recv.Type.(*ast.Ident); !ok || id.Name != typeName {
+			continue
+		}
+		name := recv.Names[0].Name
+		method := fn.Name.Name
+		if method == g.stringMethod() {
+			method = "String"
+		}
+		stmts := "\n\tif p == nil {\n\t\t" + nilStmt(fn, method) + "\n\t}"
+		if usesName(fn.Body, name) {
+			stmts += "\n\t" + name + " := *p"
+		}
+		body := fset.Position(fn.Body.Lbrace).Offset + 1
+		edits = append(edits,
+			edit{fset.Position(recv.Pos()).Offset, fset.Position(recv.Type.Pos()).Offset, "p *"},
+			edit{body, body, stmts},
+		)
+	}
+	var out []byte
+	last := len(header)
+	for _, e := range edits {
+		out = append(out, src[last:e.start]...)
+		out = append(out, e.text...)
+		last = e.end
+	}
+	out = append(out, src[last:]...)
+	g.buf.Truncate(start)
+	g.buf.Write(out)
+}
+
+// nilStmt returns the statement by which the method fn returns for a nil
+// receiver. The method is the generated method fn is, such as String for the
+// String method renamed by -method or -erroronly.
+func nilStmt(fn *ast.FuncDecl, method string) string {
+	switch {
+	case method == "Format":
+		// As fmt prints a nil pointer with no Format method.
+		f := fn.Type.Params.List[0].Names[0].Name
+		return f + `.Write([]byte("<nil>"))` + "\n\t\treturn"
+	case fn.Type.Results == nil:
+		return "return"
+	}
+	return "return " + nilResults(fn, method)
+}
+
+// nilResults returns the values returned by the method fn, the generated
+// method named, for a nil receiver.
+func nilResults(fn *ast.FuncDecl, method string) string {
+	var results string
+	for _, field := range fn.Type.Results.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for ; n > 0; n-- {
+			if results != "" {
+				results += ", "
+			}
+			results += zeroValue(method, field.Type)
+		}
+	}
+	return results
+}
+
+// zeroValue returns the value of the given type that the named method
+// returns for a nil receiver.
+func zeroValue(method string, typ ast.Expr) string {
+	id, ok := typ.(*ast.Ident)
+	switch {
+	case method == "LogValue":
+		return `slog.StringValue("<nil>")`
+	case !ok, id.Name == "error":
+		return "nil"
+	case id.Name == "string" && (method == "String" || method == "Error"):
+		return `"<nil>"`
+	case id.Name == "string" && method == "GoString":
+		return `"nil"`
+	case id.Name == "string":
+		return `""`
+	case id.Name == "bool":
+		return "false"
+	}
+	// A number, or the type itself.
+	return "0"
+}
+
+// usesName reports whether the named variable appears in the body.
+func usesName(body *ast.BlockStmt, name string) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
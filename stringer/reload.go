@@ -0,0 +1,67 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the reloading of packages whose files changed, for the
+// -watch flag of the stringer command.
+
+package stringer
+
+import (
+	"os"
+	"time"
+)
+
+// Reload returns the package loaded again, with the same files as Load or
+// LoadFiles found, or, for a directory, those it has now, selected by opts,
+// which may be nil. Only the files that changed since they were parsed are
+// parsed again, though the package is type-checked anew. If no file changed,
+// Reload returns pkg itself.
+func (pkg *Package) Reload(opts *Options) (newPkg *Package, err error) {
+	defer catch(&err)
+	g := newGenerator(opts)
+	names := pkg.names
+	if pkg.listed {
+		names = g.listFiles(pkg.dir)
+	}
+	if !pkg.changed(names) {
+		return pkg, nil
+	}
+	g.prev = pkg
+	g.parsePackage(pkg.dir, names, nil)
+	g.pkg.path = pkg.path
+	g.pkg.listed = pkg.listed
+	return g.pkg, nil
+}
+
+// changed reports whether the package is made of other files than the named
+// ones, or one of its Go files has changed since it was parsed.
+func (pkg *Package) changed(names []string) bool {
+	if len(names) != len(pkg.names) {
+		return true
+	}
+	for i, name := range names {
+		if name != pkg.names[i] {
+			return true
+		}
+		parsed, ok := pkg.parsed[name]
+		if !ok {
+			continue // Not a Go file.
+		}
+		info, err := os.Stat(name)
+		if err != nil || !info.ModTime().Equal(parsed.modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsedFile returns the named file as it was parsed for pkg, which may be
+// nil, if it was read when its modification time was modTime.
+func (pkg *Package) parsedFile(name string, modTime time.Time) (parsedFile, bool) {
+	if pkg == nil || modTime.IsZero() {
+		return parsedFile{}, false
+	}
+	parsed, ok := pkg.parsed[name]
+	return parsed, ok && parsed.modTime.Equal(modTime)
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the handling of the -schema flag, or the Schema option,
+// which writes a JSON Schema listing the names of the types beside the
+// generated code.
+
+package stringer
+
+import "strconv"
+
+// schemaURL identifies the version of JSON Schema written.
+const schemaURL = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaDoc is a JSON Schema document defining types under their names.
+type schemaDoc struct {
+	Schema string                 `json:"$schema"`
+	Defs   map[string]*schemaEnum `json:"$defs"`
+}
+
+// newSchema returns a schema document defining no types.
+func newSchema() *schemaDoc {
+	return &schemaDoc{Schema: schemaURL, Defs: make(map[string]*schemaEnum)}
+}
+
+// schemaEnum is the JSON Schema of a type, an enumeration of the strings its
+// String method returns for the constants.
+type schemaEnum struct {
+	Type string   `json:"type"`
+	Enum []string `json:"enum"`
+}
+
+// addSchema records the schema of the named type, whose constants, without
+// duplicates, are given in declaration order.
+func (g *generator) addSchema(typeName string, values []Value) {
+	enum := make([]string, len(values))
+	for i, v := range values {
+		enum[i] = v.name
+		if v.isString {
+			// String returns the value itself.
+			enum[i], _ = strconv.Unquote(v.str)
+		}
+	}
+	g.schema.Defs[typeName] = &schemaEnum{Type: "string", Enum: enum}
+}
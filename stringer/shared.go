@@ -0,0 +1,158 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains the tables in which String looks up the names of runs
+// of values, and their sharing by the types generated together.
+
+package stringer
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A nameTable locates the names of a run of values, stored one after another
+// in the string constant name: the name at position i of the run is
+// name[index[offset+i]:index[offset+i+1]].
+type nameTable struct {
+	name, index string
+	offset      int    // The position in index of the first name of the run.
+	start       int    // The position in name of the first name of the run.
+	count       string // A constant expression for the number of names in the run.
+	shared      bool   // Whether the string holds the names of other runs too.
+}
+
+// runTable returns the table declared for the run with the suffix by
+// createIndexAndNameDecl, which holds its names alone.
+func runTable(typeName, suffix string) *nameTable {
+	index := fmt.Sprintf("_%s_index%s", typeName, suffix)
+	return &nameTable{
+		name:  fmt.Sprintf("_%s_name%s", typeName, suffix),
+		index: index,
+		count: fmt.Sprintf("len(%s)-1", index),
+	}
+}
+
+// lookup returns the expression for the name at position i of the run,
+// given the expression j for the next position. The positions are converted
+// to int before the offset is added, so that the sum does not overflow.
+func (t *nameTable) lookup(i, j string) string {
+	if t.offset > 0 {
+		i, j = fmt.Sprintf("int(%s)+%d", i, t.offset), fmt.Sprintf("int(%s)+%d", i, t.offset+1)
+	}
+	return fmt.Sprintf("%[1]s[%[2]s[%[3]s]:%[2]s[%[4]s]]", t.name, t.index, i, j)
+}
+
+// slice returns the expression for the bytes from n to m of the names of
+// the run.
+func (t *nameTable) slice(n, m int) string {
+	return fmt.Sprintf("%s[%d:%d]", t.name, t.start+n, t.start+m)
+}
+
+// whole returns the expression for the name of a run of one value, whose
+// length is n.
+func (t *nameTable) whole(n int) string {
+	if t.shared {
+		return t.slice(0, n)
+	}
+	return t.name
+}
+
+// sharedNames holds the names of several runs in one string and one array of
+// their offsets: those of the types generated together with Options.Shared,
+// declared once at the end of the file rather than for each type, or those
+// of a const block with Options.Blocks. Names already stored in the same
+// order, as by types with the same constants, are stored once.
+type sharedNames struct {
+	name, index string
+	names       bytes.Buffer
+	offsets     []int    // The offset in names of each name, and of the end of the last.
+	list        []string // The names, in the order stored.
+}
+
+// newSharedNames returns the tables shared by the types of a file, named
+// after the first of them.
+func newSharedNames(typeName string) *sharedNames {
+	return &sharedNames{
+		name:    fmt.Sprintf("_%s_shared_name", typeName),
+		index:   fmt.Sprintf("_%s_shared_index", typeName),
+		offsets: []int{0},
+	}
+}
+
+// add stores the names of the run, without their prefixes if prefixes is not
+// nil, unless they are stored already, and returns the table locating them.
+func (s *sharedNames) add(run []Value, prefixes *prefixTable) *nameTable {
+	names := make([]string, len(run))
+	for i := range run {
+		_, names[i] = prefixes.split(run[i].name)
+	}
+	k, stored := s.find(names)
+	t := &nameTable{
+		name:   s.name,
+		index:  s.index,
+		offset: k,
+		start:  s.offsets[k],
+		count:  fmt.Sprint(len(run)),
+		shared: true,
+	}
+	for _, name := range names[stored:] {
+		s.names.WriteString(name)
+		s.offsets = append(s.offsets, s.names.Len())
+		s.list = append(s.list, name)
+	}
+	return t
+}
+
+// find returns the position among the stored names at which the names are
+// to be found, and how many of them are stored there already: all, if they
+// are stored one after another, or as many of the first as end the stored
+// names, which the rest are to follow.
+func (s *sharedNames) find(names []string) (k, stored int) {
+	for k := 0; k+len(names) <= len(s.list); k++ {
+		if equalNames(s.list[k:k+len(names)], names) {
+			return k, len(names)
+		}
+	}
+	n := len(names)
+	if n > len(s.list) {
+		n = len(s.list)
+	}
+	for ; n > 0; n-- {
+		if equalNames(s.list[len(s.list)-n:], names[:n]) {
+			return len(s.list) - n, n
+		}
+	}
+	return len(s.list), 0
+}
+
+// equalNames reports whether the lists hold the same names in the same order.
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// declare declares the string of names and the array of offsets, unless no
+// type stored any names in them.
+func (s *sharedNames) declare(g *generator) {
+	if len(s.offsets) == 1 {
+		return
+	}
+	g.Printf("\nconst %s = %q\n\n", s.name, s.names.String())
+	g.Printf("var %s = [...]uint%d{", s.index, usize(s.names.Len()))
+	for i, n := range s.offsets {
+		if i > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("%d", n)
+	}
+	g.Printf("}\n")
+}
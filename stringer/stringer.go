@@ -0,0 +1,2547 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.5
+
+// Package stringer finds the constants of the named types of a Go package
+// and generates code that prints them by name, such as String methods. It is
+// the implementation of the stringer command, whose documentation describes
+// the code generated, for use by other code generators.
+//
+// Load or LoadFiles type-checks a package. Its Values method returns the
+// constants of a type, which Runs sorts into runs of consecutive values, and
+// Generate writes the code for some of its types.
+package stringer // import "golang.org/x/tools/stringer"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	exact "go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Options specifies the code to generate and how to find the constants. The
+// zero value generates the String method alone. Most options correspond to a
+// flag of the stringer command, which documents them in more detail.
+type Options struct {
+	// Finding the files of a package.
+	Tags   []string // Build tags that select the files of a package directory.
+	GOOS   string   // Target operating system, if not the default.
+	GOARCH string   // Target architecture, if not the default.
+
+	// Naming the constants.
+	TrimPrefix  string              // Prefix to be removed from the names.
+	TrimSuffix  string              // Suffix to be removed from the names.
+	LineComment bool                // Whether to use a trailing line comment as the name.
+	Transform   func(string) string // Rewrites the names, such as a function from TransformStyle; may be nil.
+	Duplicates  string              // Which name String prints for equal values: first (or empty), last, join or error.
+	Char        string              // Which values print as quoted characters: fallback, all, or none if empty.
+
+	// The String method.
+	Lookup    string // How String finds names: switch, map, binarysearch, bits, or auto (or empty).
+	Threshold int    // Most runs for which String uses a switch with automatic lookup; zero for DefaultThreshold.
+	Flags     bool   // Whether the constants are bit flags.
+	Invalid   string // Format of the string for values with no name; or empty, or panic.
+	NoFmt     bool   // Whether to avoid importing fmt.
+	Ptr       bool   // Whether the methods have pointer receivers.
+	Method    string // Name of the String method, such as Label, if not String (or empty).
+	Prefixes  bool   // Whether to store the prefixes shared by the names once each.
+	Shared    bool   // Whether the types generated together store their names in the same tables.
+
+	// The other code, as generated by the flag of the same name in lower case.
+	Parse, Text, JSON, SQL, Gob, Binary, YAML, Proto        bool
+	ValueOf, Values, Bounds, Description, Localize, Ordinal bool
+	Iter, Strings, IsValid, GoString, Slog, Format          bool
+
+	// What MarshalText does with values with no name: writes the string of
+	// String (fallback, or empty), fails (error), or writes N (name:N).
+	TextInvalid string
+
+	// Whether Parse also accepts a name in another case, as compared by
+	// strings.EqualFold.
+	Fold bool
+
+	// Whether to generate an Error method returning the name, after the
+	// type name and ": " with ErrorPrefix. ErrorOnly implies Error, and
+	// names the String method Error in place of adding one.
+	Error, ErrorPrefix, ErrorOnly bool
+
+	// Whether the tables of names of String are split by the const blocks
+	// declaring the constants, and whether to generate a Group method
+	// returning the name given to the block of a value; see GroupMarker.
+	Blocks, Group bool
+
+	Template  *template.Template // Replaces the built-in generator, if set; see ParseTemplate.
+	Plugins   []string           // Programs that add code for each type.
+	OutputPkg string             // Name of the package to write the helpers into, if not that of the types.
+
+	// The output.
+	Args   []string  // The command line to record in the header, without the program name.
+	Header []byte    // Comment lines to add to the header.
+	Test   io.Writer // If set, receives a test of the generated code; implies Parse.
+	Schema io.Writer // If set, receives a JSON Schema of the names.
+}
+
+// DefaultThreshold is the default number of runs of consecutive values above
+// which String uses a map rather than a switch.
+const DefaultThreshold = 10
+
+// BuildContext returns the context in which the files of a package directory
+// are selected, according to the Tags, GOOS and GOARCH options.
+func (opts *Options) BuildContext() *build.Context {
+	return newGenerator(opts).buildContext()
+}
+
+// Load parses and type-checks the package in the directory, as selected by
+// opts, which may be nil. Type errors are reported only if they leave a
+// constant without a value, since the package may use code yet to be
+// generated.
+func Load(dir string, opts *Options) (pkg *Package, err error) {
+	defer catch(&err)
+	g := newGenerator(opts)
+	g.parsePackageDir(dir)
+	return g.pkg, nil
+}
+
+// LoadFiles is like Load for the package made of the named files.
+func LoadFiles(names []string, opts *Options) (pkg *Package, err error) {
+	defer catch(&err)
+	g := newGenerator(opts)
+	g.parsePackageFiles(names)
+	return g.pkg, nil
+}
+
+// Values returns the constants of the named type in the package, in the order
+// they are declared, named according to opts, which may be nil.
+func (pkg *Package) Values(typeName string, opts *Options) (values []Value, err error) {
+	defer catch(&err)
+	g := newGenerator(opts)
+	g.pkg = pkg
+	return g.findValues(typeName), nil
+}
+
+// Runs sorts the values, which must be of a single integer type, drops those
+// equal to another, and splits the rest into runs of consecutive values.
+func Runs(values []Value) [][]Value {
+	return splitIntoRuns(values)
+}
+
+// Generate writes the code set by opts, which may be nil, for the named types
+// of the package to w, as a formatted Go file.
+func Generate(w io.Writer, pkg *Package, typeNames []string, opts *Options) (err error) {
+	defer catch(&err)
+	if opts == nil {
+		opts = new(Options)
+	}
+	g := newGenerator(opts)
+	g.pkg = pkg
+	if opts.Test != nil {
+		g.test = g.newTest()
+	}
+	if opts.Schema != nil {
+		g.schema = newSchema()
+	}
+	if opts.Shared && !opts.Blocks && len(typeNames) > 1 {
+		g.shared = newSharedNames(typeNames[0])
+	}
+	for _, typeName := range typeNames {
+		g.generate(typeName)
+	}
+	if g.shared != nil {
+		g.shared.declare(g)
+	}
+	// Print the header and package clause, now that the imports are known.
+	g.printHeader(opts.Args)
+	if _, err := w.Write(g.format()); err != nil {
+		return err
+	}
+	if g.test != nil {
+		g.test.printHeader(opts.Args)
+		if _, err := opts.Test.Write(g.test.format()); err != nil {
+			return err
+		}
+	}
+	if g.schema != nil {
+		data, err := json.MarshalIndent(g.schema, "", "\t")
+		if err != nil {
+			return err
+		}
+		if _, err := opts.Schema.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generator holds the state of the analysis. Primarily used to buffer
+// the output for format.Source.
+type generator struct {
+	buf     bytes.Buffer    // Accumulated output.
+	pkg     *Package        // Package we are scanning.
+	imports map[string]bool // Packages imported by the generated code.
+	prev    *Package        // Package being reloaded, whose unchanged files are reused.
+
+	trimPrefix  string              // Prefix to be removed from the constant names.
+	trimSuffix  string              // Suffix to be removed from the constant names.
+	duplicates  string              // Policy for constants with the same value; empty for first.
+	lineComment bool                // Whether to use a trailing line comment as the printed name.
+	transform   func(string) string // Rewrites the constant names; may be nil.
+	parse       bool                // Whether to generate a Parse function for each type.
+	fold        bool                // Whether Parse accepts names in any case.
+	text        bool                // Whether to generate encoding.TextMarshaler and TextUnmarshaler methods.
+	textInvalid string              // What MarshalText does with values with no name; empty for fallback.
+	json        bool                // Whether to generate json.Marshaler and json.Unmarshaler methods.
+	sql         bool                // Whether to generate sql.Scanner and driver.Valuer methods.
+	gob         bool                // Whether to generate gob.GobEncoder and GobDecoder methods.
+	binary      bool                // Whether to generate encoding.BinaryMarshaler and BinaryUnmarshaler methods.
+	yaml        bool                // Whether to generate MarshalYAML and UnmarshalYAML methods.
+	proto       bool                // Whether to generate protocol buffer style name and value maps.
+	valueOf     bool                // Whether to generate a function looking up the values by name.
+	values      bool                // Whether to generate a function returning all the values.
+	bounds      bool                // Whether to generate constants for the least and greatest values and their number.
+	description bool                // Whether to generate a Description method returning the doc comments.
+	localize    bool                // Whether to generate MessageKey and Localized methods.
+	ordinal     bool                // Whether to generate an Ordinal method.
+	iter        bool                // Whether to generate Next and Prev methods and the first and last values.
+	strings     bool                // Whether to generate a function returning all the names.
+	isValid     bool                // Whether to generate an IsValid method.
+	goString    bool                // Whether to generate a GoString method.
+	method      string              // Name of the String method; empty for String.
+	formatter   bool                // Whether to generate a Format method; implies goString.
+	slog        bool                // Whether to generate a LogValue method for log/slog.
+	errorMethod bool                // Whether to generate an Error method.
+	errorPrefix bool                // Whether Error prefixes the name with the type name.
+	errorOnly   bool                // Whether the String method is named Error instead; implies errorMethod.
+	blocks      bool                // Whether the tables of names are split by const block.
+	group       bool                // Whether to generate a Group method.
+	ptr         bool                // Whether the methods have pointer receivers.
+	prefixes    bool                // Whether to store the prefixes shared by the names once each.
+	shared      *sharedNames        // The tables of names shared by the types, if they are shared.
+	flags       bool                // Whether the constants are bit flags.
+	invalid     string              // Format for values with no name; see -invalid.
+	char        string              // Which values print as quoted characters: fallback, all or none if empty.
+	noFmt       bool                // Whether to avoid importing fmt.
+	test        *generator          // Accumulates a test of the generated code, if one is wanted.
+	schema      *schemaDoc          // Accumulates a JSON Schema of the names, if one is wanted.
+	tags        []string            // Build tags that select the files of a package directory.
+	goos        string              // Target operating system, if not the default.
+	goarch      string              // Target architecture, if not the default.
+	outputPkg   string              // Name of the package to write into, if not that of the type.
+	template    *template.Template  // Replaces the built-in generator, if set.
+	plugins     []string            // Programs that add code for each type; see -plugin.
+	header      []byte              // Comment lines added to the header; see -header.
+	lookup      string              // How String finds names; empty to choose by the values.
+	threshold   int                 // Most runs for which String uses a switch; zero for DefaultThreshold.
+}
+
+// newGenerator returns a generator with the settings of opts, which may be nil.
+func newGenerator(opts *Options) *generator {
+	if opts == nil {
+		return new(generator)
+	}
+	g := &generator{
+		trimPrefix:  opts.TrimPrefix,
+		trimSuffix:  opts.TrimSuffix,
+		duplicates:  opts.Duplicates,
+		lineComment: opts.LineComment,
+		transform:   opts.Transform,
+		parse:       opts.Parse || opts.Test != nil,
+		fold:        opts.Fold,
+		text:        opts.Text,
+		textInvalid: opts.TextInvalid,
+		json:        opts.JSON,
+		sql:         opts.SQL,
+		gob:         opts.Gob,
+		binary:      opts.Binary,
+		yaml:        opts.YAML,
+		proto:       opts.Proto,
+		valueOf:     opts.ValueOf,
+		values:      opts.Values,
+		bounds:      opts.Bounds,
+		description: opts.Description,
+		localize:    opts.Localize,
+		ordinal:     opts.Ordinal,
+		iter:        opts.Iter,
+		strings:     opts.Strings,
+		isValid:     opts.IsValid,
+		goString:    opts.GoString || opts.Format,
+		formatter:   opts.Format,
+		method:      opts.Method,
+		slog:        opts.Slog,
+		errorMethod: opts.Error || opts.ErrorOnly,
+		errorPrefix: opts.ErrorPrefix,
+		errorOnly:   opts.ErrorOnly,
+		blocks:      opts.Blocks,
+		group:       opts.Group,
+		ptr:         opts.Ptr,
+		prefixes:    opts.Prefixes,
+		flags:       opts.Flags,
+		invalid:     opts.Invalid,
+		char:        opts.Char,
+		noFmt:       opts.NoFmt,
+		tags:        opts.Tags,
+		goos:        opts.GOOS,
+		goarch:      opts.GOARCH,
+		outputPkg:   opts.OutputPkg,
+		template:    opts.Template,
+		plugins:     opts.Plugins,
+		header:      opts.Header,
+		lookup:      opts.Lookup,
+		threshold:   opts.Threshold,
+	}
+	if g.lookup == "auto" {
+		g.lookup = ""
+	}
+	if g.textInvalid == "fallback" {
+		g.textInvalid = ""
+	}
+	return g
+}
+
+func (g *generator) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// addImport records that the generated code uses the package with the given path.
+func (g *generator) addImport(path string) {
+	if g.imports == nil {
+		g.imports = make(map[string]bool)
+	}
+	g.imports[path] = true
+}
+
+// printHeader inserts the header comment, package clause and imports before
+// the generated code, which must be complete since it determines the imports.
+// The args are those of the stringer command line, recorded in the header.
+func (g *generator) printHeader(args []string) {
+	body := append([]byte(nil), g.buf.Bytes()...)
+	g.buf.Reset()
+	g.Printf("// Code generated by \"stringer %s\" (stringer %s); DO NOT EDIT.\n", strings.Join(args, " "), version())
+	g.buf.Write(g.header)
+	g.Printf("\n")
+	name := g.pkg.name
+	if g.outputPkg != "" {
+		name = g.outputPkg
+	}
+	g.Printf("package %s", name)
+	g.Printf("\n")
+	g.printImports(body)
+	g.buf.Write(body)
+}
+
+// version returns the version of stringer recorded in the binary by the go
+// command, or devel if there is none.
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+// File holds a single parsed file and associated data.
+type File struct {
+	pkg  *Package  // Package to which this file belongs.
+	file *ast.File // Parsed AST.
+	// These fields are reset for each type being generated.
+	typeName string     // Name of the constant type.
+	typ      types.Type // The type it denotes.
+	values   []Value    // Accumulator for constant values of that type.
+
+	trimPrefix  string
+	trimSuffix  string
+	lineComment bool
+	transform   func(string) string
+}
+
+// A Package is a type-checked package, as returned by Load. It must not be
+// used by several goroutines at once, but different packages may be loaded
+// and generated in parallel.
+type Package struct {
+	dir      string
+	name     string
+	defs     map[*ast.Ident]types.Object
+	exprs    map[ast.Expr]types.TypeAndValue // Types and values of the expressions.
+	fset     *token.FileSet
+	files    []*File
+	path     string // Import path, if known.
+	typesPkg *types.Package
+	errors   []error // Type errors found by check.
+
+	// These fields let Reload parse again only the files that changed.
+	names  []string              // Names of the files, as given to parsePackage.
+	listed bool                  // The names were listed from dir, and are listed again.
+	parsed map[string]parsedFile // The files as parsed, by name.
+}
+
+// A parsedFile is a file of a package as it was parsed, before any cgo
+// translation, with its modification time when it was read.
+type parsedFile struct {
+	file    *ast.File
+	modTime time.Time
+}
+
+// buildContext returns the context in which to select the files of a package
+// directory, according to the -tags, -goos and -goarch flags.
+func (g *generator) buildContext() *build.Context {
+	ctxt := build.Default
+	ctxt.BuildTags = g.tags
+	if g.goos != "" {
+		ctxt.GOOS = g.goos
+	}
+	if g.goarch != "" {
+		ctxt.GOARCH = g.goarch
+	}
+	return &ctxt
+}
+
+// parsePackageDir parses the package residing in the directory.
+func (g *generator) parsePackageDir(directory string) {
+	g.parsePackage(directory, g.listFiles(directory), nil)
+	g.pkg.path = importPath(directory)
+	g.pkg.listed = true
+}
+
+// listFiles returns the names of the files of the package in the directory.
+func (g *generator) listFiles(directory string) []string {
+	pkg, err := g.buildContext().ImportDir(directory, 0)
+	if err != nil {
+		failAt(FileError, token.Position{Filename: directory}, "cannot process directory %s: %s", directory, err)
+	}
+	var names []string
+	names = append(names, pkg.GoFiles...)
+	names = append(names, pkg.CgoFiles...)
+	// TODO: Need to think about constants in test files. Maybe write type_string_test.go
+	// in a separate pass? For later.
+	// names = append(names, pkg.TestGoFiles...) // These are also in the "foo" package.
+	names = append(names, pkg.SFiles...)
+	return prefixDirectory(directory, names)
+}
+
+// parsePackageFiles parses the package occupying the named files.
+func (g *generator) parsePackageFiles(names []string) {
+	g.parsePackage(".", names, nil)
+	g.pkg.path = importPath(filepath.Dir(names[0]))
+}
+
+// importPath returns the import path of the package in the directory, or the
+// empty string if it is not known.
+func importPath(directory string) string {
+	// A relative directory would produce a local import path.
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		return ""
+	}
+	pkg, err := build.Default.ImportDir(abs, build.FindOnly)
+	// Directories outside GOPATH get a path beginning with an underscore.
+	if err != nil || build.IsLocalImport(pkg.ImportPath) || strings.HasPrefix(pkg.ImportPath, "_") {
+		return ""
+	}
+	return pkg.ImportPath
+}
+
+// prefixDirectory places the directory name on the beginning of each name in the list.
+func prefixDirectory(directory string, names []string) []string {
+	if directory == "." {
+		return names
+	}
+	ret := make([]string, len(names))
+	for i, name := range names {
+		ret[i] = filepath.Join(directory, name)
+	}
+	return ret
+}
+
+// parsePackage analyzes the single package constructed from the named files.
+// If text is non-nil, it is a string to be used instead of the content of the file,
+// to be used for testing. parsePackage exits if there is an error. The files
+// of g.prev, if set, that have not changed since it was parsed are reused.
+func (g *generator) parsePackage(directory string, names []string, text interface{}) {
+	var files []*File
+	var goNames []string
+	g.pkg = &Package{names: names, parsed: make(map[string]parsedFile)}
+	fs := token.NewFileSet()
+	if g.prev != nil {
+		fs = g.prev.fset
+	}
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		var modTime time.Time
+		if text == nil {
+			if info, err := os.Stat(name); err == nil {
+				modTime = info.ModTime()
+			}
+		}
+		parsed, ok := g.prev.parsedFile(name, modTime)
+		if !ok {
+			f, err := parser.ParseFile(fs, name, text, parser.ParseComments)
+			if err != nil {
+				failAt(FileError, errorPos(name, err), "parsing package: %s: %s", name, err)
+			}
+			parsed = parsedFile{f, modTime}
+		}
+		g.pkg.parsed[name] = parsed
+		goNames = append(goNames, name)
+		files = append(files, &File{
+			file: parsed.file,
+			pkg:  g.pkg,
+		})
+	}
+	if len(files) == 0 {
+		failAt(FileError, token.Position{Filename: directory}, "%s: no buildable Go files", directory)
+	}
+	g.pkg.name = files[0].file.Name.Name
+	g.pkg.files = files
+	g.pkg.dir = directory
+	var astFiles []*ast.File
+	if text == nil {
+		// Constants may be defined by C.
+		astFiles = g.translateCgo(fs, files, goNames)
+	} else {
+		for _, file := range files {
+			astFiles = append(astFiles, file.file)
+		}
+	}
+	// Type check the package.
+	g.pkg.check(fs, astFiles, g.newImporter(filepath.Dir(goNames[0]), fs, astFiles))
+}
+
+// check type-checks the package. Type errors are recorded rather than being
+// fatal, since the package may refer to functions that stringer has yet to
+// generate; they are reported only if they leave a constant without a value.
+// The imported packages are found by imp.
+func (pkg *Package) check(fs *token.FileSet, astFiles []*ast.File, imp types.Importer) {
+	pkg.defs = make(map[*ast.Ident]types.Object)
+	pkg.exprs = make(map[ast.Expr]types.TypeAndValue)
+	pkg.fset = fs
+	config := types.Config{
+		Importer:    imp,
+		FakeImportC: true,
+		Error: func(err error) {
+			pkg.errors = append(pkg.errors, err)
+		},
+	}
+	info := &types.Info{
+		Defs:  pkg.defs,
+		Types: pkg.exprs,
+	}
+	typesPkg, _ := config.Check(pkg.dir, fs, astFiles, info)
+	pkg.typesPkg = typesPkg
+}
+
+// generate produces the String method for the named type.
+func (g *generator) generate(typeName string) {
+	if g.ptr {
+		// Rewrite the methods once they have all been generated.
+		defer g.pointerReceivers(typeName, g.buf.Len())
+	}
+	if g.errorOnly {
+		if g.errorPrefix {
+			failf("-errorprefix does not apply with -erroronly, as the methods writing names would write the prefix")
+		}
+		if g.template != nil || len(g.plugins) > 0 || g.test != nil {
+			failf("-erroronly does not apply with -template, -plugin or -gentest, which call String")
+		}
+	}
+	if g.method != "" && g.method != "String" {
+		if g.errorOnly {
+			failf("-method does not apply with -erroronly, which names the String method Error")
+		}
+		if !token.IsIdentifier(g.method) {
+			failf("-method: %q is not a valid method name", g.method)
+		}
+		if g.template != nil || len(g.plugins) > 0 || g.test != nil {
+			failf("-method does not apply with -template, -plugin or -gentest, which call String")
+		}
+	}
+	if name := g.stringMethod(); name != "String" {
+		// Rename String once the methods have all been generated, and
+		// before -ptr rewrites them.
+		defer g.renameString(typeName, g.buf.Len(), name)
+	}
+	values := g.findValues(typeName)
+	if len(values) > 0 && g.template != nil {
+		g.executeTemplate(typeName, values)
+		return
+	}
+	if len(values) > 0 && values[0].isString {
+		g.generateString(typeName, values)
+		return
+	}
+	if g.flags && g.formatter {
+		failf("-format does not apply with -flags")
+	}
+	if g.flags && g.goString {
+		failf("-gostring does not apply with -flags")
+	}
+	if len(values) > 0 && values[0].isFloat && (g.json || g.sql || g.flags) {
+		failf("-json, -sql and -flags do not apply to %s, whose underlying type is floating-point", typeName)
+	}
+	if g.flags {
+		values = singleBits(values)
+	}
+	if len(values) == 0 {
+		if len(g.pkg.errors) > 0 {
+			g.pkg.failChecking()
+		}
+		failAt(NoValues, g.pkg.typePos(typeName), "no values defined for type %s", typeName)
+	}
+	if g.char != "" {
+		g.checkChar(typeName, &values[0])
+	}
+	if g.char == "all" {
+		quoteChars(values)
+	}
+	g.resolveDuplicates(typeName, values)
+	if hasAliases(values) || g.fold && g.parse {
+		g.checkNames(typeName, values)
+	}
+	// splitIntoRuns sorts the values in place, so keep the declaration order,
+	// both without and, for -proto, with the duplicates.
+	all := append([]Value(nil), values...)
+	declared := unique(values)
+	runs := splitIntoRuns(values)
+	if g.blocks {
+		runs = splitBlocks(runs)
+	}
+	// The decision of which pattern to use depends on the number of
+	// runs in the numbers. If there's only one, it's easy. For more than
+	// one, there's a tradeoff between complexity and size of the data
+	// and code vs. the simplicity of a map. A map takes more space,
+	// but so does the code. The decision here (crossover at DefaultThreshold,
+	// unless set by -threshold) is arbitrary, but considers that for large
+	// numbers of runs the cost of the linear scan in the switch might become
+	// important, and so we use a map. In any case, the likelihood of a map
+	// being necessary for any realistic example other than bitmasks
+	// is very low. The -lookup flag overrides the decision.
+	lookup := g.lookup
+	threshold := g.threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	switch {
+	case lookup == "switch" && values[0].isFloat:
+		failf("-lookup=switch does not apply to %s, whose underlying type is floating-point", typeName)
+	case lookup == "bits" && !singleBitValues(runs):
+		failf("-lookup=bits does not apply to %s, whose values are not all powers of two", typeName)
+	case lookup != "":
+	case values[0].isFloat:
+		// There are no runs of floating-point values to speak of.
+		lookup = "map"
+	case len(runs) > 1 && singleBitValues(runs):
+		// Flags declared with 1 << iota are each a run of their own.
+		lookup = "bits"
+	case len(runs) <= threshold:
+		lookup = "switch"
+	default:
+		lookup = "map"
+	}
+	// The marshaling methods use IsValid to check values.
+	// GoString may use it to check for constants too.
+	isValid := g.isValid || g.json || g.sql || g.gob || g.yaml || g.binary || g.text && g.textInvalid != "" || g.slog || g.goString && sameNames(declared)
+	var tables []*nameTable   // The tables of the names of the runs, if String uses them.
+	var prefixes *prefixTable // Only String methods using runs store the prefixes apart.
+	switch {
+	case g.outputPkg != "":
+		// Methods must be declared in the package of the type, so only the
+		// names are needed here, for the helper functions.
+		g.checkOutputPkg(typeName, declared)
+		g.Printf("\n")
+		g.declareNameVars(runs, typeName, "")
+	case g.flags:
+		g.buildFlags(runs, typeName)
+		if isValid {
+			g.Printf(isValidFlags, typeName)
+		}
+	case lookup == "binarysearch":
+		g.buildBinarySearch(runs, typeName)
+		if isValid {
+			g.Printf(isValidBinarySearch, typeName)
+		}
+	case lookup == "map":
+		g.buildMap(runs, typeName)
+		if isValid {
+			g.Printf(isValidMap, typeName)
+		}
+	case lookup == "bits":
+		g.buildBits(runs, typeName)
+		if isValid {
+			g.Printf(isValidBits, typeName)
+		}
+	case len(runs) == 1:
+		if g.prefixes {
+			prefixes = sharedPrefixes(runs)
+		}
+		tables = g.buildOneRun(runs, typeName, prefixes)
+		if isValid {
+			g.buildOneRunIsValid(runs, typeName, tables[0])
+		}
+	default:
+		if g.prefixes {
+			prefixes = sharedPrefixes(runs)
+		}
+		tables = g.buildMultipleRuns(runs, typeName, prefixes)
+		if isValid {
+			g.buildMultipleRunsIsValid(runs, typeName)
+		}
+	}
+	t := &typeValues{
+		name:     typeName,
+		all:      all,
+		declared: declared,
+		runs:     runs,
+		names:    nameExprs(runs, typeName, tables, prefixes),
+		lookup:   lookup,
+	}
+	for _, e := range emitters {
+		if e.enabled(g) {
+			e.emit(g, t)
+		}
+	}
+}
+
+// findValues returns the constants of the named type, in the order they are
+// declared, with the names String prints for them.
+func (g *generator) findValues(typeName string) []Value {
+	// The type may be an alias, or declared in any file of the package.
+	obj, _ := g.pkg.typesPkg.Scope().Lookup(typeName).(*types.TypeName)
+	if obj == nil {
+		if len(g.pkg.errors) > 0 {
+			g.pkg.failChecking()
+		}
+		failAt(TypeNotFound, token.Position{}, "no type %s in package %s", typeName, g.pkg.name)
+	}
+	values := make([]Value, 0, 100)
+	for _, file := range g.pkg.files {
+		// Set the state for this run of the walker.
+		file.typeName = typeName
+		file.typ = obj.Type()
+		file.values = nil
+		file.trimPrefix = g.trimPrefix
+		file.trimSuffix = g.trimSuffix
+		file.lineComment = g.lineComment
+		file.transform = g.transform
+		if file.file != nil {
+			ast.Inspect(file.file, file.genDecl)
+			values = append(values, file.values...)
+		}
+	}
+	if g.trimSuffix != "" {
+		checkTrimmed(values)
+	}
+	return values
+}
+
+// singleBits returns the values that are zero or have a single bit set,
+// which are those that can be combined as flags.
+func singleBits(values []Value) []Value {
+	var bits []Value
+	for _, v := range values {
+		if v.value&(v.value-1) == 0 {
+			bits = append(bits, v)
+		}
+	}
+	return bits
+}
+
+// resolveDuplicates applies the -duplicates policy to the values, given in
+// declaration order, by placing the one whose name is to be printed first
+// among those with the same value, or, for join, giving the first the names
+// of them all.
+func (g *generator) resolveDuplicates(typeName string, values []Value) {
+	first := make(map[string]int)
+	for i := range values {
+		j, ok := first[values[i].str]
+		if !ok {
+			first[values[i].str] = i
+			continue
+		}
+		switch g.duplicates {
+		case "last":
+			values[i], values[j] = values[j], values[i]
+		case "join":
+			values[j].name += "/" + values[i].name
+		case "error":
+			failf("-duplicates=error: %s and %s have the same %s value %s", values[j].originalName, values[i].originalName, typeName, values[i].str)
+		}
+	}
+}
+
+// unique returns a copy of values in the same order, omitting any value
+// equal to one that precedes it.
+func unique(values []Value) []Value {
+	seen := make(map[uint64]bool)
+	var u []Value
+	for _, v := range values {
+		if !seen[v.value] {
+			seen[v.value] = true
+			u = append(u, v)
+		}
+	}
+	return u
+}
+
+// splitIntoRuns breaks the values into runs of contiguous sequences.
+// For example, given 1,2,3,5,6,7 it returns {1,2,3},{5,6,7}.
+// The input slice is known to be non-empty.
+func splitIntoRuns(values []Value) [][]Value {
+	// We use stable sort so the lexically first name is chosen for equal elements.
+	sort.Stable(byValue(values))
+	// Remove duplicates. Stable sort has put the one we want to print first,
+	// so use that one. The String method won't care about which named constant
+	// was the argument, so the first name for the given value is the only one to keep.
+	// We need to do this because identical values would cause the switch or map
+	// to fail to compile.
+	j := 1
+	for i := 1; i < len(values); i++ {
+		if values[i].value != values[i-1].value {
+			values[j] = values[i]
+			j++
+		}
+	}
+	values = values[:j]
+	runs := make([][]Value, 0, 10)
+	for len(values) > 0 {
+		// One contiguous sequence per outer loop.
+		i := 1
+		for i < len(values) && values[i].value == values[i-1].value+1 {
+			i++
+		}
+		runs = append(runs, values[:i])
+		values = values[i:]
+	}
+	return runs
+}
+
+// format returns the gofmt-ed contents of the generator's buffer.
+func (g *generator) format() []byte {
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		// The user can compile the output to see the error.
+		log.Printf("warning: internal error: invalid Go generated: %s", err)
+		log.Printf("warning: compile the package to analyze the error")
+		return g.buf.Bytes()
+	}
+	return src
+}
+
+// Value represents a declared constant.
+type Value struct {
+	originalName string // The name of the constant.
+	name         string // The name with trimmed prefix and transformed, or the line comment text.
+	// The value is stored as a bit pattern alone. The boolean tells us
+	// whether to interpret it as an int64 or a uint64; the only place
+	// this matters is when sorting.
+	// Much of the time the str field is all we need; it is printed
+	// by Value.String.
+	value  uint64 // Will be converted to int64 when needed.
+	signed bool   // Whether the constant is a signed type.
+	bits   uint   // The size of the type in bits.
+	str    string // The string representation given by the "go/exact" package.
+	// For a type whose underlying type is string, value, signed and bits
+	// are unused and str holds the quoted value.
+	isString bool
+	// For a floating-point type, value holds a key that sorts in the order
+	// of the numbers and str is the shortest literal for the value.
+	isFloat bool
+	doc     string    // The doc comment of the constant, on one line.
+	aliases []string  // Other names by which the constant is read; see AliasMarker.
+	block   token.Pos // The position of the const block declaring the constant.
+	group   string    // The name of the group of the block; see GroupMarker.
+}
+
+// String returns the value as a Go literal.
+func (v *Value) String() string {
+	return v.str
+}
+
+// Name returns the name String prints for the value.
+func (v *Value) Name() string {
+	return v.name
+}
+
+// OriginalName returns the name of the constant.
+func (v *Value) OriginalName() string {
+	return v.originalName
+}
+
+// Doc returns the doc comment of the constant, on one line.
+func (v *Value) Doc() string {
+	return v.doc
+}
+
+// Int64 returns the value of a constant of a signed integer type.
+func (v *Value) Int64() int64 {
+	return int64(v.value)
+}
+
+// Uint64 returns the value of a constant of an unsigned integer type.
+func (v *Value) Uint64() uint64 {
+	return v.value
+}
+
+// negative reports whether v is below zero. The code for a run of values
+// starting at such a v does not subtract it, which would negate a constant,
+// overflowing for math.MinInt64, but computes the position in the run with
+// above.
+func (v *Value) negative() bool {
+	return v.signed && int64(v.value) < 0
+}
+
+// above returns an expression for how far the value of expr is above v, as a
+// uint64 that wraps around for values below v. For negative v it adds the
+// magnitude of v, which fits in a uint64 even for math.MinInt64.
+func (v *Value) above(expr string) string {
+	if v.negative() {
+		return fmt.Sprintf("uint64(%s) + %d", expr, -v.value)
+	}
+	return fmt.Sprintf("uint64(%s) - %d", expr, v.value)
+}
+
+// format returns the verb with which to print a value of v's type, and the
+// conversion that keeps fmt from calling the value's String method.
+func (v *Value) format() (verb, conv string) {
+	switch {
+	case v.isFloat:
+		return "%g", "float64"
+	case v.signed:
+		return "%d", "int64"
+	}
+	return "%d", "uint64"
+}
+
+// byValue lets us sort the constants into increasing order.
+// We take care in the Less method to sort in signed or unsigned order,
+// as appropriate.
+type byValue []Value
+
+func (b byValue) Len() int      { return len(b) }
+func (b byValue) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byValue) Less(i, j int) bool {
+	if b[i].signed {
+		return int64(b[i].value) < int64(b[j].value)
+	}
+	return b[i].value < b[j].value
+}
+
+// byString sorts constants of string types into increasing order.
+type byString []Value
+
+func (b byString) Len() int           { return len(b) }
+func (b byString) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byString) Less(i, j int) bool { return b[i].str < b[j].str }
+
+// genDecl processes one declaration clause.
+func (f *File) genDecl(node ast.Node) bool {
+	decl, ok := node.(*ast.GenDecl)
+	if !ok || decl.Tok != token.CONST {
+		// We only care about const declarations.
+		return true
+	}
+	group := groupName(decl.Doc)
+	// Loop over the elements of the declaration. Each element is a ValueSpec:
+	// a list of names possibly followed by a type, possibly followed by values.
+	// Rather than matching the type as written, which may be missing, carried
+	// down from a previous line, an alias, or hidden in a conversion such as
+	// Day(3), we let the type checker tell us the type of each constant.
+	for _, spec := range decl.Specs {
+		vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
+		// The comment on a declaration without parentheses documents its constants.
+		docGroup := vspec.Doc
+		if docGroup == nil && !decl.Lparen.IsValid() {
+			docGroup = decl.Doc
+		}
+		doc := strings.Join(strings.Fields(docGroup.Text()), " ")
+		aliases := f.aliases(vspec, docGroup)
+		// Grab the names and actual values of the constants of the desired
+		// type and store them in f.values.
+		for _, name := range vspec.Names {
+			if name.Name == "_" {
+				continue
+			}
+			// This dance lets the type checker find the values for us. It's a
+			// bit tricky: look up the object declared by the name, find its
+			// types.Const, and extract its value.
+			obj, ok := f.pkg.defs[name]
+			if !ok {
+				failAt(FileError, f.pkg.fset.Position(name.Pos()), "no value for constant %s", name)
+			}
+			if !types.Identical(obj.Type(), f.typ) {
+				// This is not the type we're looking for.
+				continue
+			}
+			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
+			if value.Kind() == exact.Unknown && len(f.pkg.errors) > 0 {
+				f.pkg.failChecking()
+			}
+			info := obj.Type().Underlying().(*types.Basic).Info()
+			if info&types.IsString != 0 {
+				f.values = append(f.values, Value{
+					originalName: name.Name,
+					name:         name.Name,
+					str:          strconv.Quote(exact.StringVal(value)),
+					isString:     true,
+					aliases:      aliases,
+					block:        decl.Pos(),
+					group:        group,
+				})
+				continue
+			}
+			if info&types.IsFloat != 0 {
+				v := f.floatValue(name.Name, obj.Type().Underlying().(*types.Basic), value, vspec)
+				v.doc = doc
+				v.aliases = aliases
+				v.block, v.group = decl.Pos(), group
+				f.values = append(f.values, v)
+				continue
+			}
+			if info&types.IsInteger == 0 {
+				failf("can't handle non-integer constant type %s", f.typeName)
+			}
+			if value.Kind() != exact.Int {
+				failf("can't happen: constant is not an integer %s", name)
+			}
+			i64, isInt := exact.Int64Val(value)
+			u64, isUint := exact.Uint64Val(value)
+			if !isInt && !isUint {
+				failf("internal error: value of %s is not an integer: %s", name, value.String())
+			}
+			if !isUint {
+				// Negative; store the bit pattern.
+				u64 = uint64(i64)
+			}
+			v := Value{
+				originalName: name.Name,
+				value:        u64,
+				signed:       info&types.IsUnsigned == 0,
+				bits:         bitSize(obj.Type().Underlying().(*types.Basic)),
+				str:          value.String(),
+				doc:          doc,
+				aliases:      aliases,
+				block:        decl.Pos(),
+				group:        group,
+			}
+			v.name = f.printedName(v.originalName, vspec)
+			f.values = append(f.values, v)
+		}
+	}
+	return false
+}
+
+// printedName returns the name printed for the constant with the given name,
+// declared in vspec, after applying the -trimprefix, -trimsuffix, -transform
+// and -linecomment flags.
+func (f *File) printedName(name string, vspec *ast.ValueSpec) string {
+	name = strings.TrimPrefix(name, f.trimPrefix)
+	if trimmed := strings.TrimSuffix(name, f.trimSuffix); trimmed != name {
+		if trimmed == "" {
+			failf("-trimsuffix: the name of %s would be empty", name)
+		}
+		name = trimmed
+	}
+	if f.transform != nil {
+		name = f.transform(name)
+	}
+	if c := vspec.Comment; f.lineComment && c != nil && len(c.List) == 1 && !strings.HasPrefix(c.List[0].Text, AliasMarker) {
+		name = strings.TrimSpace(c.Text())
+	}
+	return name
+}
+
+// checkTrimmed verifies that no two constants with different values have
+// the same name once -trimsuffix has trimmed them.
+func checkTrimmed(values []Value) {
+	seen := make(map[string]*Value)
+	for i := range values {
+		v := &values[i]
+		if w := seen[v.name]; w != nil && w.str != v.str {
+			failf("-trimsuffix: %s and %s would both be named %q", w.originalName, v.originalName, v.name)
+		}
+		seen[v.name] = v
+	}
+}
+
+// Helpers
+
+// qualified returns the name declared in the package of the type, qualified
+// by the package name if the code is written into another package.
+func (g *generator) qualified(name string) string {
+	if g.outputPkg == "" {
+		return name
+	}
+	return g.pkg.name + "." + name
+}
+
+// checkOutputPkg verifies that the helpers for the type, with the given
+// values, can be written into another package, and imports the type's package.
+func (g *generator) checkOutputPkg(typeName string, values []Value) {
+	if g.text || g.json || g.sql || g.gob || g.yaml || g.binary || g.isValid || g.goString || g.formatter || g.slog || g.errorMethod || g.group || g.description || g.localize || g.ordinal || g.iter {
+		failf("-outputpkg: methods of %s must be declared in its own package; use only -parse, -values, -strings and -bounds", typeName)
+	}
+	if !ast.IsExported(typeName) {
+		failf("-outputpkg: %s is not exported", typeName)
+	}
+	if g.values {
+		for _, v := range values {
+			if !ast.IsExported(v.originalName) {
+				failf("-outputpkg: constant %s is not exported", v.originalName)
+			}
+		}
+	}
+	if g.pkg.path == "" {
+		failf("-outputpkg: cannot determine the import path of package %s", g.pkg.name)
+	}
+	g.addImport(g.pkg.path)
+}
+
+// funcName returns the name of a generated function for the type, formed by
+// joining prefix and typeName. It is exported only if the type is.
+func funcName(prefix, typeName string) string {
+	if !ast.IsExported(typeName) {
+		prefix = strings.ToLower(prefix[:1]) + prefix[1:]
+	}
+	return prefix + strings.ToUpper(typeName[:1]) + typeName[1:]
+}
+
+// invalidString returns an expression for the string printed for a value
+// with no name, according to the -invalid and -char flags. The value is that
+// of expr, which has the kind of v. It is not meaningful if String is to
+// panic.
+func (g *generator) invalidString(typeName, expr string, v *Value) string {
+	switch {
+	case g.invalid == "empty":
+		return `""`
+	case g.invalid != "" && !strings.Contains(g.invalid, "%"):
+		return fmt.Sprintf("%q", g.invalid)
+	case g.char != "":
+		return g.quoteChar(expr)
+	case g.noFmt:
+		// The -invalid format is not allowed.
+		return fmt.Sprintf("%q + %s + \")\"", typeName+"(", g.formatNumber(expr, v))
+	}
+	g.addImport("fmt")
+	verb, conv := v.format()
+	if g.invalid == "" {
+		return fmt.Sprintf("fmt.Sprintf(%q, %s)", typeName+"("+verb+")", expr)
+	}
+	// Convert the value so that a %v or %s in the format
+	// doesn't call String recursively.
+	return fmt.Sprintf("fmt.Sprintf(%q, %s(%s))", g.invalid, conv, expr)
+}
+
+// invalidStmt returns the statement by which String handles a value with no
+// name, the value of expr, according to the -invalid flag.
+func (g *generator) invalidStmt(typeName, expr string, v *Value) string {
+	if g.invalid == "panic" && g.noFmt {
+		return fmt.Sprintf("panic(%q + %s)", "invalid "+typeName+" ", g.formatNumber(expr, v))
+	}
+	if g.invalid == "panic" {
+		g.addImport("fmt")
+		verb, _ := v.format()
+		return fmt.Sprintf("panic(fmt.Sprintf(%q, %s))", "invalid "+typeName+" "+verb, expr)
+	}
+	return "return " + g.invalidString(typeName, expr, v)
+}
+
+// formatNumber returns an expression, not using fmt, for the decimal string
+// of the value of expr, which has the kind of v.
+func (g *generator) formatNumber(expr string, v *Value) string {
+	g.addImport("strconv")
+	switch {
+	case v.isFloat:
+		return fmt.Sprintf("strconv.FormatFloat(float64(%s), 'g', -1, %d)", expr, v.bits)
+	case v.signed:
+		return fmt.Sprintf("strconv.FormatInt(int64(%s), 10)", expr)
+	}
+	return fmt.Sprintf("strconv.FormatUint(uint64(%s), 10)", expr)
+}
+
+// invalidError returns an expression for the error reporting that the string
+// expr is not the name of one of the constants.
+func (g *generator) invalidError(typeName, expr string) string {
+	if g.noFmt {
+		g.addImport("errors")
+		g.addImport("strconv")
+		return fmt.Sprintf("errors.New(%q + strconv.Quote(%s))", "invalid "+typeName+" ", expr)
+	}
+	g.addImport("fmt")
+	return fmt.Sprintf("fmt.Errorf(%q, %s)", "invalid "+typeName+" %q", expr)
+}
+
+// invalidValueError returns an expression for the error reporting that the
+// value of expr, which has the kind of v, is not that of one of the constants.
+func (g *generator) invalidValueError(typeName, expr string, v *Value) string {
+	if g.noFmt {
+		g.addImport("errors")
+		return fmt.Sprintf("errors.New(%q + %s)", "invalid "+typeName+" ", g.formatNumber(expr, v))
+	}
+	g.addImport("fmt")
+	verb, _ := v.format()
+	return fmt.Sprintf("fmt.Errorf(%q, %s)", "invalid "+typeName+" "+verb, expr)
+}
+
+// bitSize returns the size in bits of the numeric type t. The sizes of int,
+// uint and uintptr, which depend on the platform, are taken to be 64 bits;
+// no run of constants could fill even a 32-bit type.
+func bitSize(t *types.Basic) uint {
+	switch t.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	}
+	return 64
+}
+
+// coversType reports whether the run holds every value of its unsigned type,
+// in which case every value has a name. The length of such a run, as needed
+// in bounds checks, cannot be represented in the type.
+func coversType(run []Value) bool {
+	v := run[0]
+	return !v.signed && v.bits < 64 && uint64(len(run)) == 1<<v.bits
+}
+
+// usize returns the number of bits of the smallest unsigned integer
+// type that will hold n. Used to create the smallest possible slice of
+// integers to use as indexes into the concatenated strings.
+func usize(n int) int {
+	switch {
+	case n < 1<<8:
+		return 8
+	case n < 1<<16:
+		return 16
+	default:
+		// 2^32 is enough constants for anyone.
+		return 32
+	}
+}
+
+// declareIndexAndNameVars declares the index slices and concatenated names
+// strings representing the runs of values, and, if prefixes is not nil, the
+// table of prefixes and the positions in it for the runs of several values.
+// It returns the tables locating the names of the runs, which, if the types
+// share them, are stored in the shared tables instead, and with -blocks in
+// those of their const blocks.
+func (g *generator) declareIndexAndNameVars(runs [][]Value, typeName string, prefixes *prefixTable) []*nameTable {
+	switch {
+	case g.blocks:
+		return g.declareBlockNames(runs, typeName, prefixes)
+	case g.shared != nil:
+		return g.addSharedNames(runs, typeName, prefixes)
+	}
+	var indexes, names []string
+	tables := make([]*nameTable, len(runs))
+	for i, run := range runs {
+		suffix := fmt.Sprintf("_%d", i)
+		index, name := g.createIndexAndNameDecl(run, typeName, suffix, prefixes)
+		indexes = append(indexes, index)
+		names = append(names, name)
+		tables[i] = runTable(typeName, suffix)
+	}
+	g.Printf("const (\n")
+	for _, name := range names {
+		g.Printf("\t%s\n", name)
+	}
+	g.Printf(")\n\n")
+	g.Printf("var (")
+	for _, index := range indexes {
+		g.Printf("\t%s\n", index)
+	}
+	g.Printf(")\n\n")
+	if prefixes != nil {
+		prefixes.declareRuns(g, runs, typeName)
+	}
+	return tables
+}
+
+// declareIndexAndNameVar is the single-run version of declareIndexAndNameVars
+// that does not share the tables.
+func (g *generator) declareIndexAndNameVar(run []Value, typeName string, prefixes *prefixTable) *nameTable {
+	index, name := g.createIndexAndNameDecl(run, typeName, "", prefixes)
+	g.Printf("const %s\n", name)
+	g.Printf("var %s\n", index)
+	if prefixes != nil {
+		g.Printf("\n")
+		prefixes.declareRuns(g, [][]Value{run}, typeName)
+	}
+	return runTable(typeName, "")
+}
+
+// addSharedNames stores the names of the runs in the shared tables, declaring
+// only the prefixes, if prefixes is not nil, and returns the tables locating
+// them.
+func (g *generator) addSharedNames(runs [][]Value, typeName string, prefixes *prefixTable) []*nameTable {
+	tables := make([]*nameTable, len(runs))
+	for i, run := range runs {
+		tables[i] = g.shared.add(run, prefixes)
+	}
+	if prefixes != nil {
+		prefixes.declareRuns(g, runs, typeName)
+	}
+	return tables
+}
+
+// createIndexAndNameDecl returns the pair of declarations for the run. The caller will add "const" and "var".
+// If prefixes is not nil, the names are stored without their prefixes.
+func (g *generator) createIndexAndNameDecl(run []Value, typeName string, suffix string, prefixes *prefixTable) (string, string) {
+	b := new(bytes.Buffer)
+	indexes := make([]int, len(run))
+	for i := range run {
+		name := run[i].name
+		if prefixes != nil {
+			_, name = prefixes.split(name)
+		}
+		b.WriteString(name)
+		indexes[i] = b.Len()
+	}
+	nameConst := fmt.Sprintf("_%s_name%s = %q", typeName, suffix, b.String())
+	nameLen := b.Len()
+	b.Reset()
+	fmt.Fprintf(b, "_%s_index%s = [...]uint%d{0, ", typeName, suffix, usize(nameLen))
+	for i, v := range indexes {
+		if i > 0 {
+			fmt.Fprintf(b, ", ")
+		}
+		fmt.Fprintf(b, "%d", v)
+	}
+	fmt.Fprintf(b, "}")
+	return b.String(), nameConst
+}
+
+// declareNameVars declares the concatenated names string representing all the values in the runs.
+func (g *generator) declareNameVars(runs [][]Value, typeName string, suffix string) {
+	g.Printf("const _%s_name%s = \"", typeName, suffix)
+	for _, run := range runs {
+		for i := range run {
+			g.Printf("%s", run[i].name)
+		}
+	}
+	g.Printf("\"\n")
+}
+
+// buildOneRun generates the variables and String method for a single run of
+// contiguous values, and returns the table of its names.
+func (g *generator) buildOneRun(runs [][]Value, typeName string, prefixes *prefixTable) []*nameTable {
+	values := runs[0]
+	g.Printf("\n")
+	var t *nameTable
+	if g.shared != nil {
+		t = g.addSharedNames(runs, typeName, prefixes)[0]
+	} else {
+		t = g.declareIndexAndNameVar(values, typeName, prefixes)
+	}
+	// The generated code is simple enough to write as a Printf format.
+	lessThanZero := ""
+	if values[0].signed {
+		lessThanZero = "i < 0 || "
+	}
+	switch {
+	case coversType(values):
+		g.Printf(stringOneRunFull, typeName, lookupName(t, typeName, "", "i", "int(i)+1", prefixes))
+	case values[0].value == 0: // Signed or unsigned, 0 is still 0.
+		g.Printf(stringOneRun, typeName, usize(len(values)), lessThanZero, g.invalidStmt(typeName, "i", &values[0]),
+			lookupName(t, typeName, "", "i", "i+1", prefixes), t.count)
+	case values[0].negative():
+		g.Printf(stringOneRunBelowZero, typeName, values[0].above("i"), g.invalidStmt(typeName, "i", &values[0]),
+			lookupName(t, typeName, "", "n", "n+1", prefixes), t.count)
+	default:
+		offset := values[0].String()
+		g.Printf(stringOneRunWithOffset, typeName, offset, usize(len(values)), lessThanZero,
+			g.invalidStmt(typeName, "i + "+offset, &values[0]), lookupName(t, typeName, "", "i", "i+1", prefixes), t.count)
+	}
+	return []*nameTable{t}
+}
+
+// lookupName returns the expression for the name at position i of the run
+// declared with the suffix, whose names the table locates, given the
+// expression j for the next position. If prefixes is not nil, the name is
+// stored without its prefix, which the expression adds back.
+func lookupName(t *nameTable, typeName, suffix, i, j string, prefixes *prefixTable) string {
+	name := t.lookup(i, j)
+	if prefixes == nil {
+		return name
+	}
+	return fmt.Sprintf("_%[1]s_prefixes[_%[1]s_prefix%[2]s[%[3]s]] + %[4]s", typeName, suffix, i, name)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: name of i; the index is converted to int so that i+1 does not overflow
+const stringOneRunFull = `func (i %[1]s) String() string {
+	return %[2]s
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: size of index element (8 for uint8 etc.)
+//	[3]: less than zero check (for signed types)
+//	[4]: statement for values with no name
+//	[5]: name of i
+//	[6]: number of values
+const stringOneRun = `func (i %[1]s) String() string {
+	if %[3]si >= %[1]s(%[6]s) {
+		%[4]s
+	}
+	return %[5]s
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: lowest defined value for type, as a string
+//	[3]: size of index element (8 for uint8 etc.)
+//	[4]: less than zero check (for signed types)
+//	[5]: statement for values with no name
+//	[6]: name of i, once the lowest value is subtracted
+//	[7]: number of values
+/*
+ */
+const stringOneRunWithOffset = `func (i %[1]s) String() string {
+	i -= %[2]s
+	if %[4]si >= %[1]s(%[7]s) {
+		%[5]s
+	}
+	return %[6]s
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: position of i in the run, as an expression of type uint64
+//	[3]: statement for values with no name
+//	[4]: name of the value at position n
+//	[5]: number of values
+const stringOneRunBelowZero = `func (i %[1]s) String() string {
+	n := %[2]s
+	if n >= uint64(%[5]s) {
+		%[3]s
+	}
+	return %[4]s
+}
+`
+
+// buildMultipleRuns generates the variables and String method for multiple runs of contiguous values,
+// and returns the tables of their names. For this pattern, a single Printf format won't do.
+func (g *generator) buildMultipleRuns(runs [][]Value, typeName string, prefixes *prefixTable) []*nameTable {
+	g.Printf("\n")
+	tables := g.declareIndexAndNameVars(runs, typeName, prefixes)
+	g.Printf("func (i %s) String() string {\n", typeName)
+	g.Printf("\tswitch {\n")
+	for i, values := range runs {
+		suffix := fmt.Sprintf("_%d", i)
+		if len(values) == 1 {
+			g.Printf("\tcase i == %s:\n", &values[0])
+			k, rest := prefixes.split(values[0].name)
+			if k > 0 {
+				g.Printf("\t\treturn _%s_prefixes[%d] + %s\n", typeName, k, tables[i].whole(len(rest)))
+				continue
+			}
+			g.Printf("\t\treturn %s\n", tables[i].whole(len(rest)))
+			continue
+		}
+		g.Printf("\tcase %s <= i && i <= %s:\n", &values[0], &values[len(values)-1])
+		if values[0].negative() {
+			g.Printf("\t\tn := %s\n", values[0].above("i"))
+			g.Printf("\t\treturn %s\n", lookupName(tables[i], typeName, suffix, "n", "n+1", prefixes))
+			continue
+		}
+		if values[0].value != 0 {
+			g.Printf("\t\ti -= %s\n", &values[0])
+		}
+		g.Printf("\t\treturn %s\n", lookupName(tables[i], typeName, suffix, "i", "i+1", prefixes))
+	}
+	g.Printf("\tdefault:\n")
+	g.Printf("\t\t%s\n", g.invalidStmt(typeName, "i", &runs[0][0]))
+	g.Printf("\t}\n")
+	g.Printf("}\n")
+	return tables
+}
+
+// buildMap handles the case where the space is so sparse a map is a reasonable fallback.
+// It's a rare situation but has simple code.
+func (g *generator) buildMap(runs [][]Value, typeName string) {
+	g.Printf("\n")
+	g.declareNameVars(runs, typeName, "")
+	g.Printf("\nvar _%s_map = map[%s]string{\n", typeName, typeName)
+	n := 0
+	for _, values := range runs {
+		for _, value := range values {
+			g.Printf("\t%s: _%s_name[%d:%d],\n", &value, typeName, n, n+len(value.name))
+			n += len(value.name)
+		}
+	}
+	g.Printf("}\n\n")
+	g.Printf(stringMap, typeName, g.invalidStmt(typeName, "i", &runs[0][0]))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: statement for values with no name
+const stringMap = `func (i %[1]s) String() string {
+	if str, ok := _%[1]s_map[i]; ok {
+		return str
+	}
+	%[2]s
+}
+`
+
+// nameExprs returns, for each value in the runs, an expression yielding its
+// name by slicing the constants declared for String, so that no string data
+// is duplicated. If tables is not nil, they locate the names of each run, as
+// in buildOneRun and buildMultipleRuns; otherwise the names are stored in
+// order in one constant. If prefixes is not nil, the constants hold the names
+// without their prefixes, which the expressions add back.
+func nameExprs(runs [][]Value, typeName string, tables []*nameTable, prefixes *prefixTable) map[uint64]string {
+	exprs := make(map[uint64]string)
+	t := runTable(typeName, "")
+	n := 0
+	for i, values := range runs {
+		if tables != nil {
+			t = tables[i]
+			n = 0
+		}
+		for _, value := range values {
+			k, rest := prefixes.split(value.name)
+			expr := t.slice(n, n+len(rest))
+			if k > 0 {
+				expr = fmt.Sprintf("_%s_prefixes[%d] + %s", typeName, k, expr)
+			}
+			exprs[value.value] = expr
+			n += len(rest)
+		}
+	}
+	return exprs
+}
+
+// buildValueMap generates the map from names to values used to invert the
+// String method. It maps the aliases of the constants, all of them in
+// declaration order, too.
+func (g *generator) buildValueMap(runs [][]Value, all []Value, typeName string, names map[uint64]string) {
+	g.Printf("\nvar _%s_value = map[string]%s{\n", typeName, g.qualified(typeName))
+	seen := make(map[string]bool)
+	for _, values := range runs {
+		for _, value := range values {
+			g.Printf("\t%s: %s,\n", names[value.value], &value)
+			seen[value.name] = true
+		}
+	}
+	for i := range all {
+		for _, alias := range all[i].aliases {
+			if !seen[alias] {
+				g.Printf("\t%q: %s,\n", alias, &all[i])
+				seen[alias] = true
+			}
+		}
+	}
+	g.Printf("}\n")
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: name of the Parse function
+//	[3]: type name, qualified if in another package
+//	[4]: error for an invalid s
+//	[5]: statements looking s up regardless of case, if any
+//	[6]: the end of the doc comment, for aliases and case
+const parseFunc = `
+// %[2]s returns the %[1]s whose String method returns s%[6]s.
+func %[2]s(s string) (%[3]s, error) {
+	if i, ok := _%[1]s_value[s]; ok {
+		return i, nil
+	}
+%[5]s	return 0, %[4]s
+}
+`
+
+// buildText generates the MarshalText and UnmarshalText methods. The value
+// v gives the kind of the type.
+func (g *generator) buildText(typeName string, v *Value) {
+	text := "text" // fmt quotes a []byte as it does a string.
+	if g.noFmt {
+		text = "string(text)"
+	}
+	invalid := ""
+	switch {
+	case g.textInvalid == "":
+	case g.textInvalid == "error":
+		invalid = fmt.Sprintf("\tif !i.IsValid() {\n\t\treturn nil, %s\n\t}\n", g.invalidValueError(typeName, "i", v))
+	case strings.HasPrefix(g.textInvalid, "name:"):
+		invalid = fmt.Sprintf("\tif !i.IsValid() {\n\t\treturn []byte(%q), nil\n\t}\n", strings.TrimPrefix(g.textInvalid, "name:"))
+	default:
+		failf("unknown -textinvalid policy %q", g.textInvalid)
+	}
+	g.Printf(textMethods, typeName, g.invalidError(typeName, text), invalid)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: error for an invalid text
+//	[3]: statement handling values with no name, if any
+const textMethods = `
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i %[1]s) MarshalText() ([]byte, error) {
+%[3]s	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *%[1]s) UnmarshalText(text []byte) error {
+	v, ok := _%[1]s_value[string(text)]
+	if !ok {
+		return %[2]s
+	}
+	*i = v
+	return nil
+}
+`
+
+// buildJSON generates the MarshalJSON and UnmarshalJSON methods.
+func (g *generator) buildJSON(runs [][]Value, typeName string) {
+	g.addImport("encoding/json")
+	g.addImport("fmt")
+	g.addImport("reflect")
+	intType := "uint64"
+	if runs[0][0].signed {
+		intType = "int64"
+	}
+	g.Printf(jsonMethods, typeName, intType)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: integer type wide enough to hold any value of the type
+const jsonMethods = `
+// MarshalJSON implements the json.Marshaler interface.
+func (i %[1]s) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts the name
+// of a %[1]s constant or, for compatibility with numeric encodings, its value.
+// Other names and numbers are rejected with a *json.UnmarshalTypeError.
+func (i *%[1]s) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if v, ok := _%[1]s_value[s]; ok {
+			*i = v
+			return nil
+		}
+		return &json.UnmarshalTypeError{Value: fmt.Sprintf("string %%q", s), Type: reflect.TypeOf(*i)}
+	}
+	var n %[2]s
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	if v := %[1]s(n); %[2]s(v) == n && v.IsValid() {
+		*i = v
+		return nil
+	}
+	return &json.UnmarshalTypeError{Value: "number " + string(data), Type: reflect.TypeOf(*i)}
+}
+`
+
+// Argument to format is the type name.
+const sqlMethods = `
+// Value implements the driver.Valuer interface, storing the name of i.
+func (i %[1]s) Value() (driver.Value, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("invalid %[1]s %%d", i)
+	}
+	return i.String(), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting the name of a %[1]s
+// as a string or []byte. A NULL leaves i unchanged.
+func (i *%[1]s) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %%T into %[1]s", value)
+	}
+	v, ok := _%[1]s_value[s]
+	if !ok {
+		return fmt.Errorf("invalid %[1]s %%q", s)
+	}
+	*i = v
+	return nil
+}
+`
+
+// sameNames reports whether the values print as the names of the constants,
+// so that GoString can use the names of String.
+func sameNames(values []Value) bool {
+	for _, v := range values {
+		if v.isString || v.name != v.originalName {
+			return false
+		}
+	}
+	return true
+}
+
+// buildGoString generates the GoString method, which prints the constants
+// qualified by the package name. If String prints the constants' names the
+// method uses it, and IsValid; otherwise it has a map of its own.
+func (g *generator) buildGoString(values []Value, typeName string) {
+	prefix := g.pkg.name + "." + typeName + "("
+	var fallback string
+	switch {
+	case g.noFmt && values[0].isString:
+		g.addImport("strconv")
+		fallback = fmt.Sprintf("%q + strconv.Quote(string(i)) + \")\"", prefix)
+	case g.noFmt:
+		fallback = fmt.Sprintf("%q + %s + \")\"", prefix, g.formatNumber("i", &values[0]))
+	case values[0].isString:
+		// Only %q would call String.
+		g.addImport("fmt")
+		fallback = fmt.Sprintf("fmt.Sprintf(%q, string(i))", prefix+"%q)")
+	default:
+		g.addImport("fmt")
+		verb, _ := values[0].format()
+		fallback = fmt.Sprintf("fmt.Sprintf(%q, i)", prefix+verb+")")
+	}
+	if sameNames(values) {
+		g.Printf(goStringFunc, typeName, strconv.Quote(g.pkg.name+"."), fallback)
+		return
+	}
+	// Emit the map in order of value, as the other tables are.
+	sorted := append([]Value(nil), values...)
+	if values[0].isString {
+		sort.Sort(byString(sorted))
+	} else {
+		sort.Sort(byValue(sorted))
+	}
+	g.Printf("\nvar _%s_gonames = map[%s]string{\n", typeName, typeName)
+	for _, v := range sorted {
+		g.Printf("\t%s: %q,\n", &v, g.pkg.name+"."+v.originalName)
+	}
+	g.Printf("}\n")
+	g.Printf(goStringMap, typeName, fallback)
+}
+
+// buildFormat generates the Format method. The value v gives the kind of the
+// type.
+func (g *generator) buildFormat(typeName string, v *Value) {
+	g.addImport("fmt")
+	_, conv := v.format()
+	g.Printf(formatMethod, typeName, conv)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: basic type to which i is converted to print the number
+const formatMethod = `
+// Format implements the fmt.Formatter interface, so that %%v and %%s print the
+// name of i, %%q the name quoted and %%#v what GoString returns, while the
+// other verbs, such as %%d and %%x, print the number, each with the width,
+// precision and flags given.
+func (i %[1]s) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, fmt.FormatString(f, 's'), i.GoString())
+	case verb == 'v' || verb == 's' || verb == 'q':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), i.String())
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), %[2]s(i))
+	}
+}
+`
+
+// buildSlog generates the LogValue method. The value v gives the kind of the
+// type.
+func (g *generator) buildSlog(typeName string, v *Value) {
+	g.addImport("log/slog")
+	code := `slog.Uint64("code", uint64(i))`
+	switch {
+	case v.isFloat:
+		code = `slog.Float64("code", float64(i))`
+	case v.signed:
+		code = `slog.Int64("code", int64(i))`
+	}
+	g.Printf(slogMethod, typeName, code)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: attribute holding the number
+const slogMethod = `
+// LogValue implements the slog.LogValuer interface, logging the name of i or,
+// if it has none, a group of what String prints and the number.
+func (i %[1]s) LogValue() slog.Value {
+	if !i.IsValid() {
+		return slog.GroupValue(slog.String("name", i.String()), %[2]s)
+	}
+	return slog.StringValue(i.String())
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: quoted package qualifier
+//	[3]: expression for values with no name
+const goStringFunc = `
+// GoString implements the fmt.GoStringer interface, so that %%#v prints i as Go syntax.
+func (i %[1]s) GoString() string {
+	if i.IsValid() {
+		return %[2]s + i.String()
+	}
+	return %[3]s
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: expression for values with no name
+const goStringMap = `
+// GoString implements the fmt.GoStringer interface, so that %%#v prints i as Go syntax.
+func (i %[1]s) GoString() string {
+	if s, ok := _%[1]s_gonames[i]; ok {
+		return s
+	}
+	return %[2]s
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: error for an invalid i
+//	[3]: error for invalid data
+const gobMethods = `
+// GobEncode implements the gob.GobEncoder interface, encoding i by name so
+// that encoded values survive renumbering of the constants.
+func (i %[1]s) GobEncode() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, %[2]s
+	}
+	return []byte(i.String()), nil
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (i *%[1]s) GobDecode(data []byte) error {
+	v, ok := _%[1]s_value[string(data)]
+	if !ok {
+		return %[3]s
+	}
+	*i = v
+	return nil
+}
+`
+
+// buildBinary generates the MarshalBinary and UnmarshalBinary methods, which
+// encode values of the type of v, big-endian, in the bytes of the type; int
+// and uint, which vary in size, take 8.
+func (g *generator) buildBinary(v *Value, typeName string) {
+	size := v.bits / 8
+	// The expression for the bits of i, and the value whose bits are in x.
+	bits, value := fmt.Sprintf("uint%d(i)", v.bits), "x"
+	if v.isFloat {
+		g.addImport("math")
+		bits = fmt.Sprintf("math.Float%dbits(float%d(i))", v.bits, v.bits)
+		value = fmt.Sprintf("math.Float%dfrombits(x)", v.bits)
+	}
+	var put, get string
+	if size == 1 {
+		put = fmt.Sprintf("b := []byte{%s}", bits)
+		get = "x := data[0]"
+	} else {
+		g.addImport("encoding/binary")
+		put = fmt.Sprintf("b := make([]byte, %d)\n\tbinary.BigEndian.PutUint%d(b, %s)", size, v.bits, bits)
+		get = fmt.Sprintf("x := binary.BigEndian.Uint%d(data)", v.bits)
+	}
+	g.addImport("errors")
+	g.Printf(binaryMethods, typeName, size, put, get, value,
+		g.invalidValueError(typeName, "i", v), g.invalidValueError(typeName, "v", v))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: size of the encoding in bytes
+//	[3]: statement declaring b, the encoding of i
+//	[4]: statement declaring x, the bits decoded from data
+//	[5]: expression for the value whose bits are x
+//	[6]: error for an invalid i
+//	[7]: error for an invalid v
+const binaryMethods = `
+// MarshalBinary implements the encoding.BinaryMarshaler interface, encoding
+// i in %[2]d bytes, big-endian.
+func (i %[1]s) MarshalBinary() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, %[6]s
+	}
+	%[3]s
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface,
+// accepting only the values of the %[1]s constants.
+func (i *%[1]s) UnmarshalBinary(data []byte) error {
+	if len(data) != %[2]d {
+		return errors.New("invalid %[1]s encoding: length is not %[2]d")
+	}
+	%[4]s
+	v := %[1]s(%[5]s)
+	if !v.IsValid() {
+		return %[7]s
+	}
+	*i = v
+	return nil
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: error for an invalid i
+//	[3]: error for an invalid s
+const yamlMethods = `
+// MarshalYAML implements the yaml.Marshaler interface, encoding i by name.
+func (i %[1]s) MarshalYAML() (interface{}, error) {
+	if !i.IsValid() {
+		return nil, %[2]s
+	}
+	return i.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, accepting the
+// name of a %[1]s.
+func (i *%[1]s) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, ok := _%[1]s_value[s]
+	if !ok {
+		return %[3]s
+	}
+	*i = v
+	return nil
+}
+`
+
+// buildProto generates the T_name and T_value maps of protocol buffer enums,
+// as generated by protoc-gen-go, for the values in declaration order. As
+// there, T_value holds every constant, and T_name the first of each value.
+func (g *generator) buildProto(values []Value, typeName string) {
+	for _, v := range values {
+		if v.isFloat || v.signed && int64(v.value) != int64(int32(v.value)) || !v.signed && v.value > math.MaxInt32 {
+			failf("-proto: value of %s is not an int32: %s", v.originalName, &v)
+		}
+	}
+	g.Printf("\nvar %s_name = map[int32]string{\n", typeName)
+	for _, v := range unique(values) {
+		g.Printf("\t%s: %q,\n", &v, v.name)
+	}
+	g.Printf("}\n")
+	g.Printf("\nvar %s_value = map[string]int32{\n", typeName)
+	for _, v := range values {
+		g.Printf("\t%q: %s,\n", v.name, &v)
+	}
+	g.Printf("}\n")
+}
+
+// buildValueOf generates the exported map from names to values and the
+// function looking them up. The values, in declaration order, include the
+// duplicates, which are named by their own names rather than those String
+// prints, given by names.
+func (g *generator) buildValueOf(values []Value, typeName string, names map[uint64]string) {
+	g.Printf("\nvar _%s_valueOf = map[string]%s{\n", typeName, g.qualified(typeName))
+	seen := make(map[uint64]bool)
+	seenName := make(map[string]bool)
+	for _, v := range values {
+		if seenName[v.name] {
+			continue
+		}
+		seenName[v.name] = true
+		if !seen[v.value] {
+			seen[v.value] = true
+			g.Printf("\t%s: %s,\n", names[v.value], &v)
+			continue
+		}
+		g.Printf("\t%q: %s,\n", v.name, &v)
+	}
+	g.Printf("}\n")
+	g.Printf(valueOfFunc, typeName, typeName+"ValueOf", g.qualified(typeName))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: name of the ValueOf function
+//	[3]: type name, qualified if in another package
+const valueOfFunc = `
+// %[2]s returns the %[1]s constant with the given name, either
+// that which String returns or, for an alias of another constant, its own,
+// and whether there is one.
+func %[2]s(name string) (%[3]s, bool) {
+	i, ok := _%[1]s_valueOf[name]
+	return i, ok
+}
+`
+
+// buildValues generates the function returning the values in declaration order.
+func (g *generator) buildValues(values []Value, typeName string) {
+	g.Printf("\nvar _%s_values = []%s{", typeName, g.qualified(typeName))
+	for i, value := range values {
+		if i > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("%s", g.qualified(value.originalName))
+	}
+	g.Printf("}\n")
+	g.Printf(valuesFunc, typeName, g.qualified(typeName))
+}
+
+// buildBounds generates the constants for the least and greatest of the
+// values, which are sorted in runs, and their number.
+func (g *generator) buildBounds(runs [][]Value, typeName string) {
+	if g.outputPkg == "" {
+		for _, name := range []string{typeName + "Min", typeName + "Max", typeName + "Count"} {
+			g.checkUndeclared("-bounds", name)
+		}
+	}
+	last := runs[len(runs)-1]
+	n := 0
+	for _, run := range runs {
+		n += len(run)
+	}
+	g.Printf(boundsConsts, typeName, g.qualified(typeName), &runs[0][0], &last[len(last)-1], n)
+}
+
+// checkUndeclared fails if the package of the type declares name, which the
+// flag would have the generated code declare, other than in a file generated
+// by stringer, whose declarations the generated code replaces.
+func (g *generator) checkUndeclared(flag, name string) {
+	obj := g.pkg.typesPkg.Scope().Lookup(name)
+	if obj == nil || g.pkg.generated(obj.Pos()) {
+		return
+	}
+	pos := g.pkg.fset.Position(obj.Pos())
+	failAt(OtherError, pos, "%s: %s would declare %s, which is already declared", pos, flag, name)
+}
+
+// generated reports whether pos is in a file of the package that stringer
+// generated, as its first line records.
+func (pkg *Package) generated(pos token.Pos) bool {
+	file := pkg.fset.File(pos)
+	for _, f := range pkg.files {
+		if pkg.fset.File(f.file.Pos()) != file {
+			continue
+		}
+		for _, c := range f.file.Comments {
+			if c.Pos() > f.file.Package {
+				break
+			}
+			for _, line := range c.List {
+				if strings.HasPrefix(line.Text, `// Code generated by "stringer `) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// buildDescription generates the Description method, which returns the doc
+// comments of the values, given in declaration order. A value takes the first
+// comment of the constants that have it.
+func (g *generator) buildDescription(values []Value, typeName string) {
+	g.Printf("\nvar _%s_description = map[%s]string{\n", typeName, typeName)
+	seen := make(map[uint64]bool)
+	for _, v := range values {
+		if v.doc != "" && !seen[v.value] {
+			seen[v.value] = true
+			g.Printf("\t%s: %q,\n", &v, v.doc)
+		}
+	}
+	g.Printf("}\n")
+	g.Printf(descriptionFunc, typeName)
+}
+
+// Argument to format is the type name.
+const descriptionFunc = `
+// Description returns the doc comment of the %[1]s constant with the value of
+// i, on one line, or the empty string if there is none.
+func (i %[1]s) Description() string {
+	return _%[1]s_description[i]
+}
+`
+
+// buildIter generates the Next and Prev methods, which search a sorted array of
+// the values, given in runs, and the constants for the first and last values.
+func (g *generator) buildIter(runs [][]Value, typeName string) {
+	g.addImport("sort")
+	last := runs[len(runs)-1]
+	g.Printf("\nvar _%s_sorted = [...]%s{", typeName, typeName)
+	for i, values := range runs {
+		for j := range values {
+			if i > 0 || j > 0 {
+				g.Printf(", ")
+			}
+			g.Printf("%s", &values[j])
+		}
+	}
+	g.Printf("}\n")
+	g.Printf(iterMethods, typeName, funcName("First", typeName), funcName("Last", typeName), &runs[0][0], &last[len(last)-1])
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: name of the first value
+//	[3]: name of the last value
+//	[4]: least value
+//	[5]: greatest value
+const iterMethods = `
+// %[2]s and %[3]s are the least and greatest values of the %[1]s constants,
+// so that a loop such as
+//
+//	for v, ok := %[2]s, true; ok; v, ok = v.Next() {
+//
+// visits each value once, in increasing order, and no other.
+const (
+	%[2]s %[1]s = %[4]s
+	%[3]s %[1]s = %[5]s
+)
+
+// Next returns the least value of the %[1]s constants that is greater than i,
+// and true; or, if there is none, i and false.
+func (i %[1]s) Next() (%[1]s, bool) {
+	n := sort.Search(len(_%[1]s_sorted), func(j int) bool { return _%[1]s_sorted[j] > i })
+	if n == len(_%[1]s_sorted) {
+		return i, false
+	}
+	return _%[1]s_sorted[n], true
+}
+
+// Prev returns the greatest value of the %[1]s constants that is less than i,
+// and true; or, if there is none, i and false.
+func (i %[1]s) Prev() (%[1]s, bool) {
+	n := sort.Search(len(_%[1]s_sorted), func(j int) bool { return _%[1]s_sorted[j] >= i })
+	if n == 0 {
+		return i, false
+	}
+	return _%[1]s_sorted[n-1], true
+}
+`
+
+// buildOrdinal generates the Ordinal method, which returns the position of a
+// value among those declared, given in declaration order. If they are also in
+// increasing order and String switches on the runs, so does Ordinal, counting
+// the values of the earlier runs; otherwise it uses a map.
+func (g *generator) buildOrdinal(declared []Value, runs [][]Value, typeName string, useRuns bool) {
+	g.Printf(ordinalDoc, typeName)
+	if useRuns && sort.IsSorted(byValue(declared)) {
+		g.Printf("func (i %s) Ordinal() int {\n", typeName)
+		g.Printf("\tswitch {\n")
+		n := 0
+		for _, values := range runs {
+			if len(values) == 1 {
+				g.Printf("\tcase i == %s:\n", &values[0])
+				g.Printf("\t\treturn %d\n", n)
+				n++
+				continue
+			}
+			g.Printf("\tcase %s <= i && i <= %s:\n", &values[0], &values[len(values)-1])
+			pos := "int(i)"
+			switch {
+			case values[0].negative():
+				pos = fmt.Sprintf("int(%s)", values[0].above("i"))
+			case values[0].value != 0:
+				pos = fmt.Sprintf("int(i - %s)", &values[0])
+			}
+			if n > 0 {
+				pos = fmt.Sprintf("%s + %d", pos, n)
+			}
+			g.Printf("\t\treturn %s\n", pos)
+			n += len(values)
+		}
+		g.Printf("\t}\n")
+		g.Printf("\treturn -1\n")
+		g.Printf("}\n")
+		return
+	}
+	g.Printf("func (i %s) Ordinal() int {\n", typeName)
+	g.Printf("\tif n, ok := _%s_ordinal[i]; ok {\n", typeName)
+	g.Printf("\t\treturn n\n")
+	g.Printf("\t}\n")
+	g.Printf("\treturn -1\n")
+	g.Printf("}\n")
+	g.Printf("\nvar _%s_ordinal = map[%s]int{\n", typeName, typeName)
+	for n, v := range declared {
+		g.Printf("\t%s: %d,\n", &v, n)
+	}
+	g.Printf("}\n")
+}
+
+// Argument to format is the type name.
+const ordinalDoc = `
+// Ordinal returns the position of i, from 0, among the values of the %[1]s
+// constants in the order they are declared, or -1 if i is not one of them.
+// Constants with the same value as an earlier one are not counted.
+`
+
+// buildLocalize generates the MessageKey and Localized methods and the hook
+// through which Localized translates the names of the values, which are in
+// declaration order without duplicates.
+func (g *generator) buildLocalize(values []Value, typeName string) {
+	g.Printf("\nvar _%s_keys = map[%s]string{\n", typeName, typeName)
+	for _, v := range values {
+		g.Printf("\t%s: %q,\n", &v, typeName+"."+v.originalName)
+	}
+	g.Printf("}\n")
+	g.Printf(localizeMethods, typeName, funcName("Localize", typeName))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: name of the hook
+const localizeMethods = `
+// %[2]s, if set, returns the translation into the language lang, a BCP 47
+// tag such as "fr-CA", of the message with the given key, which names a value
+// of %[1]s, and reports whether there is one. It may, for instance, look the
+// key up in a golang.org/x/text/message catalog.
+var %[2]s func(lang, key string) (string, bool)
+
+// MessageKey returns the key under which the name of i is translated, which
+// is that of the %[1]s constant qualified by the type, as in %[1]s.Name, or
+// the empty string if i is not the value of a constant.
+func (i %[1]s) MessageKey() string {
+	return _%[1]s_keys[i]
+}
+
+// Localized returns the name of i translated into the language lang by
+// %[2]s or, if there is no translation, the one String returns.
+func (i %[1]s) Localized(lang string) string {
+	if key := i.MessageKey(); key != "" && %[2]s != nil {
+		if s, ok := %[2]s(lang, key); ok {
+			return s
+		}
+	}
+	return i.String()
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: type name, qualified if in another package
+//	[3]: least value
+//	[4]: greatest value
+//	[5]: number of distinct values
+const boundsConsts = `
+// %[1]sMin and %[1]sMax are the least and greatest values of the %[1]s
+// constants, and %[1]sCount is the number of distinct values.
+const (
+	%[1]sMin %[2]s = %[3]s
+	%[1]sMax %[2]s = %[4]s
+	%[1]sCount = %[5]d
+)
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: type name, qualified if in another package
+const valuesFunc = `
+// %[1]sValues returns the values of the %[1]s constants, in the order they
+// are declared. Constants with the same value as an earlier one are omitted.
+func %[1]sValues() []%[2]s {
+	return append([]%[2]s(nil), _%[1]s_values...)
+}
+`
+
+// buildStrings generates the function returning the names of the values in
+// declaration order.
+func (g *generator) buildStrings(values []Value, typeName string, names map[uint64]string) {
+	g.Printf("\nvar _%s_strings = []string{", typeName)
+	for i, value := range values {
+		if i > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("%s", names[value.value])
+	}
+	g.Printf("}\n")
+	g.Printf(stringsFunc, typeName)
+}
+
+// Argument to format is the type name.
+const stringsFunc = `
+// %[1]sStrings returns the names of the %[1]s constants, in the order they
+// are declared and so aligned with %[1]sValues.
+func %[1]sStrings() []string {
+	return append([]string(nil), _%[1]s_strings...)
+}
+`
+
+// buildOneRunIsValid generates the IsValid method for a single run of contiguous values,
+// whose names the table locates.
+func (g *generator) buildOneRunIsValid(runs [][]Value, typeName string, t *nameTable) {
+	values := runs[0]
+	g.Printf("\n// IsValid reports whether i is the value of one of the %s constants.\n", typeName)
+	g.Printf("func (i %s) IsValid() bool {\n", typeName)
+	if coversType(values) {
+		g.Printf("\treturn true\n")
+		g.Printf("}\n")
+		return
+	}
+	if values[0].negative() {
+		g.Printf("\treturn %s < uint64(%s)\n", values[0].above("i"), t.count)
+		g.Printf("}\n")
+		return
+	}
+	if values[0].value != 0 {
+		g.Printf("\ti -= %s\n", &values[0])
+	}
+	greaterThanZero := ""
+	if values[0].signed {
+		greaterThanZero = "0 <= i && "
+	}
+	g.Printf("\treturn %si < %s(%s)\n", greaterThanZero, typeName, t.count)
+	g.Printf("}\n")
+}
+
+// buildMultipleRunsIsValid generates the IsValid method for multiple runs of contiguous values.
+func (g *generator) buildMultipleRunsIsValid(runs [][]Value, typeName string) {
+	g.Printf("\n// IsValid reports whether i is the value of one of the %s constants.\n", typeName)
+	g.Printf("func (i %s) IsValid() bool {\n", typeName)
+	g.Printf("\treturn ")
+	for i, values := range runs {
+		if i > 0 {
+			g.Printf(" ||\n\t\t")
+		}
+		if len(values) == 1 {
+			g.Printf("i == %s", &values[0])
+			continue
+		}
+		g.Printf("%s <= i && i <= %s", &values[0], &values[len(values)-1])
+	}
+	g.Printf("\n}\n")
+}
+
+// buildBinarySearch handles any values by searching a sorted array of them.
+// Unlike a map, the tables need no initialization when the program starts.
+func (g *generator) buildBinarySearch(runs [][]Value, typeName string) {
+	var values []Value
+	for _, run := range runs {
+		values = append(values, run...)
+	}
+	g.Printf("\n")
+	g.declareIndexAndNameVar(values, typeName, nil)
+	g.Printf("\nvar _%s_keys = [...]%s{", typeName, typeName)
+	for i := range values {
+		if i > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("%s", &values[i])
+	}
+	g.Printf("}\n")
+	g.Printf(stringBinarySearch, typeName, g.invalidStmt(typeName, "i", &values[0]))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: statement for values with no name
+const stringBinarySearch = `
+// _%[1]s_lookup returns the index of i in _%[1]s_keys, or -1.
+func _%[1]s_lookup(i %[1]s) int {
+	// The loop runs a fixed number of times, and its body can be compiled
+	// to a conditional move rather than a branch.
+	j, n := 0, len(_%[1]s_keys)
+	for n > 1 {
+		half := n / 2
+		if _%[1]s_keys[j+half] <= i {
+			j += half
+		}
+		n -= half
+	}
+	if _%[1]s_keys[j] != i {
+		return -1
+	}
+	return j
+}
+
+func (i %[1]s) String() string {
+	if j := _%[1]s_lookup(i); j >= 0 {
+		return _%[1]s_name[_%[1]s_index[j]:_%[1]s_index[j+1]]
+	}
+	%[2]s
+}
+`
+
+// Argument to format is the type name.
+const isValidBinarySearch = `
+// IsValid reports whether i is the value of one of the %[1]s constants.
+func (i %[1]s) IsValid() bool {
+	return _%[1]s_lookup(i) >= 0
+}
+`
+
+// singleBitValues reports whether each of the values in the runs is positive
+// and has a single bit set.
+func singleBitValues(runs [][]Value) bool {
+	for _, values := range runs {
+		for _, v := range values {
+			if v.isFloat || v.value == 0 || v.value&(v.value-1) != 0 || v.signed && int64(v.value) < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// buildBits generates the variables and String method for values that are
+// powers of two, whose names are found in a table indexed by the number of
+// the bit set.
+func (g *generator) buildBits(runs [][]Value, typeName string) {
+	g.addImport("math/bits")
+	g.Printf("\n")
+	g.declareNameVars(runs, typeName, "")
+	var mask uint64
+	indexes := []int{0}
+	n := 0
+	for _, values := range runs {
+		for _, v := range values {
+			mask |= v.value
+			// Bits with no name have an empty one.
+			for uint64(1)<<uint(len(indexes)-1) < v.value {
+				indexes = append(indexes, n)
+			}
+			n += len(v.name)
+			indexes = append(indexes, n)
+		}
+	}
+	g.Printf("\nconst _%s_mask = %#x\n\n", typeName, mask)
+	g.Printf("var _%s_index = [...]uint%d{", typeName, usize(n))
+	for i, index := range indexes {
+		if i > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("%d", index)
+	}
+	g.Printf("}\n\n")
+	g.Printf(stringBits, typeName, g.invalidStmt(typeName, "i", &runs[0][0]))
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: statement for values with no name
+const stringBits = `func (i %[1]s) String() string {
+	if i&(i-1) != 0 || i&_%[1]s_mask == 0 {
+		%[2]s
+	}
+	n := bits.TrailingZeros64(uint64(i))
+	return _%[1]s_name[_%[1]s_index[n]:_%[1]s_index[n+1]]
+}
+`
+
+// Argument to format is the type name.
+const isValidBits = `
+// IsValid reports whether i is the value of one of the %[1]s constants.
+func (i %[1]s) IsValid() bool {
+	return i&(i-1) == 0 && i&_%[1]s_mask != 0
+}
+`
+
+// Argument to format is the type name.
+const isValidMap = `
+// IsValid reports whether i is the value of one of the %[1]s constants.
+func (i %[1]s) IsValid() bool {
+	_, ok := _%[1]s_map[i]
+	return ok
+}
+`
+
+// buildFlags generates the variables and String method for bit flags.
+// The runs hold the values with a single bit set, and perhaps zero.
+func (g *generator) buildFlags(runs [][]Value, typeName string) {
+	g.Printf("\n")
+	g.declareNameVars(runs, typeName, "")
+	g.Printf("\nvar _%s_flags = [...]struct {\n", typeName)
+	g.Printf("\tflag %s\n", typeName)
+	g.Printf("\tname string\n")
+	g.Printf("}{\n")
+	first := &runs[0][0]
+	zero := g.invalidStmt(typeName, "i", first)
+	if g.invalid == "" {
+		zero = fmt.Sprintf("return %q", typeName+"(0)")
+	}
+	n := 0
+	for _, values := range runs {
+		for _, value := range values {
+			name := fmt.Sprintf("_%s_name[%d:%d]", typeName, n, n+len(value.name))
+			n += len(value.name)
+			if value.value == 0 {
+				zero = "return " + name
+				continue
+			}
+			g.Printf("\t{%s, %s},\n", &value, name)
+		}
+	}
+	g.Printf("}\n\n")
+	// Bits with no name are shown with the fallback, unless it is empty.
+	var rest string
+	switch g.invalid {
+	case "empty":
+		rest = "if s == \"\" {\n\t\treturn s\n\t}\n\t"
+	case "panic":
+		rest = fmt.Sprintf("if i != 0 {\n\t\t%s\n\t}\n\t", g.invalidStmt(typeName, "i", first))
+	default:
+		rest = fmt.Sprintf("if i != 0 {\n\t\ts += \"|\" + %s\n\t}\n\t", g.invalidString(typeName, "i", first))
+	}
+	g.Printf(stringFlags, typeName, zero, rest)
+}
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: statement returning the string for the zero value
+//	[3]: statement handling the bits with no name
+const stringFlags = `func (i %[1]s) String() string {
+	if i == 0 {
+		%[2]s
+	}
+	var s string
+	for _, f := range _%[1]s_flags {
+		if i&f.flag != 0 {
+			s += "|" + f.name
+			i &^= f.flag
+		}
+	}
+	%[3]sreturn s[1:]
+}
+`
+
+// Argument to format is the type name.
+const isValidFlags = `
+// IsValid reports whether each bit set in i is one of the %[1]s constants.
+func (i %[1]s) IsValid() bool {
+	for _, f := range _%[1]s_flags {
+		i &^= f.flag
+	}
+	return i == 0
+}
+`
@@ -0,0 +1,106 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.5
+
+// This file contains the generator for types whose underlying type is string.
+
+package stringer
+
+import (
+	"strings"
+)
+
+// generateString produces the methods for a type whose underlying type is
+// string. The String method returns the value itself, so only the -parse,
+// -values, -strings and -isvalid flags have anything to add.
+func (g *generator) generateString(typeName string, values []Value) {
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{{"text", g.text}, {"json", g.json}, {"sql", g.sql}, {"gob", g.gob}, {"binary", g.binary}, {"bounds", g.bounds}, {"description", g.description}, {"localize", g.localize}, {"ordinal", g.ordinal}, {"valueof", g.valueOf}, {"iter", g.iter}, {"gentest", g.test != nil}, {"yaml", g.yaml}, {"proto", g.proto}, {"char", g.char != ""}, {"plugin", len(g.plugins) > 0}, {"flags", g.flags}, {"slog", g.slog}, {"format", g.formatter}, {"fold", g.fold}, {"error", g.errorMethod}, {"group", g.group}} {
+		if f.set {
+			failf("-%s does not apply to %s, whose underlying type is string", f.name, typeName)
+		}
+	}
+	if g.outputPkg != "" {
+		failf("-outputpkg does not apply to %s, whose underlying type is string", typeName)
+	}
+	if hasAliases(values) {
+		failf("%s does not apply to %s, whose underlying type is string", AliasMarker, typeName)
+	}
+	values = uniqueStrings(values)
+	g.Printf(stringValue, typeName)
+	// Parse uses IsValid to check its argument.
+	if g.isValid || g.parse {
+		g.Printf("\n// IsValid reports whether i is the value of one of the %s constants.\n", typeName)
+		g.Printf("func (i %s) IsValid() bool {\n", typeName)
+		g.Printf("\tswitch i {\n")
+		g.Printf("\tcase %s:\n", strValues(values))
+		g.Printf("\t\treturn true\n")
+		g.Printf("\t}\n")
+		g.Printf("\treturn false\n")
+		g.Printf("}\n")
+	}
+	if g.parse {
+		g.Printf(parseStringFunc, typeName, funcName("Parse", typeName), g.invalidError(typeName, "s"))
+	}
+	if g.goString {
+		g.buildGoString(values, typeName)
+	}
+	if g.values {
+		g.buildValues(values, typeName)
+	}
+	if g.schema != nil {
+		g.addSchema(typeName, values)
+	}
+	if g.strings {
+		g.Printf("\nvar _%s_strings = []string{%s}\n", typeName, strValues(values))
+		g.Printf(stringsFunc, typeName)
+	}
+}
+
+// uniqueStrings returns a copy of the string values in the same order,
+// omitting any value equal to one that precedes it.
+func uniqueStrings(values []Value) []Value {
+	seen := make(map[string]bool)
+	var u []Value
+	for _, v := range values {
+		if !seen[v.str] {
+			seen[v.str] = true
+			u = append(u, v)
+		}
+	}
+	return u
+}
+
+// strValues returns the quoted values as a comma-separated list.
+func strValues(values []Value) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.str
+	}
+	return strings.Join(s, ", ")
+}
+
+// Argument to format is the type name.
+const stringValue = `
+func (i %[1]s) String() string {
+	return string(i)
+}
+`
+
+// Arguments to format are:
+//	[1]: type name
+//	[2]: name of the function
+//	[3]: error for an invalid s
+const parseStringFunc = `
+// %[2]s returns s as a %[1]s if it is the value of one of the constants.
+func %[2]s(s string) (%[1]s, error) {
+	if i := %[1]s(s); i.IsValid() {
+		return i, nil
+	}
+	return "", %[3]s
+}
+`
@@ -0,0 +1,91 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.5
+
+// This file contains the support for the -template flag.
+
+package stringer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"text/template"
+)
+
+// templateData is the data with which the -template template is executed
+// for each type.
+type templateData struct {
+	TypeName string            // The name of the type.
+	Package  string            // The name of the package.
+	Values   []templateValue   // The constants in declaration order, without duplicate values.
+	Runs     [][]templateValue // The constants sorted, in runs of consecutive values.
+}
+
+// templateValue describes one constant to the template.
+type templateValue struct {
+	Name         string // The printed name, after -trimprefix and the like.
+	OriginalName string // The name of the constant.
+	Value        string // The value as a Go literal.
+}
+
+// ParseTemplate parses the named file as a template for the Template option.
+// The template may call import with a package path to import the package in
+// the generated file, and quote to make a string literal.
+func ParseTemplate(file string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"import": func(path string) string { return "" },
+		"quote":  strconv.Quote,
+	}
+	tmpl, err := template.New(filepath.Base(file)).Funcs(funcs).ParseFiles(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %s", err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate generates the code for the type, with the given values,
+// using the template in place of the built-in generator.
+func (g *generator) executeTemplate(typeName string, values []Value) {
+	data := templateData{
+		TypeName: typeName,
+		Package:  g.pkg.name,
+	}
+	if values[0].isString {
+		data.Values = templateValues(uniqueStrings(values))
+	} else {
+		// splitIntoRuns sorts the values in place, so keep the declaration order.
+		data.Values = templateValues(unique(values))
+		for _, run := range splitIntoRuns(values) {
+			data.Runs = append(data.Runs, templateValues(run))
+		}
+	}
+	// Bind import to this run of the generator.
+	tmpl, err := g.template.Clone()
+	if err != nil {
+		failf("executing template for %s: %s", typeName, err)
+	}
+	tmpl.Funcs(template.FuncMap{
+		"import": func(path string) string {
+			g.addImport(path)
+			return ""
+		},
+	})
+	if err := tmpl.Execute(&g.buf, data); err != nil {
+		failf("executing template for %s: %s", typeName, err)
+	}
+}
+
+func templateValues(values []Value) []templateValue {
+	t := make([]templateValue, len(values))
+	for i, v := range values {
+		t[i] = templateValue{
+			Name:         v.name,
+			OriginalName: v.originalName,
+			Value:        v.str,
+		}
+	}
+	return t
+}
@@ -0,0 +1,71 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.5
+
+// This file contains the name transformations selected by the -transform flag.
+
+package stringer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// transforms maps the names accepted by the -transform flag to the functions
+// that rewrite a constant's name for printing.
+var transforms = map[string]func(string) string{
+	"snake": func(name string) string { return strings.ToLower(strings.Join(words(name), "_")) },
+	"kebab": func(name string) string { return strings.ToLower(strings.Join(words(name), "-")) },
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"title": func(name string) string {
+		w := words(name)
+		for i, word := range w {
+			r, size := utf8.DecodeRuneInString(word)
+			w[i] = string(unicode.ToUpper(r)) + word[size:]
+		}
+		return strings.Join(w, " ")
+	},
+}
+
+// TransformStyle returns the function for the Transform option that rewrites
+// the names in the given style: snake, kebab, lower, upper or title. It
+// returns nil for other styles.
+func TransformStyle(style string) func(string) string {
+	return transforms[style]
+}
+
+// words splits an identifier into its words. Words are separated by
+// underscores or by changes of case, so that "MaxRetries" and "max_retries"
+// both hold the words "Max", "Retries" (with case preserved). A run of upper
+// case letters is a single word, an acronym, except that its last letter
+// begins a new word if followed by a lower case letter: "HTTPServer" holds
+// "HTTP" and "Server". Digits belong to the word they follow.
+func words(name string) []string {
+	var w []string
+	runes := []rune(name)
+	start := 0
+	for i, r := range runes {
+		switch {
+		case r == '_':
+			if start < i {
+				w = append(w, string(runes[start:i]))
+			}
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				w = append(w, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		w = append(w, string(runes[start:]))
+	}
+	return w
+}
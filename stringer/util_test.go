@@ -0,0 +1,230 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for some of the internal functions.
+
+package stringer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Helpers to save typing in the test cases.
+type u []uint64
+type uu [][]uint64
+
+type SplitTest struct {
+	input  u
+	output uu
+	signed bool
+}
+
+var (
+	m2  = uint64(2)
+	m1  = uint64(1)
+	m0  = uint64(0)
+	m_1 = ^uint64(0)     // -1 when signed.
+	m_2 = ^uint64(0) - 1 // -2 when signed.
+)
+
+var splitTests = []SplitTest{
+	// No need for a test for the empty case; that's picked off before splitIntoRuns.
+	// Single value.
+	{u{1}, uu{u{1}}, false},
+	// Out of order.
+	{u{3, 2, 1}, uu{u{1, 2, 3}}, true},
+	// Out of order.
+	{u{3, 2, 1}, uu{u{1, 2, 3}}, false},
+	// A gap at the beginning.
+	{u{1, 33, 32, 31}, uu{u{1}, u{31, 32, 33}}, true},
+	// A gap in the middle, in mixed order.
+	{u{33, 7, 32, 31, 9, 8}, uu{u{7, 8, 9}, u{31, 32, 33}}, true},
+	// Gaps throughout
+	{u{33, 44, 1, 32, 45, 31}, uu{u{1}, u{31, 32, 33}, u{44, 45}}, true},
+	// Unsigned values spanning 0.
+	{u{m1, m0, m_1, m2, m_2}, uu{u{m0, m1, m2}, u{m_2, m_1}}, false},
+	// Signed values spanning 0
+	{u{m1, m0, m_1, m2, m_2}, uu{u{m_2, m_1, m0, m1, m2}}, true},
+}
+
+func TestSplitIntoRuns(t *testing.T) {
+Outer:
+	for n, test := range splitTests {
+		values := make([]Value, len(test.input))
+		for i, v := range test.input {
+			values[i] = Value{value: v, signed: test.signed, bits: 64, str: fmt.Sprint(v)}
+		}
+		runs := splitIntoRuns(values)
+		if len(runs) != len(test.output) {
+			t.Errorf("#%d: %v: got %d runs; expected %d", n, test.input, len(runs), len(test.output))
+			continue
+		}
+		for i, run := range runs {
+			if len(run) != len(test.output[i]) {
+				t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
+				continue Outer
+			}
+			for j, v := range run {
+				if v.value != test.output[i][j] {
+					t.Errorf("#%d: got %v; expected %v", n, runs, test.output)
+					continue Outer
+				}
+			}
+		}
+	}
+}
+
+var transformTests = []struct {
+	name, style, output string
+}{
+	{"MaxRetries", "snake", "max_retries"},
+	{"MaxRetries", "kebab", "max-retries"},
+	{"MaxRetries", "lower", "maxretries"},
+	{"MaxRetries", "upper", "MAXRETRIES"},
+	{"MaxRetries", "title", "Max Retries"},
+	// Acronyms.
+	{"HTTPServer", "snake", "http_server"},
+	{"ServeHTTP", "snake", "serve_http"},
+	{"HTTPServer", "title", "HTTP Server"},
+	// Digits stay with the preceding word.
+	{"Base64Encoding", "kebab", "base64-encoding"},
+	// Underscores separate words, but never make empty ones.
+	{"max_retries", "title", "Max Retries"},
+	{"_Max__Retries_", "snake", "max_retries"},
+	{"m_2", "kebab", "m-2"},
+	// Single words.
+	{"x", "title", "X"},
+	{"Однажды", "snake", "однажды"},
+}
+
+func TestTransform(t *testing.T) {
+	for _, test := range transformTests {
+		got := transforms[test.style](test.name)
+		if got != test.output {
+			t.Errorf("%s(%q) = %q; expected %q", test.style, test.name, got, test.output)
+		}
+	}
+}
+
+var errorTests = []struct {
+	name  string
+	input string
+	kind  ErrorKind
+	pos   string // The position, as printed.
+}{
+	{"no type", "package test\n", TypeNotFound, "-"},
+	{"no values", "package test\n\ntype Day int\n", NoValues, "errors.go:3:6"},
+	{"parse", "package test\n\ntype Day int(\n", FileError, "errors.go:3:13"},
+	{"check", "package test\n\ntype Day int\n\nconst Monday Day = Sunday\n", FileError, "errors.go:5:20"},
+	{"options", "package test\n\ntype Day float64\n\nconst Monday Day = 1\n", OtherError, "-"},
+}
+
+func TestErrors(t *testing.T) {
+	for _, test := range errorTests {
+		err := func() (err error) {
+			defer catch(&err)
+			var g generator
+			g.parsePackage(".", []string{"errors.go"}, test.input)
+			return Generate(ioutil.Discard, g.pkg, []string{"Day"}, &Options{Lookup: "bits"})
+		}()
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: got %v; expected an *Error", test.name, err)
+			continue
+		}
+		if e.Kind != test.kind || e.Pos.String() != test.pos {
+			t.Errorf("%s: got %s at %s; expected %s at %s", test.name, e.Kind, e.Pos, test.kind, test.pos)
+		}
+	}
+}
+
+var nameTests = []struct {
+	name  string
+	input string
+	fold  bool
+	msg   string // Part of the error, or empty if there is none.
+}{
+	{"alias", "const (\n\tMonday Day = iota //stringer:alias Tuesday\n\tTuesday\n)\n", false, `Monday and Tuesday of type Day both have the name "Tuesday"`},
+	{"same", "const (\n\tMonday Day = iota //stringer:alias Mon\n\tMon2 = Monday //stringer:alias Mon\n)\n", false, ""},
+	{"fold", "const (\n\tMonday Day = iota\n\tMONDAY\n)\n", true, `names "Monday" and "MONDAY", differing only in case`},
+	{"nofold", "const (\n\tMonday Day = iota\n\tMONDAY\n)\n", false, ""},
+	{"several", "const (\n\tMonday, Tuesday Day = 0, 1 //stringer:alias Mon\n)\n", false, "errors.go:6:29: //stringer:alias is on a declaration of several constants"},
+}
+
+// Names that would parse as more than one value are rejected.
+func TestNames(t *testing.T) {
+	for _, test := range nameTests {
+		err := func() (err error) {
+			defer catch(&err)
+			var g generator
+			g.parsePackage(".", []string{"errors.go"}, "package test\n\ntype Day int\n\n"+test.input)
+			return Generate(ioutil.Discard, g.pkg, []string{"Day"}, &Options{Parse: true, Fold: test.fold})
+		}()
+		switch {
+		case err == nil && test.msg != "":
+			t.Errorf("%s: no error; expected %q", test.name, test.msg)
+		case err != nil && (test.msg == "" || !strings.Contains(posMsg(err), test.msg)):
+			t.Errorf("%s: got %q; expected %q", test.name, posMsg(err), test.msg)
+		}
+	}
+}
+
+// posMsg returns the message of the error, after its position if it has one.
+func posMsg(err error) string {
+	if e, ok := err.(*Error); ok && e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Msg
+	}
+	return err.Error()
+}
+
+// Reload parses again only the files that changed, and returns the package
+// itself if none did.
+func TestReload(t *testing.T) {
+	day := "package day\n\ntype Day int\n\nconst (\n\tMonday Day = iota\n\tTuesday\n"
+	dir := writeFiles(t, map[string]string{
+		"day.go":   day + ")\n",
+		"other.go": "package day\n\nvar x = 1\n",
+	})
+	defer os.RemoveAll(dir)
+	pkg, err := Load(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same, err := pkg.Reload(nil); err != nil || same != pkg {
+		t.Fatalf("Reload of an unchanged package returned %p, %v; expected %p", same, err, pkg)
+	}
+	name := filepath.Join(dir, "day.go")
+	if err := ioutil.WriteFile(name, []byte(day+"\tWednesday\n)\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// The file system may not record a change within the same tick.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+	newPkg, err := pkg.Reload(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newPkg == pkg {
+		t.Fatal("Reload of a changed package returned it")
+	}
+	other := filepath.Join(dir, "other.go")
+	if newPkg.parsed[other].file != pkg.parsed[other].file {
+		t.Error("unchanged file was parsed again")
+	}
+	values, err := newPkg.Values("Day", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 {
+		t.Errorf("got %d values of Day; expected 3", len(values))
+	}
+}